@@ -0,0 +1,276 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auth gets Atlas credentials with OAuth, like the keyless credentials of GitHub Actions workflows.
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mongodb/atlas-cli-core/config"
+)
+
+var (
+	ErrTokenRequest         = errors.New("token request failed")
+	ErrOAuthNotSupported    = errors.New("the service doesn't support OAuth")
+	ErrInvalidTokenResponse = errors.New("invalid token response")
+)
+
+// TokenError is an error returned by the token endpoint, like invalid_grant.
+type TokenError struct {
+	StatusCode  int
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *TokenError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%v: %s: %s", ErrTokenRequest, e.Code, e.Description)
+	}
+	return fmt.Sprintf("%v: %s (HTTP %d)", ErrTokenRequest, e.Code, e.StatusCode)
+}
+
+func (*TokenError) Unwrap() error {
+	return ErrTokenRequest
+}
+
+// Token is the response of the token endpoint.
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// Expiry returns when the access token expires, the zero time if the endpoint didn't tell.
+func (t *Token) Expiry(now time.Time) time.Time {
+	if t.ExpiresIn <= 0 {
+		return time.Time{}
+	}
+	return now.Add(time.Duration(t.ExpiresIn) * time.Second)
+}
+
+type options struct {
+	profile   *config.Profile // profile is the profile of WithProfile, nil for the default one
+	client    *http.Client
+	clock     Clock
+	service   config.ServiceType
+	authURL   string
+	tokenURL  string
+	deviceURL string
+	clientID  string
+	lifetime  time.Duration
+	scopes    []string
+
+	fullAccess       bool // fullAccess delegates the access token as is, see WithFullAccess
+	authorizationURL string
+	browser          func(u string) error
+}
+
+// Option customizes how credentials are requested.
+type Option func(*options)
+
+// WithHTTPClient sets the client used for the requests, http.DefaultClient by default.
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *options) {
+		o.client = c
+	}
+}
+
+// WithService sets the service the credentials are for, config.CloudService by default.
+func WithService(s config.ServiceType) Option {
+	return func(o *options) {
+		o.service = s
+	}
+}
+
+// WithAuthURL sets the base URL of the OAuth endpoints, the default base URL of the service by default.
+func WithAuthURL(u string) Option {
+	return func(o *options) {
+		o.authURL = u
+	}
+}
+
+// WithProfile uses the service, OAuth endpoints and client ID configured in the profile,
+// see config.AuthURL, config.TokenURL and config.ClientID. Options after it take precedence.
+func WithProfile(p *config.Profile) Option {
+	return func(o *options) {
+		o.profile = p
+		o.service = p.CurrentService()
+		o.authURL = p.AuthURL()
+		o.tokenURL = p.TokenURL()
+		if id := p.ClientID(); id != "" {
+			o.clientID = id
+		}
+	}
+}
+
+// WithTokenURL overrides the token endpoint of the service.
+func WithTokenURL(u string) Option {
+	return func(o *options) {
+		o.tokenURL = u
+	}
+}
+
+// WithClientID sets the OAuth client ID sent to the token endpoint.
+func WithClientID(id string) Option {
+	return func(o *options) {
+		o.clientID = id
+	}
+}
+
+func applyOptions(opts []Option) *options {
+	o := &options{
+		client:  http.DefaultClient,
+		clock:   systemClock{},
+		browser: openBrowser,
+		service: config.CloudService,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// newOptions applies the options and sets the token endpoint of the service unless WithTokenURL is used.
+func newOptions(opts []Option) (*options, error) {
+	o := applyOptions(opts)
+	if err := o.setTokenURL(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (o *options) setTokenURL() error {
+	if o.tokenURL != "" {
+		return nil
+	}
+	u, err := o.endpoint(config.TokenPath)
+	o.tokenURL = u
+	return err
+}
+
+// checkOnline returns an OfflineError in offline mode, per the profile of WithProfile or the default one.
+func (o *options) checkOnline(operation string) error {
+	if o.profile == nil {
+		return config.CheckOnline(operation)
+	}
+	return o.profile.CheckOnline(operation)
+}
+
+// endpoint returns the URL of an OAuth endpoint of the service, relative to WithAuthURL if set.
+func (o *options) endpoint(path string) (string, error) {
+	if o.authURL != "" {
+		return strings.TrimSuffix(o.authURL, "/") + "/" + path, nil
+	}
+	c := o.service.Capabilities()
+	if !c.OAuth || c.DefaultBaseURL == "" {
+		return "", fmt.Errorf("%w: %q", ErrOAuthNotSupported, o.service)
+	}
+	return c.DefaultBaseURL + path, nil
+}
+
+// requestToken posts a form to the token endpoint.
+func requestToken(ctx context.Context, o *options, form url.Values) (*Token, error) {
+	body, err := postForm(ctx, o, o.tokenURL, form)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Token{}
+	if err := json.Unmarshal(body, t); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTokenResponse, err)
+	}
+	if t.AccessToken == "" {
+		return nil, fmt.Errorf("%w: no access token", ErrInvalidTokenResponse)
+	}
+	return t, nil
+}
+
+// postForm posts a form to an OAuth endpoint and returns the response body, or a TokenError.
+func postForm(ctx context.Context, o *options, u string, form url.Values) ([]byte, error) {
+	if o.clientID != "" {
+		form.Set("client_id", o.clientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenRequest, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenRequest, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newTokenError(resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// newTokenError parses an OAuth error response, or an Atlas API error like {"errorCode": "...", "detail": "..."}.
+func newTokenError(statusCode int, body []byte) *TokenError {
+	var v struct {
+		TokenError
+		ErrorCode string `json:"errorCode"`
+		Detail    string `json:"detail"`
+	}
+	_ = json.Unmarshal(body, &v)
+	tokenErr := &v.TokenError
+	tokenErr.StatusCode = statusCode
+	if tokenErr.Code == "" {
+		tokenErr.Code, tokenErr.Description = v.ErrorCode, v.Detail
+	}
+	if tokenErr.Code == "" {
+		tokenErr.Code = http.StatusText(statusCode)
+	}
+	return tokenErr
+}
+
+// StoreToken sets the access and refresh tokens of the profile to the token's and saves it.
+func StoreToken(p *config.Profile, t *Token) error {
+	p.SetAccessToken(t.AccessToken)
+	p.SetRefreshToken(t.RefreshToken)
+	return p.Save()
+}
+
+// newProfile returns an ephemeral profile using the token.
+func newProfile(o *options, t *Token) *config.Profile {
+	p := config.NewEphemeralProfile(nil)
+	p.SetService(string(o.service))
+	p.SetAccessToken(t.AccessToken)
+	if t.RefreshToken != "" {
+		p.SetRefreshToken(t.RefreshToken)
+	}
+	if o.clientID != "" {
+		p.Set(config.ClientIDField, o.clientID)
+	}
+	return p
+}
@@ -0,0 +1,46 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package auth
+
+import (
+	"testing"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithProfile(t *testing.T) {
+	p := config.NewEphemeralProfile(map[string]any{
+		"service":   config.CloudGovService,
+		"auth_url":  "https://login.example.com",
+		"client_id": "white-label",
+	})
+	o, err := newOptions([]Option{WithProfile(p), WithScopes("openid")})
+	require.NoError(t, err)
+	assert.Equal(t, config.ServiceType(config.CloudGovService), o.service)
+	assert.Equal(t, "white-label", o.clientID)
+	assert.Equal(t, "https://login.example.com/"+config.TokenPath, o.tokenURL)
+
+	f, err := NewDeviceFlow(WithProfile(p))
+	require.NoError(t, err)
+	assert.Equal(t, "https://login.example.com/"+deviceAuthorizationPath, f.o.deviceURL)
+
+	o, err = newOptions([]Option{WithProfile(p), WithClientID("other")})
+	require.NoError(t, err)
+	assert.Equal(t, "other", o.clientID, "later options take precedence")
+}
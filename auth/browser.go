@@ -0,0 +1,214 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/mongodb/atlas-cli-core/config"
+)
+
+const (
+	authorizationPath     = "api/oauth/authorize"
+	authorizationCodeType = "authorization_code"
+	callbackPath          = "/callback"
+)
+
+var ErrAuthorization = errors.New("authorization failed")
+
+// WithAuthorizationURL overrides the authorization endpoint of the service.
+func WithAuthorizationURL(u string) Option {
+	return func(o *options) {
+		o.authorizationURL = u
+	}
+}
+
+// WithBrowser sets how the authorization URL is opened, the default browser of the system by default.
+// Use it to print the URL when there's no browser.
+func WithBrowser(open func(u string) error) Option {
+	return func(o *options) {
+		o.browser = open
+	}
+}
+
+// openBrowser opens a URL with the default browser of the system.
+func openBrowser(u string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", u)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", u)
+	default:
+		cmd = exec.Command("xdg-open", u)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait() //nolint:errcheck // the browser outlives the command on some systems
+	return nil
+}
+
+// BrowserFlow logs users in with the OAuth authorization code grant with PKCE (RFC 7636):
+// it opens the authorization URL in a browser and receives the code on a callback server listening on localhost.
+type BrowserFlow struct {
+	o *options
+}
+
+// NewBrowserFlow returns the browser flow for the service, see WithProfile, WithService, WithClientID and WithScopes.
+func NewBrowserFlow(opts ...Option) (*BrowserFlow, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if o.authorizationURL == "" {
+		if o.authorizationURL, err = o.endpoint(authorizationPath); err != nil {
+			return nil, err
+		}
+	}
+	return &BrowserFlow{o: o}, nil
+}
+
+// Login opens the browser and waits until the user authorizes the login, or the context is done.
+// It returns ErrAccessDenied when the user denies it.
+func (f *BrowserFlow) Login(ctx context.Context) (*Token, error) {
+	if err := f.o.checkOnline("logging in"); err != nil {
+		return nil, err
+	}
+
+	verifier, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	redirectURI := "http://" + l.Addr().String() + callbackPath
+	codes := make(chan callbackResult, 1)
+	server := &http.Server{
+		Handler:           callbackHandler(state, codes),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go server.Serve(l) //nolint:errcheck // always ErrServerClosed after Shutdown
+
+	defer server.Shutdown(context.Background()) //nolint:errcheck // nothing to do if it fails
+
+	challenge := sha256.Sum256([]byte(verifier))
+	q := url.Values{
+		"response_type":         {"code"},
+		"redirect_uri":          {redirectURI},
+		"state":                 {state},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	if f.o.clientID != "" {
+		q.Set("client_id", f.o.clientID)
+	}
+	if len(f.o.scopes) > 0 {
+		q.Set("scope", strings.Join(f.o.scopes, " "))
+	}
+	if err := f.o.browser(f.o.authorizationURL + "?" + q.Encode()); err != nil {
+		return nil, fmt.Errorf("opening the browser: %w", err)
+	}
+
+	var result callbackResult
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result = <-codes:
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	return requestToken(ctx, f.o, url.Values{
+		"grant_type":    {authorizationCodeType},
+		"code":          {result.code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+	})
+}
+
+// Profile returns an ephemeral profile using the token returned by Login.
+func (f *BrowserFlow) Profile(t *Token) *config.Profile {
+	return newProfile(f.o, t)
+}
+
+type callbackResult struct {
+	code string
+	err  error
+}
+
+// callbackHandler receives the redirect of the authorization server, requests without the state are ignored
+// as they don't come from the login that was started.
+func callbackHandler(state string, results chan<- callbackResult) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != callbackPath || q.Get("state") != state {
+			http.NotFound(w, r)
+			return
+		}
+
+		var result callbackResult
+		switch code := q.Get("error"); {
+		case code == "access_denied":
+			result.err = ErrAccessDenied
+		case code != "":
+			result.err = fmt.Errorf("%w: %s: %s", ErrAuthorization, code, q.Get("error_description"))
+		case q.Get("code") == "":
+			result.err = fmt.Errorf("%w: no authorization code", ErrAuthorization)
+		default:
+			result.code = q.Get("code")
+		}
+
+		select {
+		case results <- result:
+		default: // already received
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if result.err != nil {
+			_, _ = fmt.Fprintf(w, "Login failed: %v\n", result.err)
+			return
+		}
+		_, _ = fmt.Fprintln(w, "Login succeeded, you can close this window.")
+	})
+}
+
+// randomString returns a random PKCE verifier or state, 43 URL safe characters.
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
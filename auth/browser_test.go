@@ -0,0 +1,116 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBrowser follows the authorization URL as if the user authorized, or denied, the login.
+func fakeBrowser(t *testing.T, challenge *string, params url.Values) func(string) error {
+	t.Helper()
+	return func(raw string) error {
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		q := u.Query()
+		assert.Equal(t, "/authorize", u.Path)
+		assert.Equal(t, "cli", q.Get("client_id"))
+		assert.Equal(t, "S256", q.Get("code_challenge_method"))
+		*challenge = q.Get("code_challenge")
+
+		params.Set("state", q.Get("state"))
+		go func() {
+			// a request without the state is ignored
+			resp, err := http.Get(q.Get("redirect_uri") + "?code=forged")
+			if err == nil {
+				resp.Body.Close()
+			}
+			resp, err = http.Get(q.Get("redirect_uri") + "?" + params.Encode())
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+}
+
+func TestBrowserFlow(t *testing.T) {
+	var challenge string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, authorizationCodeType, r.PostForm.Get("grant_type"))
+		assert.Equal(t, "code", r.PostForm.Get("code"))
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		assert.Equal(t, challenge, base64.RawURLEncoding.EncodeToString(sum[:]))
+		_ = json.NewEncoder(w).Encode(Token{AccessToken: "access-token", RefreshToken: "refresh-token"})
+	}))
+	defer s.Close()
+
+	f, err := NewBrowserFlow(
+		WithClientID("cli"),
+		WithAuthorizationURL(s.URL+"/authorize"),
+		WithTokenURL(s.URL),
+		WithBrowser(fakeBrowser(t, &challenge, url.Values{"code": {"code"}})),
+	)
+	require.NoError(t, err)
+	token, err := f.Login(context.Background())
+	require.NoError(t, err)
+
+	p := config.NewEphemeralProfile(nil)
+	require.NoError(t, StoreToken(p, token))
+	assert.Equal(t, "access-token", p.AccessToken())
+	assert.Equal(t, "refresh-token", p.RefreshToken())
+}
+
+func TestBrowserFlow_Denied(t *testing.T) {
+	var challenge string
+	f, err := NewBrowserFlow(
+		WithClientID("cli"),
+		WithAuthorizationURL("https://example.com/authorize"),
+		WithTokenURL("https://example.com/token"),
+		WithBrowser(fakeBrowser(t, &challenge, url.Values{"error": {"access_denied"}})),
+	)
+	require.NoError(t, err)
+	_, err = f.Login(context.Background())
+	require.ErrorIs(t, err, ErrAccessDenied)
+}
+
+func TestBrowserFlow_Offline(t *testing.T) {
+	f, err := NewBrowserFlow(
+		WithProfile(config.NewEphemeralProfile(map[string]any{"offline": true})),
+		WithAuthorizationURL("https://example.com/authorize"),
+		WithTokenURL("https://example.com/token"),
+		WithBrowser(func(string) error {
+			t.Error("the browser is not opened")
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+	_, err = f.Login(context.Background())
+	require.ErrorIs(t, err, config.ErrOffline, "the offline setting of the profile is respected")
+}
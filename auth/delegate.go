@@ -0,0 +1,178 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/mongodb/atlas-cli-core/config"
+)
+
+const accessTokenType = "urn:ietf:params:oauth:token-type:access_token"
+
+var (
+	ErrDelegationUnsupported = errors.New("only OAuth access tokens can be delegated, API keys can't be narrowed")
+	ErrDelegatedExpired      = errors.New("delegated credential expired")
+	ErrDelegationUnscoped    = errors.New("the delegated access token isn't narrowed, use WithScopes, or WithFullAccess to delegate the profile's token")
+)
+
+// DelegatedCredential is a credential for a child process, like a plugin: an access token without the refresh token,
+// valid until Expiry. It never includes API keys or refresh tokens.
+type DelegatedCredential struct {
+	AccessToken   string             `json:"access_token"`
+	Expiry        time.Time          `json:"expiry"`
+	Service       config.ServiceType `json:"service"`
+	OpsManagerURL string             `json:"ops_manager_url,omitempty"`
+	Scope         string             `json:"scope,omitempty"`
+}
+
+// WithLifetime limits how long the delegated credential can be used, it can't outlive the access token.
+// The token itself stays valid until it expires, the limit is enforced by ReadDelegated
+// and, through config.AccessTokenExpiryEnv, by the CLI reading DelegatedCredential.Environ.
+func WithLifetime(d time.Duration) Option {
+	return func(o *options) {
+		o.lifetime = d
+	}
+}
+
+// WithScopes exchanges the access token for one limited to the scopes, like a read-only scope,
+// where the token endpoint supports narrowing tokens (RFC 8693).
+func WithScopes(scopes ...string) Option {
+	return func(o *options) {
+		o.scopes = append(o.scopes, scopes...)
+	}
+}
+
+// WithFullAccess delegates the profile's access token as is, with all its permissions, when it isn't narrowed with WithScopes.
+func WithFullAccess() Option {
+	return func(o *options) {
+		o.fullAccess = true
+	}
+}
+
+// Delegate mints a credential of the profile to hand to a child process instead of the profile's
+// refresh token or private key, see DelegatedCredential.Environ and DelegatedCredential.WriteTo.
+// It returns ErrDelegationUnscoped unless the token is narrowed with WithScopes or WithFullAccess is given.
+func Delegate(ctx context.Context, p *config.Profile, opts ...Option) (*DelegatedCredential, error) {
+	if p.AuthType() != config.OAuth {
+		return nil, ErrDelegationUnsupported
+	}
+
+	c := &DelegatedCredential{
+		AccessToken:   p.AccessToken(),
+		Service:       p.CurrentService(),
+		OpsManagerURL: p.OpsManagerURL(),
+	}
+	o := applyOptions(append([]Option{WithProfile(p)}, opts...))
+	if len(o.scopes) == 0 && !o.fullAccess {
+		return nil, ErrDelegationUnscoped
+	}
+	if len(o.scopes) > 0 {
+		if err := p.CheckOnline("exchanging the access token"); err != nil {
+			return nil, err
+		}
+		if err := o.setTokenURL(); err != nil {
+			return nil, err
+		}
+		t, err := requestToken(ctx, o, url.Values{
+			"grant_type":           {tokenExchangeGrantType},
+			"subject_token":        {c.AccessToken},
+			"subject_token_type":   {accessTokenType},
+			"requested_token_type": {accessTokenType},
+			"scope":                {strings.Join(o.scopes, " ")},
+		})
+		if err != nil {
+			return nil, err
+		}
+		c.AccessToken = t.AccessToken
+		c.Scope = t.Scope
+	}
+
+	c.Expiry = tokenExpiry(c.AccessToken)
+	if o.lifetime > 0 {
+		if limit := time.Now().Add(o.lifetime); c.Expiry.IsZero() || limit.Before(c.Expiry) {
+			c.Expiry = limit
+		}
+	}
+	return c, nil
+}
+
+// tokenExpiry returns the expiry of a JWT access token, the zero time when unknown.
+func tokenExpiry(token string) time.Time {
+	c := jwt.RegisteredClaims{}
+	// the claims are only used to cap the lifetime, the API verifies the token
+	if _, _, err := new(jwt.Parser).ParseUnverified(token, &c); err != nil || c.ExpiresAt == nil {
+		return time.Time{}
+	}
+	return c.ExpiresAt.Time
+}
+
+// Environ returns the environment variables that make the CLI in a child process use the credential,
+// until its expiry, see config.AccessTokenExpiryEnv.
+func (c *DelegatedCredential) Environ() []string {
+	env := []string{
+		envName(config.AccessTokenField) + "=" + c.AccessToken,
+		envName("service") + "=" + string(c.Service),
+	}
+	if c.OpsManagerURL != "" {
+		env = append(env, envName(config.OpsManagerURLField)+"="+c.OpsManagerURL)
+	}
+	if !c.Expiry.IsZero() {
+		env = append(env, config.AccessTokenExpiryEnv+"="+c.Expiry.UTC().Format(time.RFC3339))
+	}
+	return env
+}
+
+func envName(key string) string {
+	return strings.ToUpper(config.AtlasCLIEnvPrefix + "_" + key)
+}
+
+// WriteTo writes the credential as JSON, for passing it through a pipe or an inherited file descriptor.
+func (c *DelegatedCredential) WriteTo(w io.Writer) (int64, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// ReadDelegated reads a credential written by DelegatedCredential.WriteTo into an ephemeral profile,
+// it returns ErrDelegatedExpired when the credential can't be used anymore.
+func ReadDelegated(r io.Reader) (*config.Profile, error) {
+	c := &DelegatedCredential{}
+	if err := json.NewDecoder(io.LimitReader(r, 1<<20)).Decode(c); err != nil {
+		return nil, fmt.Errorf("reading delegated credential: %w", err)
+	}
+	if !c.Expiry.IsZero() && !time.Now().Before(c.Expiry) {
+		return nil, fmt.Errorf("%w at %s", ErrDelegatedExpired, c.Expiry.Format(time.RFC3339))
+	}
+
+	p := config.NewEphemeralProfile(nil)
+	p.SetService(string(c.Service))
+	p.SetAccessToken(c.AccessToken)
+	if c.OpsManagerURL != "" {
+		p.SetOpsManagerURL(c.OpsManagerURL)
+	}
+	return p, nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testToken(t *testing.T, expiry time.Time) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   "user@example.com",
+		ExpiresAt: jwt.NewNumericDate(expiry),
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	return token
+}
+
+func TestDelegate(t *testing.T) {
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	p := config.NewEphemeralProfile(map[string]any{
+		config.AccessTokenField:  testToken(t, expiry),
+		config.RefreshTokenField: "refresh-token",
+		"service":                config.CloudService,
+	})
+
+	_, err := Delegate(context.Background(), p)
+	require.ErrorIs(t, err, ErrDelegationUnscoped, "the full token is only delegated when asked to")
+
+	c, err := Delegate(context.Background(), p, WithFullAccess())
+	require.NoError(t, err)
+	assert.Equal(t, p.AccessToken(), c.AccessToken)
+	assert.True(t, expiry.Equal(c.Expiry))
+	assert.Contains(t, c.Environ(), "MONGODB_ATLAS_ACCESS_TOKEN="+p.AccessToken())
+	assert.Contains(t, c.Environ(), config.AccessTokenExpiryEnv+"="+expiry.UTC().Format(time.RFC3339))
+	assert.False(t, slices.ContainsFunc(c.Environ(), func(s string) bool {
+		return strings.Contains(s, "refresh-token")
+	}), "the refresh token isn't delegated")
+
+	c, err = Delegate(context.Background(), p, WithFullAccess(), WithLifetime(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, c.Expiry.Before(expiry))
+
+	var pipe bytes.Buffer
+	_, err = c.WriteTo(&pipe)
+	require.NoError(t, err)
+	assert.NotContains(t, pipe.String(), "refresh-token")
+	child, err := ReadDelegated(&pipe)
+	require.NoError(t, err)
+	assert.Equal(t, p.AccessToken(), child.AccessToken())
+	assert.Empty(t, child.RefreshToken())
+
+	c.Expiry = time.Now().Add(-time.Second)
+	pipe.Reset()
+	_, err = c.WriteTo(&pipe)
+	require.NoError(t, err)
+	_, err = ReadDelegated(&pipe)
+	require.ErrorIs(t, err, ErrDelegatedExpired)
+}
+
+func TestDelegate_Scopes(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, tokenExchangeGrantType, r.PostForm.Get("grant_type"))
+		assert.Equal(t, "full-token", r.PostForm.Get("subject_token"))
+		assert.Equal(t, "read", r.PostForm.Get("scope"))
+		_ = json.NewEncoder(w).Encode(Token{AccessToken: "read-token", Scope: "read"})
+	}))
+	defer s.Close()
+
+	p := config.NewEphemeralProfile(map[string]any{config.AccessTokenField: "full-token"})
+	c, err := Delegate(context.Background(), p, WithScopes("read"), WithTokenURL(s.URL))
+	require.NoError(t, err)
+	assert.Equal(t, "read-token", c.AccessToken)
+	assert.Equal(t, "read", c.Scope)
+
+	p.SetProfileOffline(true)
+	_, err = Delegate(context.Background(), p, WithScopes("read"), WithTokenURL(s.URL))
+	require.ErrorIs(t, err, config.ErrOffline)
+}
+
+func TestDelegate_APIKeys(t *testing.T) {
+	p := config.NewEphemeralProfile(map[string]any{"public_api_key": "public", "private_api_key": "private"})
+	_, err := Delegate(context.Background(), p, WithFullAccess())
+	require.ErrorIs(t, err, ErrDelegationUnsupported)
+}
@@ -0,0 +1,182 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mongodb/atlas-cli-core/config"
+)
+
+const (
+	deviceAuthorizationPath = "api/oauth/device/authorize"
+	deviceCodeGrantType     = "urn:ietf:params:oauth:grant-type:device_code"
+	defaultPollInterval     = 5 * time.Second
+	slowDownIncrement       = 5 * time.Second
+)
+
+var (
+	ErrDeviceCodeExpired = errors.New("the device code expired before the user authorized it")
+	ErrAccessDenied      = errors.New("the user denied the authorization")
+)
+
+// Clock tells the time and waits, it's replaced in tests to poll without waiting.
+type Clock interface {
+	Now() time.Time
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// WithClock sets the clock used to poll, the system clock by default.
+func WithClock(c Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// WithDeviceAuthorizationURL overrides the device authorization endpoint of the service.
+func WithDeviceAuthorizationURL(u string) Option {
+	return func(o *options) {
+		o.deviceURL = u
+	}
+}
+
+// DeviceCode is the response of the device authorization endpoint, the user confirms UserCode at VerificationURI.
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"` // VerificationURIComplete includes the user code, when supported
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"` // Interval is the seconds to wait between polls
+
+	expiresAt time.Time
+}
+
+// DeviceFlow logs users in on devices without a browser with the OAuth device authorization grant (RFC 8628):
+// RequestCode returns a code the user confirms in a browser, and PollToken waits for the confirmation.
+type DeviceFlow struct {
+	o *options
+}
+
+// NewDeviceFlow returns the device flow for the service, see WithProfile, WithService, WithClientID and WithScopes.
+func NewDeviceFlow(opts ...Option) (*DeviceFlow, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if o.deviceURL == "" {
+		if o.deviceURL, err = o.endpoint(deviceAuthorizationPath); err != nil {
+			return nil, err
+		}
+	}
+	return &DeviceFlow{o: o}, nil
+}
+
+// RequestCode starts the flow, the code must be shown to the user before calling PollToken.
+func (f *DeviceFlow) RequestCode(ctx context.Context) (*DeviceCode, error) {
+	if err := f.o.checkOnline("requesting a device code"); err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	if len(f.o.scopes) > 0 {
+		form.Set("scope", strings.Join(f.o.scopes, " "))
+	}
+	body, err := postForm(ctx, f.o, f.o.deviceURL, form)
+	if err != nil {
+		return nil, err
+	}
+
+	code := &DeviceCode{}
+	if err := json.Unmarshal(body, code); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTokenResponse, err)
+	}
+	if code.DeviceCode == "" || code.UserCode == "" || code.VerificationURI == "" {
+		return nil, fmt.Errorf("%w: incomplete device code", ErrInvalidTokenResponse)
+	}
+	code.expiresAt = f.o.clock.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	return code, nil
+}
+
+// PollToken waits until the user authorizes the code and returns the token. It returns ErrDeviceCodeExpired
+// when the code expires first, ErrAccessDenied when the user denies it, and the context error when it's canceled.
+// The polling interval is increased when the server asks to slow down.
+func (f *DeviceFlow) PollToken(ctx context.Context, code *DeviceCode) (*Token, error) {
+	if err := f.o.checkOnline("polling the device token"); err != nil {
+		return nil, err
+	}
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	expiresAt := code.expiresAt
+	if expiresAt.IsZero() {
+		expiresAt = f.o.clock.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	}
+
+	for {
+		if err := f.o.clock.Sleep(ctx, interval); err != nil {
+			return nil, err
+		}
+		if !f.o.clock.Now().Before(expiresAt) {
+			return nil, ErrDeviceCodeExpired
+		}
+
+		t, err := requestToken(ctx, f.o, url.Values{
+			"grant_type":  {deviceCodeGrantType},
+			"device_code": {code.DeviceCode},
+		})
+		var tokenErr *TokenError
+		if !errors.As(err, &tokenErr) {
+			return t, err
+		}
+		switch tokenErr.Code {
+		case "authorization_pending", "DEVICE_AUTHORIZATION_PENDING":
+		case "slow_down":
+			interval += slowDownIncrement
+		case "expired_token", "DEVICE_AUTHORIZATION_EXPIRED":
+			return nil, fmt.Errorf("%w: %w", ErrDeviceCodeExpired, err)
+		case "access_denied":
+			return nil, fmt.Errorf("%w: %w", ErrAccessDenied, err)
+		default:
+			return nil, err
+		}
+	}
+}
+
+// Profile returns an ephemeral profile using the token returned by PollToken.
+func (f *DeviceFlow) Profile(t *Token) *config.Profile {
+	return newProfile(f.o, t)
+}
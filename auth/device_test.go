@@ -0,0 +1,153 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock moves forward when sleeping instead of waiting.
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(_ context.Context, d time.Duration) error {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return nil
+}
+
+// deviceServer answers the token requests with the responses in order, and then with a token.
+func deviceServer(t *testing.T, responses ...string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "cli", r.PostForm.Get("client_id"))
+		assert.Equal(t, "openid offline_access", r.PostForm.Get("scope"))
+		_ = json.NewEncoder(w).Encode(DeviceCode{
+			DeviceCode:      "device",
+			UserCode:        "ABCD-EFGH",
+			VerificationURI: "https://example.com/activate",
+			ExpiresIn:       60,
+			Interval:        5,
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, deviceCodeGrantType, r.PostForm.Get("grant_type"))
+		assert.Equal(t, "device", r.PostForm.Get("device_code"))
+		if len(responses) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(responses[0]))
+			responses = responses[1:]
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Token{AccessToken: "access-token", RefreshToken: "refresh-token"})
+	})
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestDeviceFlow(t *testing.T, s *httptest.Server, clock *fakeClock) *DeviceFlow {
+	t.Helper()
+	f, err := NewDeviceFlow(
+		WithClientID("cli"),
+		WithScopes("openid", "offline_access"),
+		WithDeviceAuthorizationURL(s.URL+"/authorize"),
+		WithTokenURL(s.URL+"/token"),
+		WithClock(clock),
+	)
+	require.NoError(t, err)
+	return f
+}
+
+func TestDeviceFlow(t *testing.T) {
+	s := deviceServer(t,
+		`{"error": "authorization_pending"}`,
+		`{"error": "slow_down"}`,
+		`{"errorCode": "DEVICE_AUTHORIZATION_PENDING", "detail": "waiting"}`,
+	)
+	clock := &fakeClock{now: time.Now()}
+	f := newTestDeviceFlow(t, s, clock)
+
+	code, err := f.RequestCode(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ABCD-EFGH", code.UserCode)
+
+	token, err := f.PollToken(context.Background(), code)
+	require.NoError(t, err)
+	assert.Equal(t, "access-token", token.AccessToken)
+	assert.Equal(t, []time.Duration{5 * time.Second, 5 * time.Second, 10 * time.Second, 10 * time.Second}, clock.sleeps)
+
+	p := f.Profile(token)
+	assert.Equal(t, "refresh-token", p.RefreshToken())
+	assert.Equal(t, "cli", p.ClientID())
+}
+
+func TestDeviceFlow_Errors(t *testing.T) {
+	tests := map[string]struct {
+		responses []string
+		want      error
+	}{
+		"expired locally":  {responses: []string{`{"error": "authorization_pending"}`, `{"error": "slow_down"}`, `{"error": "slow_down"}`, `{"error": "slow_down"}`, `{"error": "slow_down"}`}, want: ErrDeviceCodeExpired},
+		"expired":          {responses: []string{`{"error": "expired_token"}`}, want: ErrDeviceCodeExpired},
+		"expired in Atlas": {responses: []string{`{"errorCode": "DEVICE_AUTHORIZATION_EXPIRED"}`}, want: ErrDeviceCodeExpired},
+		"denied":           {responses: []string{`{"error": "access_denied"}`}, want: ErrAccessDenied},
+		"other":            {responses: []string{`{"error": "invalid_client"}`}, want: ErrTokenRequest},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := newTestDeviceFlow(t, deviceServer(t, tt.responses...), &fakeClock{now: time.Now()})
+			code, err := f.RequestCode(context.Background())
+			require.NoError(t, err)
+			_, err = f.PollToken(context.Background(), code)
+			require.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestDeviceFlow_Offline(t *testing.T) {
+	s := deviceServer(t)
+	clock := &fakeClock{now: time.Now()}
+	f, err := NewDeviceFlow(
+		WithProfile(config.NewEphemeralProfile(map[string]any{"offline": true})),
+		WithDeviceAuthorizationURL(s.URL+"/authorize"),
+		WithTokenURL(s.URL+"/token"),
+		WithClock(clock),
+	)
+	require.NoError(t, err)
+
+	_, err = f.RequestCode(context.Background())
+	require.ErrorIs(t, err, config.ErrOffline, "the offline setting of the profile is respected")
+	_, err = f.PollToken(context.Background(), &DeviceCode{DeviceCode: "device", ExpiresIn: 60})
+	require.ErrorIs(t, err, config.ErrOffline)
+	assert.Empty(t, clock.sleeps)
+}
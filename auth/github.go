@@ -0,0 +1,110 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/mongodb/atlas-cli-core/config"
+)
+
+const (
+	GitHubOIDCRequestURLEnv   = "ACTIONS_ID_TOKEN_REQUEST_URL"   // GitHubOIDCRequestURLEnv is set in jobs with the id-token: write permission
+	GitHubOIDCRequestTokenEnv = "ACTIONS_ID_TOKEN_REQUEST_TOKEN" // GitHubOIDCRequestTokenEnv authenticates the request of the OIDC token
+	tokenExchangeGrantType    = "urn:ietf:params:oauth:grant-type:token-exchange"
+	jwtTokenType              = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+var ErrGitHubOIDCUnavailable = errors.New("GitHub Actions OIDC token unavailable, the job needs the id-token: write permission")
+
+// FromGitHubOIDC gets Atlas credentials for a GitHub Actions job without stored secrets:
+// it requests the OIDC token of the job for the audience and exchanges it at the token endpoint of the service
+// (RFC 8693). The returned profile is ephemeral, nothing is written to disk.
+func FromGitHubOIDC(ctx context.Context, audience string, opts ...Option) (*config.Profile, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.checkOnline("requesting the GitHub Actions OIDC token"); err != nil {
+		return nil, err
+	}
+
+	idToken, err := gitHubOIDCToken(ctx, o.client, audience)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := requestToken(ctx, o, url.Values{
+		"grant_type":         {tokenExchangeGrantType},
+		"subject_token":      {idToken},
+		"subject_token_type": {jwtTokenType},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newProfile(o, t), nil
+}
+
+// gitHubOIDCToken requests the OIDC token of the running GitHub Actions job.
+func gitHubOIDCToken(ctx context.Context, client *http.Client, audience string) (string, error) {
+	requestURL, token := os.Getenv(GitHubOIDCRequestURLEnv), os.Getenv(GitHubOIDCRequestTokenEnv)
+	if requestURL == "" || token == "" {
+		return "", ErrGitHubOIDCUnavailable
+	}
+
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrGitHubOIDCUnavailable, err)
+	}
+	if audience != "" {
+		q := u.Query()
+		q.Set("audience", audience)
+		u.RawQuery = q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("requesting the GitHub Actions OIDC token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: HTTP %d", ErrGitHubOIDCUnavailable, resp.StatusCode)
+	}
+
+	var body struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("decoding the GitHub Actions OIDC token: %w", err)
+	}
+	if body.Value == "" {
+		return "", fmt.Errorf("%w: empty token", ErrGitHubOIDCUnavailable)
+	}
+	return body.Value, nil
+}
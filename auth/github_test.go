@@ -0,0 +1,93 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromGitHubOIDC(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oidc", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer request-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "atlas", r.URL.Query().Get("audience"))
+		assert.Equal(t, "1", r.URL.Query().Get("api-version"))
+		_ = json.NewEncoder(w).Encode(map[string]string{"value": "id-token"})
+	})
+	mux.HandleFunc("/api/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, tokenExchangeGrantType, r.PostForm.Get("grant_type"))
+		assert.Equal(t, jwtTokenType, r.PostForm.Get("subject_token_type"))
+		if r.PostForm.Get("subject_token") != "id-token" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"untrusted repository"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Token{AccessToken: "access-token", TokenType: "Bearer", ExpiresIn: 3600})
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	t.Setenv(GitHubOIDCRequestURLEnv, s.URL+"/oidc?api-version=1")
+	t.Setenv(GitHubOIDCRequestTokenEnv, "request-token")
+
+	p, err := FromGitHubOIDC(context.Background(), "atlas", WithTokenURL(s.URL+"/api/oauth/token"))
+	require.NoError(t, err)
+	assert.True(t, p.IsEphemeral())
+	assert.Equal(t, "access-token", p.AccessToken())
+	assert.Equal(t, config.ServiceType(config.CloudService), p.CurrentService())
+	assert.Equal(t, config.OAuth, p.AuthType())
+}
+
+func TestFromGitHubOIDC_Errors(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oidc" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"value": "forged"})
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"untrusted repository"}`))
+	}))
+	defer s.Close()
+
+	t.Setenv(GitHubOIDCRequestURLEnv, "")
+	_, err := FromGitHubOIDC(context.Background(), "atlas")
+	require.ErrorIs(t, err, ErrGitHubOIDCUnavailable)
+
+	t.Setenv(GitHubOIDCRequestURLEnv, s.URL+"/oidc")
+	t.Setenv(GitHubOIDCRequestTokenEnv, "request-token")
+	_, err = FromGitHubOIDC(context.Background(), "atlas", WithTokenURL(s.URL+"/token"))
+	var tokenErr *TokenError
+	require.ErrorAs(t, err, &tokenErr)
+	require.ErrorIs(t, err, ErrTokenRequest)
+	assert.Equal(t, "invalid_grant", tokenErr.Code)
+
+	_, err = FromGitHubOIDC(context.Background(), "atlas", WithService(config.OpsManagerService))
+	require.ErrorIs(t, err, ErrOAuthNotSupported)
+
+	p := config.NewEphemeralProfile(map[string]any{"offline": true})
+	_, err = FromGitHubOIDC(context.Background(), "atlas", WithProfile(p), WithTokenURL(s.URL+"/token"))
+	require.ErrorIs(t, err, config.ErrOffline, "the offline setting of the profile is respected")
+}
@@ -0,0 +1,231 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clienttest runs a fake Atlas Admin API with canned responses, and a profile using it,
+// to test commands end-to-end without the real API.
+package clienttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mongodb/atlas-cli-core/config"
+)
+
+const (
+	AccessToken     = "clienttest-access-token" // AccessToken is the access token of the profile returned by Server.Profile
+	OrgID           = "5e2211c17a3e5a48f5497de3"
+	ProjectID       = "5e2211c17a3e5a48f5497de4"
+	DefaultPageSize = 100 // DefaultPageSize is the number of items per page when itemsPerPage isn't set, like Atlas
+)
+
+// Request is a request received by the Server.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+type route struct {
+	method    string
+	path      string
+	handler   http.Handler
+	remaining int // remaining is the number of requests the route still answers, -1 for no limit
+}
+
+// Server is an httptest.Server answering requests with the handlers registered for their method and path.
+// The handlers registered last take precedence. Requests without handler get a 404 Atlas error.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	routes   []*route
+	requests []Request
+}
+
+// NewServer starts a Server closed when the test ends.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Profile returns an ephemeral profile sending requests to the server, with an access token, OrgID and ProjectID.
+func (s *Server) Profile() *config.Profile {
+	return config.NewEphemeralProfile(map[string]any{
+		"service":                 config.CloudService,
+		config.OpsManagerURLField: s.URL + "/",
+		config.AccessTokenField:   AccessToken,
+		"org_id":                  OrgID,
+		"project_id":              ProjectID,
+	})
+}
+
+// Handle registers a handler for a method and path, an empty method matches any method.
+func (s *Server) Handle(method, path string, h http.Handler) {
+	s.handle(method, path, h, -1)
+}
+
+func (s *Server) handle(method, path string, h http.Handler, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes = append(s.routes, &route{method: method, path: path, handler: h, remaining: times})
+}
+
+// JSON answers the requests for a method and path with a status and body encoded as JSON.
+func (s *Server) JSON(method, path string, status int, body any) {
+	s.Handle(method, path, jsonHandler(status, body))
+}
+
+// Error answers the requests for a method and path with an Atlas error, like
+// Error(http.MethodGet, "/api/atlas/v2/groups/x", http.StatusNotFound, "GROUP_NOT_FOUND", "Group x not found").
+func (s *Server) Error(method, path string, status int, errorCode, detail string) {
+	s.Handle(method, path, errorHandler(status, errorCode, detail))
+}
+
+// RateLimit answers the next requests for a method and path with 429 Too Many Requests and a Retry-After header,
+// the handlers registered before answer the requests after them.
+func (s *Server) RateLimit(method, path string, times int, retryAfter time.Duration) {
+	h := errorHandler(http.StatusTooManyRequests, "RATE_LIMITED", "Too many requests, retry later")
+	s.handle(method, path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Round(time.Second).Seconds())))
+		h.ServeHTTP(w, r)
+	}), times)
+}
+
+// Paginated answers GET requests for a path with a page of the items, using the pageNum and itemsPerPage
+// query parameters and returning results, totalCount and links like the Atlas Admin API.
+func (s *Server) Paginated(path string, items []any) {
+	s.Handle(http.MethodGet, path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, size, err := pageParams(r.URL.Query())
+		if err != nil {
+			errorHandler(http.StatusBadRequest, "INVALID_QUERY_PARAMETER", err.Error()).ServeHTTP(w, r)
+			return
+		}
+		start := min((page-1)*size, len(items))
+		end := min(start+size, len(items))
+
+		link := func(rel string, page int) map[string]string {
+			return map[string]string{
+				"rel":  rel,
+				"href": fmt.Sprintf("%s%s?pageNum=%d&itemsPerPage=%d", s.URL, path, page, size),
+			}
+		}
+		links := []map[string]string{link("self", page)}
+		if page > 1 {
+			links = append(links, link("previous", page-1))
+		}
+		if end < len(items) {
+			links = append(links, link("next", page+1))
+		}
+		results := items[start:end]
+		if results == nil {
+			results = []any{}
+		}
+		jsonHandler(http.StatusOK, map[string]any{
+			"results":    results,
+			"totalCount": len(items),
+			"links":      links,
+		}).ServeHTTP(w, r)
+	}))
+}
+
+func pageParams(q url.Values) (int, int, error) {
+	page, size := 1, DefaultPageSize
+	var err error
+	if v := q.Get("pageNum"); v != "" {
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			return 0, 0, fmt.Errorf("invalid pageNum %q", v)
+		}
+	}
+	if v := q.Get("itemsPerPage"); v != "" {
+		if size, err = strconv.Atoi(v); err != nil || size < 1 {
+			return 0, 0, fmt.Errorf("invalid itemsPerPage %q", v)
+		}
+	}
+	return page, size, nil
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	h := s.match(r)
+	s.mu.Unlock()
+
+	if h == nil {
+		errorHandler(http.StatusNotFound, "RESOURCE_NOT_FOUND", fmt.Sprintf("Cannot find resource %s.", r.URL.Path)).ServeHTTP(w, r)
+		return
+	}
+	h.ServeHTTP(w, r)
+}
+
+// match returns the handler registered last for the request, consuming one of its requests if it's limited.
+func (s *Server) match(r *http.Request) http.Handler {
+	for i := len(s.routes) - 1; i >= 0; i-- {
+		rt := s.routes[i]
+		if rt.path != r.URL.Path || (rt.method != "" && rt.method != r.Method) || rt.remaining == 0 {
+			continue
+		}
+		if rt.remaining > 0 {
+			rt.remaining--
+		}
+		return rt.handler
+	}
+	return nil
+}
+
+func jsonHandler(status int, body any) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	})
+}
+
+// errorHandler answers with an error in the format of the Atlas Admin API.
+func errorHandler(status int, errorCode, detail string) http.Handler {
+	return jsonHandler(status, map[string]any{
+		"detail":     detail,
+		"error":      status,
+		"errorCode":  errorCode,
+		"parameters": []any{},
+		"reason":     http.StatusText(status),
+	})
+}
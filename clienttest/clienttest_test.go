@@ -0,0 +1,106 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package clienttest
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getJSON(t *testing.T, c *http.Client, u string, v any) *http.Response {
+	t.Helper()
+	resp, err := c.Get(u)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	return resp
+}
+
+func TestServer_Profile(t *testing.T) {
+	s := NewServer(t)
+	s.JSON(http.MethodGet, "/api/atlas/v2/groups/"+ProjectID, http.StatusOK, map[string]string{"id": ProjectID})
+	p := s.Profile()
+
+	var project map[string]string
+	getJSON(t, p.HttpClient(), p.HttpBaseURL()+"api/atlas/v2/groups/"+p.ProjectID(), &project)
+	assert.Equal(t, ProjectID, project["id"])
+
+	requests := s.Requests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "Bearer "+AccessToken, requests[0].Header.Get("Authorization"))
+}
+
+func TestServer_Paginated(t *testing.T) {
+	s := NewServer(t)
+	s.Paginated("/api/atlas/v2/groups", []any{"a", "b", "c"})
+
+	var page struct {
+		Results    []string `json:"results"`
+		TotalCount int      `json:"totalCount"`
+		Links      []struct {
+			Rel  string `json:"rel"`
+			Href string `json:"href"`
+		} `json:"links"`
+	}
+	getJSON(t, s.Client(), s.URL+"/api/atlas/v2/groups?itemsPerPage=2", &page)
+	assert.Equal(t, []string{"a", "b"}, page.Results)
+	assert.Equal(t, 3, page.TotalCount)
+	require.Len(t, page.Links, 2)
+	assert.Equal(t, "next", page.Links[1].Rel)
+
+	getJSON(t, s.Client(), page.Links[1].Href, &page)
+	assert.Equal(t, []string{"c"}, page.Results)
+	assert.Equal(t, "previous", page.Links[1].Rel)
+
+	resp := getJSON(t, s.Client(), s.URL+"/api/atlas/v2/groups?pageNum=0", &page)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestServer_Errors(t *testing.T) {
+	s := NewServer(t)
+	s.JSON(http.MethodGet, "/api/atlas/v2/groups", http.StatusOK, map[string]any{})
+	s.RateLimit(http.MethodGet, "/api/atlas/v2/groups", 1, 2*time.Second)
+	s.Error(http.MethodDelete, "/api/atlas/v2/groups", http.StatusForbidden, "USER_UNAUTHORIZED", "Not allowed")
+
+	var apiErr struct {
+		Error     int    `json:"error"`
+		ErrorCode string `json:"errorCode"`
+	}
+	resp := getJSON(t, s.Client(), s.URL+"/api/atlas/v2/groups", &apiErr)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("Retry-After"))
+	assert.Equal(t, "RATE_LIMITED", apiErr.ErrorCode)
+
+	resp = getJSON(t, s.Client(), s.URL+"/api/atlas/v2/groups", &map[string]any{})
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "the rate limit is over")
+
+	req, err := http.NewRequest(http.MethodDelete, s.URL+"/api/atlas/v2/groups", nil)
+	require.NoError(t, err)
+	resp, err = s.Client().Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = getJSON(t, s.Client(), s.URL+"/missing", &apiErr)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "RESOURCE_NOT_FOUND", apiErr.ErrorCode)
+}
@@ -0,0 +1,181 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const activityFilename = "activity.jsonl"
+
+// correlationIDHeaders are the response headers identifying a request on the server side, the first one set is recorded.
+var correlationIDHeaders = []string{"X-Correlation-Id", "X-Request-Id"}
+
+// ActivityEntry is an API request changing something, recorded when record_activity is enabled.
+// Bodies, queries and headers are never recorded so no secret ends up in the activity trail.
+type ActivityEntry struct {
+	Session       string    `json:"session"` // Session identifies the process that sent the request, see SessionID
+	Time          time.Time `json:"time"`
+	Profile       string    `json:"profile"`
+	Method        string    `json:"method"`
+	Host          string    `json:"host"`
+	Path          string    `json:"path"`
+	StatusCode    int       `json:"status,omitempty"` // StatusCode is 0 when no response was received
+	CorrelationID string    `json:"correlation_id,omitempty"`
+}
+
+// SessionID returns a random ID identifying the current process in the activity trail.
+var SessionID = sync.OnceValue(func() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+})
+
+// activityFile serializes the writes to the activity trail of the process.
+var activityFile sync.Mutex
+
+// RecordActivity get whether the API requests changing something, all but GET, HEAD and OPTIONS,
+// are recorded in the activity trail, see ReadActivity. Nothing is recorded in dry run mode.
+func RecordActivity() bool { return Default().RecordActivity() }
+func (p *Profile) RecordActivity() bool {
+	return p.GetBool(recordActivity)
+}
+
+// SetRecordActivity sets whether the API requests changing something are recorded.
+func SetRecordActivity(v bool) { Default().SetRecordActivity(v) }
+func (p *Profile) SetRecordActivity(v bool) {
+	p.Set(recordActivity, v)
+}
+
+// ActivityFilename returns the file keeping the activity trail, next to the config file.
+func ActivityFilename() string { return Default().ActivityFilename() }
+func (p *Profile) ActivityFilename() string {
+	return filepath.Join(p.configDir, activityFilename)
+}
+
+// ReadActivity returns the recorded API requests of a session, oldest first, or of every session if session is empty.
+// Use SessionID for the requests of the current process, to keep evidence of the changes made by a command.
+func ReadActivity(session string) ([]ActivityEntry, error) { return Default().ReadActivity(session) }
+func (p *Profile) ReadActivity(session string) ([]ActivityEntry, error) {
+	f, err := p.fs.Open(p.ActivityFilename())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []ActivityEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e ActivityEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// a line cut by a crash doesn't hide the rest of the trail
+			continue
+		}
+		if session == "" || e.Session == session {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+func (p *Profile) recordActivity(e ActivityEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	activityFile.Lock()
+	defer activityFile.Unlock()
+	if err := p.fs.MkdirAll(p.configDir, defaultPermissions); err != nil {
+		return err
+	}
+	f, err := p.fs.OpenFile(p.ActivityFilename(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, configPerm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func isMutation(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
+// activityTransport records the requests changing something when record_activity is enabled.
+func (p *Profile) activityTransport(base http.RoundTripper) http.RoundTripper {
+	if !p.RecordActivity() || p.IsEphemeral() || p.DryRun() {
+		return base
+	}
+	return &activityTransport{base: base, profile: p, log: p.Logger()}
+}
+
+type activityTransport struct {
+	base    http.RoundTripper
+	profile *Profile
+	log     *slog.Logger
+}
+
+func (t *activityTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isMutation(req.Method) {
+		return t.base.RoundTrip(req)
+	}
+	e := ActivityEntry{
+		Session: SessionID(),
+		Time:    time.Now().UTC(),
+		Profile: t.profile.Name(),
+		Method:  req.Method,
+		Host:    req.URL.Host,
+		Path:    req.URL.Path,
+	}
+	resp, err := t.base.RoundTrip(req)
+	if resp != nil {
+		e.StatusCode = resp.StatusCode
+		for _, h := range correlationIDHeaders {
+			if id := resp.Header.Get(h); id != "" {
+				e.CorrelationID = id
+				break
+			}
+		}
+	}
+	if recordErr := t.profile.recordActivity(e); recordErr != nil {
+		t.log.Warn("API request can't be recorded in the activity trail", "error", recordErr)
+	}
+	return resp, err
+}
+
+func (t *activityTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
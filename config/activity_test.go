@@ -0,0 +1,74 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_RecordActivity(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-"+r.Method)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	p := newTestProfile(t, "")
+	p.SetAccessToken("secret-token")
+	client := p.HttpClient()
+	resp, err := client.Post(s.URL+"/api/clusters?apiKey=secret", "application/json", strings.NewReader(`{"password": "secret"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	entries, err := p.ReadActivity("")
+	require.NoError(t, err)
+	assert.Empty(t, entries, "recording is off by default")
+
+	p.SetRecordActivity(true)
+	client = p.HttpClient()
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		req, err := http.NewRequest(method, s.URL+"/api/clusters/c1?apiKey=secret", nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	entries, err = p.ReadActivity(SessionID())
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "only mutations are recorded")
+	e := entries[0]
+	assert.Equal(t, DefaultProfile, e.Profile)
+	assert.Equal(t, http.MethodDelete, e.Method)
+	assert.Equal(t, "/api/clusters/c1", e.Path)
+	assert.Equal(t, http.StatusAccepted, e.StatusCode)
+	assert.Equal(t, "req-DELETE", e.CorrelationID)
+
+	entries, err = p.ReadActivity("another-session")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	b, err := afero.ReadFile(p.fs, p.ActivityFilename())
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "secret")
+}
@@ -0,0 +1,105 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// versionedAPIPath is the path of the Atlas Admin API endpoints selecting their version with the Accept header.
+const versionedAPIPath = "/api/atlas/v2"
+
+var ErrUnknownAPIVersion = errors.New("unknown API version")
+
+// KnownAPIVersions are the released versions of the Atlas Admin API accepted by SetAPIVersion.
+// Applications can append versions released after this package.
+var KnownAPIVersions = []string{
+	"2023-01-01",
+	"2023-02-01",
+	"2023-10-01",
+	"2023-11-15",
+	"2024-05-30",
+	"2024-08-05",
+	"2024-10-23",
+	"2024-11-13",
+}
+
+var (
+	apiVersionRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	versionedAccept = regexp.MustCompile(`^application/vnd\.atlas\.(\d{4}-\d{2}-\d{2}|preview)(\+\w+)?$`)
+)
+
+// APIVersion get the version of the Atlas Admin API pinned for the profile, like 2023-11-15.
+// When set, HttpClient requests it in the Accept header of every request to the versioned API,
+// so scripts get the same responses across API releases. Empty means each request picks its version.
+func APIVersion() string { return Default().APIVersion() }
+func (p *Profile) APIVersion() string {
+	return p.GetString(apiVersion)
+}
+
+// SetAPIVersion pins the version of the Atlas Admin API after checking it's in KnownAPIVersions, empty unpins it.
+func SetAPIVersion(v string) error { return Default().SetAPIVersion(v) }
+func (p *Profile) SetAPIVersion(v string) error {
+	if v != "" && !slices.Contains(KnownAPIVersions, v) {
+		return fmt.Errorf("%w: %q, known versions are %s", ErrUnknownAPIVersion, v, strings.Join(KnownAPIVersions, ", "))
+	}
+	p.Set(apiVersion, v)
+	return nil
+}
+
+type apiVersionTransport struct {
+	base    http.RoundTripper
+	version string
+}
+
+// apiVersionTransport wraps base to request the pinned API version, if any.
+// Versions set in the config file that are not dates are ignored.
+func (p *Profile) apiVersionTransport(base http.RoundTripper) http.RoundTripper {
+	version := p.APIVersion()
+	if version == "" || !apiVersionRegex.MatchString(version) || !p.CurrentService().Capabilities().VersionedAPI {
+		return base
+	}
+	return &apiVersionTransport{base: base, version: version}
+}
+
+// RoundTrip replaces the version of the Accept header, keeping its format like +json or +csv.
+// RoundTrippers must not modify the request, so the header is set on a copy.
+func (t *apiVersionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.HasPrefix(req.URL.Path, versionedAPIPath) {
+		return t.base.RoundTrip(req)
+	}
+	format := "+json"
+	if accept := req.Header.Get("Accept"); accept != "" {
+		m := versionedAccept.FindStringSubmatch(accept)
+		if m == nil || m[1] == "preview" {
+			return t.base.RoundTrip(req)
+		}
+		if m[2] != "" {
+			format = m[2]
+		}
+	}
+	r := req.Clone(req.Context())
+	r.Header.Set("Accept", "application/vnd.atlas."+t.version+format)
+	return t.base.RoundTrip(r)
+}
+
+func (t *apiVersionTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_SetAPIVersion(t *testing.T) {
+	p := NewEphemeralProfile(nil)
+	require.NoError(t, p.SetAPIVersion("2023-11-15"))
+	assert.Equal(t, "2023-11-15", p.APIVersion())
+	require.ErrorIs(t, p.SetAPIVersion("2023-11-16"), ErrUnknownAPIVersion)
+	require.NoError(t, p.SetAPIVersion(""))
+	assert.Empty(t, p.APIVersion())
+}
+
+func TestAPIVersionTransport(t *testing.T) {
+	var accept string
+	s := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+	}))
+	defer s.Close()
+
+	p := NewEphemeralProfile(map[string]any{"service": CloudService})
+	require.NoError(t, p.SetAPIVersion("2023-11-15"))
+	client := &http.Client{Transport: p.apiVersionTransport(http.DefaultTransport)}
+
+	tests := []struct {
+		path, accept, want string
+	}{
+		{"/api/atlas/v2/groups", "", "application/vnd.atlas.2023-11-15+json"},
+		{"/api/atlas/v2/groups", "application/vnd.atlas.2024-08-05+json", "application/vnd.atlas.2023-11-15+json"},
+		{"/api/atlas/v2/groups/x/logs", "application/vnd.atlas.2024-08-05+gzip", "application/vnd.atlas.2023-11-15+gzip"},
+		{"/api/atlas/v2/groups", "application/vnd.atlas.preview+json", "application/vnd.atlas.preview+json"},
+		{"/api/atlas/v1.0/groups", "application/json", "application/json"},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodGet, s.URL+tt.path, nil)
+		require.NoError(t, err)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, tt.want, accept, tt.path)
+		assert.Equal(t, tt.accept, req.Header.Get("Accept"), "the request isn't modified")
+	}
+
+	p.SetService(OpsManagerService)
+	assert.Equal(t, http.DefaultTransport, p.apiVersionTransport(http.DefaultTransport), "Ops Manager isn't versioned")
+}
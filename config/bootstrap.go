@@ -0,0 +1,184 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+const maxBootstrapSize = 1 << 20
+
+var (
+	ErrBootstrapInsecureURL    = errors.New("bootstrap templates can only be fetched over https")
+	ErrBootstrapUntrusted      = errors.New("bootstrap template signature is not valid for any trusted key")
+	ErrBootstrapSecretSetting  = errors.New("bootstrap templates can't contain credentials")
+	ErrBootstrapUnknownSetting = errors.New("unknown setting in bootstrap template")
+	ErrBootstrapTooLarge       = errors.New("bootstrap template is too large")
+)
+
+var bootstrapClient = http.DefaultClient
+
+func credentialProperties() []string {
+	return []string{
+		publicAPIKey,
+		privateAPIKey,
+		AccessTokenField,
+		RefreshTokenField,
+	}
+}
+
+// SetBootstrapKeys sets the ed25519 public keys trusted to sign bootstrap templates.
+func SetBootstrapKeys(keys ...ed25519.PublicKey) { Default().SetBootstrapKeys(keys...) }
+func (p *Profile) SetBootstrapKeys(keys ...ed25519.PublicKey) {
+	p.bootstrapKeys = keys
+}
+
+// BootstrapFromURL fetches a profile template from an https URL and installs it in the profile.
+// The template is a TOML document with profile settings, for example service, ops_manager_url, org_id or telemetry_enabled,
+// credentials are rejected.
+// The base64 encoded ed25519 signature of the document must be served at the same URL with a .sig suffix
+// on its path, and be valid for one of the keys set with SetBootstrapKeys.
+func BootstrapFromURL(ctx context.Context, u string) error { return Default().BootstrapFromURL(ctx, u) }
+func (p *Profile) BootstrapFromURL(ctx context.Context, u string) error {
+	if err := p.CheckOnline("fetching " + u); err != nil {
+		return err
+	}
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "https" {
+		return fmt.Errorf("%w: %s", ErrBootstrapInsecureURL, u)
+	}
+
+	body, err := fetchBootstrap(ctx, u)
+	if err != nil {
+		return err
+	}
+	encodedSignature, err := fetchBootstrap(ctx, signatureURL(parsed))
+	if err != nil {
+		return err
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encodedSignature)))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrBootstrapUntrusted, err)
+	}
+	if !slices.ContainsFunc(p.bootstrapKeys, func(key ed25519.PublicKey) bool {
+		return ed25519.Verify(key, body, signature)
+	}) {
+		return ErrBootstrapUntrusted
+	}
+
+	tree, err := toml.LoadBytes(body)
+	if err != nil {
+		return err
+	}
+	settings := tree.ToMap()
+	for k := range settings {
+		if slices.Contains(credentialProperties(), k) || k == identities {
+			return fmt.Errorf("%w: %s", ErrBootstrapSecretSetting, k)
+		}
+		if !slices.Contains(Properties(), k) {
+			return fmt.Errorf("%w: %s", ErrBootstrapUnknownSetting, k)
+		}
+	}
+	// checked before applying anything, so an invalid template doesn't leave the profile half updated
+	if v, ok := settings[service]; ok {
+		if _, err := ParseService(fmt.Sprint(v)); err != nil {
+			return err
+		}
+	}
+	if v, ok := settings[OpsManagerURLField]; ok {
+		if _, err := NormalizeBaseURL(fmt.Sprint(v)); err != nil {
+			return err
+		}
+	}
+
+	// the service is set first, so the ops manager base url is checked against it
+	if v, ok := settings[service]; ok {
+		if err := p.SetValidService(fmt.Sprint(v)); err != nil {
+			return err
+		}
+	}
+	if v, ok := settings[OpsManagerURLField]; ok {
+		if err := p.SetValidOpsManagerURL(fmt.Sprint(v)); err != nil {
+			return err
+		}
+	}
+	for k, v := range settings {
+		switch {
+		case k == readOnly, k == service, k == OpsManagerURLField:
+			// service and ops_manager_url are set above, read_only last, otherwise it would block the rest of the settings
+		case slices.Contains(GlobalProperties(), k):
+			p.SetGlobal(k, v)
+		default:
+			p.Set(k, v)
+		}
+	}
+	if v, ok := settings[readOnly]; ok {
+		p.Set(readOnly, v)
+	}
+
+	return p.Save()
+}
+
+func fetchBootstrap(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bootstrapClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: unexpected status %s", u, resp.Status)
+	}
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxBootstrapSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxBootstrapSize {
+		return nil, fmt.Errorf("%w: %s is larger than %d bytes", ErrBootstrapTooLarge, u, maxBootstrapSize)
+	}
+	return b, nil
+}
+
+// signatureURL returns the URL of the signature of a template, the template URL with a .sig suffix
+// on its path, keeping its query.
+func signatureURL(u *url.URL) string {
+	sig := *u
+	sig.Path += ".sig"
+	if sig.RawPath != "" {
+		sig.RawPath += ".sig"
+	}
+	sig.Fragment = ""
+	sig.RawFragment = ""
+	return sig.String()
+}
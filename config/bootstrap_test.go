@@ -0,0 +1,85 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_BootstrapFromURL(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	templates := map[string]string{
+		"/team.toml":    "service = \"cloudgov\"\norg_id = \"5e2211c17a3e5a48f5497de3\"\ntelemetry_enabled = false\n",
+		"/secret.toml":  "private_api_key = \"secret\"\n",
+		"/unknown.toml": "colour = \"blue\"\n",
+		"/service.toml": "service = \"atlas-classic\"\n",
+		"/om.toml":      "service = \"ops-manager\"\nops_manager_url = \"https://om.example.com/api/public/v1.0\"\n",
+		"/bad-om.toml":  "ops_manager_url = \"ftp://om.example.com\"\n",
+		"/large.toml":   "# " + strings.Repeat("x", maxBootstrapSize) + "\n",
+	}
+	mux := http.NewServeMux()
+	for path, body := range templates {
+		mux.HandleFunc(path, func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(body))
+		})
+		mux.HandleFunc(path+".sig", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(body)))))
+		})
+	}
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+	bootstrapClient = server.Client()
+	defer func() { bootstrapClient = http.DefaultClient }()
+
+	p := newTestProfile(t, "")
+	ctx := context.Background()
+
+	require.ErrorIs(t, p.BootstrapFromURL(ctx, "http://example.com/team.toml"), ErrBootstrapInsecureURL)
+	require.ErrorIs(t, p.BootstrapFromURL(ctx, server.URL+"/team.toml"), ErrBootstrapUntrusted)
+
+	p.SetBootstrapKeys(pub)
+	require.ErrorIs(t, p.BootstrapFromURL(ctx, server.URL+"/secret.toml"), ErrBootstrapSecretSetting)
+	require.ErrorIs(t, p.BootstrapFromURL(ctx, server.URL+"/unknown.toml"), ErrBootstrapUnknownSetting)
+	require.ErrorIs(t, p.BootstrapFromURL(ctx, server.URL+"/service.toml"), ErrUnknownService)
+	require.ErrorIs(t, p.BootstrapFromURL(ctx, server.URL+"/bad-om.toml"), ErrInvalidBaseURL)
+	require.ErrorIs(t, p.BootstrapFromURL(ctx, server.URL+"/large.toml"), ErrBootstrapTooLarge)
+
+	require.NoError(t, p.BootstrapFromURL(ctx, server.URL+"/team.toml"))
+
+	assert.Equal(t, CloudGovService, p.Service())
+	assert.Equal(t, "5e2211c17a3e5a48f5497de3", p.OrgID())
+	assert.False(t, p.TelemetryEnabled())
+	b, err := afero.ReadFile(p.fs, p.Filename())
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "telemetry_enabled = false")
+
+	require.NoError(t, p.BootstrapFromURL(ctx, server.URL+"/om.toml?ref=main#settings"), "the signature is fetched at the path with a .sig suffix")
+	assert.Equal(t, OpsManagerService, p.Service())
+	assert.Equal(t, "https://om.example.com/", p.OpsManagerURL(), "the base url is normalized")
+}
@@ -0,0 +1,85 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// settingsGeneration changes every time settings are set or read from the config file.
+// Profiles share viper, so a change made through one profile invalidates the cache of every profile.
+var settingsGeneration atomic.Uint64
+
+func invalidateSettings() {
+	settingsGeneration.Add(1)
+}
+
+type lookupResult struct {
+	value   any
+	profile string
+}
+
+// settingsCache holds the profile settings resolved from the config file, see lookup.
+// Environment variables and flags are not cached, they are read on every access.
+// Getters are called from the goroutines of HttpClient requests, so a cache is replaced atomically
+// when it's outdated and its entries are guarded by mu.
+type settingsCache struct {
+	generation uint64
+	name       string
+
+	mu      sync.Mutex
+	chain   []string
+	lookups map[string]lookupResult
+}
+
+func (p *Profile) settingsCache() *settingsCache {
+	generation := settingsGeneration.Load()
+	if c := p.cache.Load(); c != nil && c.generation == generation && c.name == p.name {
+		return c
+	}
+	c := &settingsCache{
+		generation: generation,
+		name:       p.name,
+		lookups:    map[string]lookupResult{},
+	}
+	p.cache.Store(c)
+	return c
+}
+
+func (c *settingsCache) getChain() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.chain
+}
+
+func (c *settingsCache) setChain(chain []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.chain = chain
+}
+
+func (c *settingsCache) lookup(key string) (lookupResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.lookups[key]
+	return r, ok
+}
+
+func (c *settingsCache) setLookup(key string, r lookupResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lookups[key] = r
+}
@@ -0,0 +1,53 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_settingsCache(t *testing.T) {
+	p := newTestProfile(t, benchmarkConfig)
+	base := p.sibling("base")
+
+	assert.Equal(t, "json", p.Output())
+	base.SetOutput("yaml")
+	assert.Equal(t, "yaml", p.Output(), "changes through another profile invalidate the cache")
+
+	require.NoError(t, p.SetName("base"))
+	assert.Empty(t, p.ProjectID(), "renaming the profile invalidates the cache")
+}
+
+func TestProfile_settingsCache_concurrent(t *testing.T) {
+	p := newTestProfile(t, benchmarkConfig)
+	invalidateSettings()
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, "json", p.Output())
+			p.ProjectID()
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,171 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCircuitBreakerThreshold = 0 // DefaultCircuitBreakerThreshold the circuit breaker is disabled
+	DefaultCircuitBreakerCooldown  = 30 * time.Second
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError is returned without sending the request while the circuit breaker of a host is open.
+type CircuitOpenError struct {
+	Host       string
+	RetryAfter time.Duration // RetryAfter is how long until a request is sent to the host again
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%v: %s failed repeatedly, retry in %s", ErrCircuitOpen, e.Host, e.RetryAfter.Round(time.Second))
+}
+
+func (*CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// CircuitBreakerThreshold get the number of consecutive failed requests to a host after which HttpClient
+// stops sending requests to it for CircuitBreakerCooldown. Connection errors and 5xx responses are failures.
+// Zero disables the circuit breaker.
+func CircuitBreakerThreshold() int { return Default().CircuitBreakerThreshold() }
+func (p *Profile) CircuitBreakerThreshold() int {
+	return p.GetIntWithDefault(circuitBreakerThreshold, DefaultCircuitBreakerThreshold)
+}
+
+// SetCircuitBreakerThreshold sets the number of consecutive failed requests that trips the circuit breaker.
+func SetCircuitBreakerThreshold(v int) { Default().SetCircuitBreakerThreshold(v) }
+func (p *Profile) SetCircuitBreakerThreshold(v int) {
+	p.Set(circuitBreakerThreshold, v)
+}
+
+// CircuitBreakerCooldown get how long requests to a host fail fast once the circuit breaker trips.
+// After it, a single request probes the host, closing the circuit breaker if it succeeds.
+func CircuitBreakerCooldown() time.Duration { return Default().CircuitBreakerCooldown() }
+func (p *Profile) CircuitBreakerCooldown() time.Duration {
+	return p.GetDurationWithDefault(circuitBreakerCooldown, DefaultCircuitBreakerCooldown)
+}
+
+// SetCircuitBreakerCooldown sets how long requests to a host fail fast once the circuit breaker trips.
+func SetCircuitBreakerCooldown(v time.Duration) { Default().SetCircuitBreakerCooldown(v) }
+func (p *Profile) SetCircuitBreakerCooldown(v time.Duration) {
+	p.Set(circuitBreakerCooldown, v.String())
+}
+
+// circuit is the state of the circuit breaker of a host. It's open while openUntil is in the future,
+// and half-open once it passed, until the probe request completes.
+type circuit struct {
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// circuits are shared by every HttpClient, so scripts creating a client per request still fail fast.
+var circuits = struct {
+	sync.Mutex
+	hosts map[string]*circuit
+}{hosts: map[string]*circuit{}}
+
+type circuitBreakerTransport struct {
+	base      http.RoundTripper
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+}
+
+// circuitBreakerTransport wraps base with a circuit breaker per host, if enabled.
+func (p *Profile) circuitBreakerTransport(base http.RoundTripper) http.RoundTripper {
+	threshold := p.CircuitBreakerThreshold()
+	if threshold <= 0 {
+		return base
+	}
+	return &circuitBreakerTransport{base: base, threshold: threshold, cooldown: p.CircuitBreakerCooldown(), now: time.Now}
+}
+
+// allow returns an error while the circuit of the host is open, or half-open with a probe in flight.
+// It returns true when the request is the probe of a half-open circuit.
+func (t *circuitBreakerTransport) allow(host string) (bool, error) {
+	circuits.Lock()
+	defer circuits.Unlock()
+	c := circuits.hosts[host]
+	if c == nil || c.failures < t.threshold {
+		return false, nil
+	}
+	now := t.now()
+	if now.Before(c.openUntil) || c.probing {
+		return false, &CircuitOpenError{Host: host, RetryAfter: max(c.openUntil.Sub(now), 0)}
+	}
+	c.probing = true
+	return true, nil
+}
+
+func (t *circuitBreakerTransport) record(host string, probe, failed bool) {
+	circuits.Lock()
+	defer circuits.Unlock()
+	c := circuits.hosts[host]
+	if c == nil {
+		c = &circuit{}
+		circuits.hosts[host] = c
+	}
+	if probe {
+		c.probing = false
+	}
+	if !failed {
+		delete(circuits.hosts, host)
+		return
+	}
+	c.failures++
+	if c.failures >= t.threshold {
+		c.openUntil = t.now().Add(t.cooldown)
+	}
+}
+
+func (t *circuitBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	probe, err := t.allow(host)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil && req.Context().Err() != nil {
+		// canceled by the caller, says nothing about the host
+		if probe {
+			t.release(host)
+		}
+		return resp, err
+	}
+	t.record(host, probe, err != nil || resp.StatusCode >= http.StatusInternalServerError)
+	return resp, err
+}
+
+func (t *circuitBreakerTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
+
+// release lets another request probe the host.
+func (*circuitBreakerTransport) release(host string) {
+	circuits.Lock()
+	defer circuits.Unlock()
+	if c := circuits.hosts[host]; c != nil {
+		c.probing = false
+	}
+}
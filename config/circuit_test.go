@@ -0,0 +1,78 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreakerTransport(t *testing.T) {
+	t.Cleanup(func() {
+		circuits.Lock()
+		circuits.hosts = map[string]*circuit{}
+		circuits.Unlock()
+	})
+	status := http.StatusServiceUnavailable
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer s.Close()
+
+	p := newTestProfile(t, "")
+	assert.Equal(t, http.DefaultTransport, p.circuitBreakerTransport(http.DefaultTransport), "disabled by default")
+	p.SetCircuitBreakerThreshold(2)
+	p.SetCircuitBreakerCooldown(time.Minute)
+
+	counter := &countingTransport{base: http.DefaultTransport}
+	transport, ok := p.circuitBreakerTransport(counter).(*circuitBreakerTransport)
+	require.True(t, ok)
+	now := time.Now()
+	transport.now = func() time.Time { return now }
+	client := &http.Client{Transport: transport}
+
+	get := func() error {
+		resp, err := client.Get(s.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	require.NoError(t, get())
+	require.NoError(t, get())
+	var openErr *CircuitOpenError
+	require.ErrorAs(t, get(), &openErr)
+	require.ErrorIs(t, get(), ErrCircuitOpen)
+	assert.Equal(t, time.Minute, openErr.RetryAfter)
+	assert.Len(t, counter.hosts, 2, "fails fast once open")
+
+	now = now.Add(time.Minute)
+	require.NoError(t, get(), "the probe is sent once the cooldown passed")
+	require.ErrorIs(t, get(), ErrCircuitOpen, "a failed probe opens the circuit again")
+	assert.Len(t, counter.hosts, 3)
+
+	now = now.Add(time.Minute)
+	status = http.StatusOK
+	require.NoError(t, get())
+	require.NoError(t, get(), "a successful probe closes the circuit")
+	assert.Len(t, counter.hosts, 5)
+}
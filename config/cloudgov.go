@@ -0,0 +1,142 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// cloudGovDomain is the domain of the Atlas for Government endpoints.
+const cloudGovDomain = "mongodbgov.com"
+
+var ErrCloudGovMismatch = errors.New("setting doesn't belong to Atlas for Government")
+
+// CloudGovError is returned when a cloudgov profile points at commercial Atlas.
+type CloudGovError struct {
+	Key    string
+	Value  string
+	Reason string
+}
+
+func (e *CloudGovError) Error() string {
+	return fmt.Sprintf("%v: %s %q %s", ErrCloudGovMismatch, e.Key, e.Value, e.Reason)
+}
+
+func (*CloudGovError) Unwrap() error {
+	return ErrCloudGovMismatch
+}
+
+// IsCloudGovURL returns true for https URLs in the Atlas for Government domain, like https://cloud.mongodbgov.com/.
+func IsCloudGovURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host == cloudGovDomain || strings.HasSuffix(host, "."+cloudGovDomain)
+}
+
+// ValidateCloudGov returns a CloudGovError if a cloudgov profile points at commercial Atlas,
+// call it after loading the config to report mistakes early. It checks that the base URLs are in the
+// Atlas for Government domain, and that the organization and project are not used by profiles of another service,
+// IDs don't tell which environment they belong to otherwise.
+func ValidateCloudGov() error { return Default().ValidateCloudGov() }
+func (p *Profile) ValidateCloudGov() error {
+	if p.CurrentService() != CloudGovService {
+		return nil
+	}
+	return p.checkCloudGov()
+}
+
+func (p *Profile) checkCloudGov() error {
+	for _, key := range []string{OpsManagerURLField, authURL, tokenURL} {
+		if err := checkCloudGovURL(key, p.GetString(key)); err != nil {
+			return err
+		}
+	}
+	for _, u := range p.FallbackBaseURLs() {
+		if err := checkCloudGovURL(fallbackBaseURLs, u); err != nil {
+			return err
+		}
+	}
+	if u, ok := p.endpointBaseURL(); ok {
+		if err := checkCloudGovURL(p.endpointKey(), u); err != nil {
+			return err
+		}
+	}
+	if err := p.checkCloudGovID(orgID, p.OrgID()); err != nil {
+		return err
+	}
+	return p.checkCloudGovID(projectID, p.ProjectID())
+}
+
+func checkCloudGovURL(key, v string) error {
+	if v != "" && !IsCloudGovURL(v) {
+		return &CloudGovError{Key: key, Value: v, Reason: "is not in the " + cloudGovDomain + " domain"}
+	}
+	return nil
+}
+
+// checkCloudGovID refuses an ID set in a profile of another service.
+func (p *Profile) checkCloudGovID(key, id string) error {
+	if id == "" {
+		return nil
+	}
+	for _, name := range List() {
+		if name == p.Name() {
+			continue
+		}
+		other := p.sibling(name)
+		if v, _ := other.lookup(key); fmt.Sprint(v) != id {
+			continue
+		}
+		s, _ := other.lookup(service)
+		str, _ := s.(string)
+		if svc, _ := ParseService(str); svc != CloudGovService {
+			return &CloudGovError{Key: key, Value: id, Reason: fmt.Sprintf("is used by profile %q of another service", name)}
+		}
+	}
+	return nil
+}
+
+// cloudGovTransport refuses requests outside the Atlas for Government domain for cloudgov profiles.
+func (p *Profile) cloudGovTransport(base http.RoundTripper) http.RoundTripper {
+	if p.CurrentService() != CloudGovService {
+		return base
+	}
+	return cloudGovTransport{base: base}
+}
+
+type cloudGovTransport struct {
+	base http.RoundTripper
+}
+
+func (t cloudGovTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !IsCloudGovURL(req.URL.String()) {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, &CloudGovError{Key: "request URL", Value: req.URL.Redacted(), Reason: "is not in the " + cloudGovDomain + " domain"}
+	}
+	return t.base.RoundTrip(req)
+}
+
+func (t cloudGovTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_ValidateCloudGov(t *testing.T) {
+	p := newTestProfile(t, `
+[default]
+  service = "cloudgov"
+  org_id = "5e2211c17a3e5a48f5497de3"
+  ops_manager_url = "https://cloud.mongodbgov.com/"
+
+[commercial]
+  org_id = "5e2211c17a3e5a48f5497de4"
+
+[gov]
+  service = "cloudgov"
+  org_id = "5e2211c17a3e5a48f5497de5"
+`)
+	require.NoError(t, p.ValidateCloudGov())
+
+	require.ErrorIs(t, p.SetValidOpsManagerURL("https://cloud.mongodb.com/"), ErrCloudGovMismatch)
+	require.NoError(t, p.SetValidOpsManagerURL("https://cloud-qa.mongodbgov.com/"))
+
+	err := p.SetValidOrgID("5e2211c17a3e5a48f5497de4")
+	var govErr *CloudGovError
+	require.ErrorAs(t, err, &govErr)
+	require.Equal(t, orgID, govErr.Key)
+	require.Contains(t, govErr.Reason, `"commercial"`)
+	require.NoError(t, p.SetValidOrgID("5e2211c17a3e5a48f5497de5"), "IDs can be shared by cloudgov profiles")
+
+	p.SetOpsManagerURL("https://cloud.mongodb.com/")
+	require.ErrorIs(t, p.ValidateCloudGov(), ErrCloudGovMismatch)
+	_, err = p.HttpClient().Get("https://cloud.mongodb.com/api/atlas/v2")
+	require.ErrorIs(t, err, ErrCloudGovMismatch)
+
+	c := p.sibling("commercial")
+	c.SetOpsManagerURL("https://cloud.mongodb.com/")
+	require.NoError(t, c.ValidateCloudGov(), "only cloudgov profiles are checked")
+	require.ErrorIs(t, c.SetValidService(CloudGovService), ErrCloudGovMismatch)
+}
+
+func TestIsCloudGovURL(t *testing.T) {
+	require.True(t, IsCloudGovURL("https://cloud.mongodbgov.com/"))
+	require.False(t, IsCloudGovURL("http://cloud.mongodbgov.com/"))
+	require.False(t, IsCloudGovURL("https://mongodbgov.com.example.com/"))
+	require.False(t, IsCloudGovURL("https://cloud.mongodb.com/"))
+}
@@ -0,0 +1,105 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidColumn = errors.New("invalid column name")
+
+var columnRegex = regexp.MustCompile(`^[A-Z0-9]+(_[A-Z0-9]+)*$`)
+
+// ColumnName normalizes a column header to the name used in the columns setting, like MDB_VER for "mdb ver".
+func ColumnName(header string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(strings.ReplaceAll(header, "_", " ")), "_"))
+}
+
+// Columns get the columns of the plaintext output configured for a resource, like clusters,
+// in the [<profile>.columns] table of the config file, nil to use the default columns of the command.
+func Columns(resource string) []string { return Default().Columns(resource) }
+func (p *Profile) Columns(resource string) []string {
+	all, _ := p.lookup(columns)
+	m, _ := all.(map[string]any)
+	var names []string
+	switch v := m[strings.ToLower(resource)].(type) {
+	case string:
+		names = strings.Split(v, ",")
+	case []any:
+		for _, name := range v {
+			names = append(names, fmt.Sprint(name))
+		}
+	case []string:
+		names = v
+	}
+	selected := make([]string, 0, len(names))
+	for _, name := range names {
+		if name = ColumnName(name); name != "" {
+			selected = append(selected, name)
+		}
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+	return selected
+}
+
+// SetColumns sets the columns of the plaintext output for a resource, like
+// SetColumns("clusters", "NAME", "STATE", "MDB_VER"), no columns restores the default columns of the command.
+func SetColumns(resource string, names ...string) error {
+	return Default().SetColumns(resource, names...)
+}
+func (p *Profile) SetColumns(resource string, names ...string) error {
+	normalized := make([]string, len(names))
+	for i, name := range names {
+		normalized[i] = ColumnName(name)
+		if !columnRegex.MatchString(normalized[i]) {
+			return fmt.Errorf("%w: %q", ErrInvalidColumn, name)
+		}
+	}
+
+	all := map[string]any{}
+	if v, _ := p.lookup(columns); v != nil {
+		m, _ := v.(map[string]any)
+		for k, v := range m {
+			all[k] = v
+		}
+	}
+	resource = strings.ToLower(resource)
+	if len(normalized) == 0 {
+		delete(all, resource)
+	} else {
+		all[resource] = strings.Join(normalized, ",")
+	}
+	p.Set(columns, all)
+	return nil
+}
+
+// ColumnResources returns the resources that have columns configured in the profile.
+func ColumnResources() []string { return Default().ColumnResources() }
+func (p *Profile) ColumnResources() []string {
+	all, _ := p.lookup(columns)
+	m, _ := all.(map[string]any)
+	resources := make([]string, 0, len(m))
+	for r := range m {
+		resources = append(resources, r)
+	}
+	sort.Strings(resources)
+	return resources
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_Columns(t *testing.T) {
+	p := newTestProfile(t, `[default]
+  [default.columns]
+    clusters = "name, state,mdb ver"
+    projects = ["id", "NAME"]
+    empty = ""
+`)
+
+	assert.Equal(t, []string{"NAME", "STATE", "MDB_VER"}, p.Columns("clusters"))
+	assert.Equal(t, []string{"NAME", "STATE", "MDB_VER"}, p.Columns("Clusters"))
+	assert.Equal(t, []string{"ID", "NAME"}, p.Columns("projects"))
+	assert.Nil(t, p.Columns("empty"))
+	assert.Nil(t, p.Columns("users"))
+	assert.Equal(t, []string{"clusters", "empty", "projects"}, p.ColumnResources())
+	assert.NotContains(t, p.Map(), columns)
+}
+
+func TestProfile_SetColumns(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  org_id = \""+testOrgA+"\"\n")
+
+	require.ErrorIs(t, p.SetColumns("clusters", "NAME", "STATE!"), ErrInvalidColumn)
+	require.ErrorIs(t, p.SetColumns("clusters", ""), ErrInvalidColumn)
+
+	require.NoError(t, p.SetColumns("clusters", "name", "mdb ver"))
+	require.NoError(t, p.SetColumns("projects", "ID"))
+	require.NoError(t, p.Save())
+	assert.Equal(t, []string{"NAME", "MDB_VER"}, p.Columns("clusters"))
+
+	b, err := afero.ReadFile(p.fs, p.Filename())
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "clusters = 'NAME,MDB_VER'")
+
+	require.NoError(t, p.SetColumns("clusters"))
+	assert.Nil(t, p.Columns("clusters"))
+	assert.Equal(t, []string{"projects"}, p.ColumnResources())
+}
+
+func TestColumnName(t *testing.T) {
+	assert.Equal(t, "MDB_VER", ColumnName(" mdb  ver "))
+	assert.Equal(t, "MDB_VER", ColumnName("mdb_ver"))
+	assert.Equal(t, "PUBLICKEY", ColumnName("PublicKey"))
+	assert.Empty(t, ColumnName(" "))
+}
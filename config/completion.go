@@ -0,0 +1,87 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// CompletionsFor returns the valid values of a setting starting with partialValue, to generate the shell completion
+// of commands setting it, like atlas config set. Settings taking any value, like IDs or URLs, return nil.
+func CompletionsFor(key, partialValue string) []string {
+	return Default().CompletionsFor(key, partialValue)
+}
+func (p *Profile) CompletionsFor(key, partialValue string) []string {
+	var values []string
+	key = strings.ToLower(key)
+	switch key {
+	case service:
+		for _, s := range Services() {
+			values = append(values, string(s))
+		}
+	case output:
+		for _, f := range OutputFormats() {
+			values = append(values, string(f))
+		}
+	case color:
+		values = []string{string(ColorAuto), string(ColorAlways), string(ColorNever)}
+	case timeFormat:
+		values = []string{string(RFC3339Time), string(LocalTime), string(RelativeTime)}
+	case endpointType:
+		values = []string{PublicEndpoint, PrivateEndpoint}
+	case ipFamily:
+		values = []string{IPFamilyAuto, IPFamilyIPv4, IPFamilyIPv6}
+	case environmentClass:
+		values = []string{DevelopmentEnvironment, StagingEnvironment, ProductionEnvironment}
+	case verbosity:
+		values = []string{VerbosityQuiet.String(), VerbosityNormal.String(), VerbosityVerbose.String(), VerbosityDebug.String()}
+	case telemetryConsent:
+		values = []string{string(ConsentRequested), string(ConsentGranted), string(ConsentDenied)}
+	case apiVersion:
+		values = KnownAPIVersions
+	case apiRegion:
+		values = p.CurrentService().Endpoints().Regions
+	case inherits:
+		values = slices.DeleteFunc(List(), func(name string) bool { return name == p.Name() })
+	case proxyAuth:
+		values = proxyAuthSchemes()
+	default:
+		if slices.Contains(BooleanProperties(), key) {
+			values = []string{"true", "false"}
+		}
+	}
+
+	var completions []string
+	for _, v := range values {
+		if strings.HasPrefix(strings.ToLower(v), strings.ToLower(partialValue)) {
+			completions = append(completions, v)
+		}
+	}
+	return completions
+}
+
+// proxyAuthSchemes returns the schemes of the registered proxy authenticators, see RegisterProxyAuthenticator.
+func proxyAuthSchemes() []string {
+	proxyAuthenticators.RLock()
+	defer proxyAuthenticators.RUnlock()
+	schemes := make([]string, 0, len(proxyAuthenticators.byScheme))
+	for _, a := range proxyAuthenticators.byScheme {
+		schemes = append(schemes, a.scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
@@ -0,0 +1,40 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile_CompletionsFor(t *testing.T) {
+	p := newTestProfile(t, inheritanceConfig)
+
+	assert.Equal(t, []string{CloudService, CloudGovService, CloudManagerService}, p.CompletionsFor(service, "cl"))
+	assert.Equal(t, []string{"json", "json-path"}, p.CompletionsFor("OUTPUT", "JS"))
+	assert.Equal(t, []string{"true"}, p.CompletionsFor(offline, "t"))
+	assert.Equal(t, []string{"true", "false"}, p.CompletionsFor(previewFeatures, ""))
+	assert.Equal(t, []string{IPFamilyIPv4, IPFamilyIPv6}, p.CompletionsFor(ipFamily, "ip"))
+	assert.ElementsMatch(t, []string{"base", "team"}, p.CompletionsFor(inherits, ""))
+	assert.Nil(t, p.CompletionsFor(projectID, ""), "IDs take any value")
+	assert.Nil(t, p.CompletionsFor(service, "x"))
+
+	RegisterProxyAuthenticator("Test", &testAuthenticator{})
+	t.Cleanup(func() { RegisterProxyAuthenticator("Test", nil) })
+	assert.Equal(t, []string{"Test"}, p.CompletionsFor(proxyAuth, "te"))
+}
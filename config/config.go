@@ -16,14 +16,14 @@ package config
 
 import (
 	"bytes"
-	"fmt"
 	"os"
 	"path"
-	"runtime"
 	"slices"
 	"sort"
 	"strings"
+	"sync"
 
+	"github.com/mongodb/atlas-cli-core/environment"
 	"github.com/spf13/viper"
 )
 
@@ -35,6 +35,8 @@ const (
 	DefaultProfile           = "default"       // DefaultProfile default
 	CloudService             = "cloud"         // CloudService setting when using Atlas API
 	CloudGovService          = "cloudgov"      // CloudGovService setting when using Atlas API for Government
+	OpsManagerService        = "ops-manager"   // OpsManagerService setting when using Ops Manager API
+	CloudManagerService      = "cloud-manager" // CloudManagerService setting when using Cloud Manager API
 	projectID                = "project_id"
 	orgID                    = "org_id"
 	mongoShellPath           = "mongosh_path"
@@ -52,11 +54,63 @@ const (
 	configPerm               = 0600
 	defaultPermissions       = 0700
 	skipUpdateCheck          = "skip_update_check"
+	readOnly                 = "read_only"
+	inherits                 = "inherits"
+	httpTimeout              = "http_timeout"
+	dialTimeout              = "dial_timeout"
+	responseHeaderTimeout    = "response_header_timeout"
+	maxIdleConnsPerHost      = "max_idle_conns_per_host"
+	maxConnsPerHost          = "max_conns_per_host"
+	idleConnTimeout          = "idle_conn_timeout"
+	fallbackBaseURLs         = "fallback_base_urls"
+	color                    = "color"
+	pager                    = "pager"
+	locale                   = "locale"
+	timeFormat               = "time_format"
+	encryptSecrets           = "encrypt_secrets"
+	clientCertFile           = "client_cert_file"
+	clientKeyFile            = "client_key_file"
+	proxyAuth                = "proxy_auth"
+	proxyURL                 = "proxy"
+	sshJumpHost              = "ssh_jump_host"
+	offline                  = "offline"
+	fipsMode                 = "fips_mode"
+	jwksURL                  = "jwks_url"
+	authURL                  = "auth_url"
+	tokenURL                 = "token_url"
+	identities               = "identities"
+	orgs                     = "orgs"
+	circuitBreakerThreshold  = "circuit_breaker_threshold"
+	circuitBreakerCooldown   = "circuit_breaker_cooldown"
+	maxRequestsPerHost       = "max_requests_per_host"
+	maxResponseSize          = "max_response_size"
+	apiVersion               = "api_version"
+	previewFeatures          = "preview_features"
+	apiRegion                = "api_region"
+	endpointType             = "endpoint_type"
+	resolve                  = "resolve"
+	ipFamily                 = "ip_family"
+	tcpKeepAlive             = "keep_alive"
+	disableKeepAlives        = "disable_keep_alives"
+	environmentClass         = "environment"
+	recordActivity           = "record_activity"
+	dryRun                   = "dry_run"
+	demoFixtures             = "demo_fixtures"
+	columns                  = "columns"
+	verbosity                = "verbosity"
+	telemetrySampleRate      = "telemetry_sample_rate"
+	telemetryBatchSize       = "telemetry_batch_size"
+	telemetryFlushInterval   = "telemetry_flush_interval"
+	crashReports             = "crash_reports"
+	telemetryConsent         = "telemetry_consent"
+	telemetrySpoolMaxSize    = "telemetry_spool_max_size"
+	telemetrySpoolMaxAge     = "telemetry_spool_max_age"
 	TelemetryEnabledProperty = "telemetry_enabled"
 	AtlasCLI                 = "atlascli"
-	ContainerizedHostNameEnv = "MONGODB_ATLAS_IS_CONTAINERIZED"
-	GitHubActionsHostNameEnv = "GITHUB_ACTIONS"
-	AtlasActionHostNameEnv   = "ATLAS_GITHUB_ACTION"
+	MongoCLI                 = "mongocli"
+	ContainerizedHostNameEnv = environment.ContainerizedEnv
+	GitHubActionsHostNameEnv = environment.GitHubActionsEnv
+	AtlasActionHostNameEnv   = environment.AtlasActionEnv
 	CLIUserTypeEnv           = "CLI_USER_TYPE" // CLIUserTypeEnv is used to separate MongoDB University users from default users
 	DefaultUser              = "default"       // Users that do NOT use ATLAS CLI with MongoDB University
 	UniversityUser           = "university"    // Users that uses ATLAS CLI with MongoDB University
@@ -64,11 +118,16 @@ const (
 	DockerContainerHostName  = "container"
 	GitHubActionsHostName    = "all_github_actions"
 	AtlasActionHostName      = "atlascli_github_action"
+	ConfigPermissionsEnv     = "MONGODB_ATLAS_CONFIG_PERMISSIONS" // ConfigPermissionsEnv overrides the PermissionsPolicy, one of warn, fix or ignore
+	NoColorEnv               = "NO_COLOR"                         // NoColorEnv disables colors when set to any value, see https://no-color.org
+	PagerEnv                 = "PAGER"
+	CredentialsDirectoryEnv  = "CREDENTIALS_DIRECTORY"             // CredentialsDirectoryEnv is set by systemd for services using LoadCredential=
+	AccessTokenExpiryEnv     = "MONGODB_ATLAS_ACCESS_TOKEN_EXPIRY" // AccessTokenExpiryEnv is when an access token set in the environment stops being used, in RFC 3339
 )
 
 var (
-	HostName       = getConfigHostnameFromEnvs()
-	CLIUserType    = newCLIUserTypeFromEnvs()
+	HostName    = getConfigHostnameFromEnvs()
+	CLIUserType = newCLIUserTypeFromEnvs()
 )
 
 type Setter interface {
@@ -104,6 +163,58 @@ func Properties() []string {
 		TelemetryEnabledProperty,
 		AccessTokenField,
 		RefreshTokenField,
+		readOnly,
+		inherits,
+		httpTimeout,
+		dialTimeout,
+		responseHeaderTimeout,
+		maxIdleConnsPerHost,
+		maxConnsPerHost,
+		idleConnTimeout,
+		fallbackBaseURLs,
+		color,
+		pager,
+		locale,
+		timeFormat,
+		encryptSecrets,
+		clientCertFile,
+		clientKeyFile,
+		proxyAuth,
+		proxyURL,
+		sshJumpHost,
+		offline,
+		fipsMode,
+		jwksURL,
+		authURL,
+		tokenURL,
+		ClientIDField,
+		identities,
+		orgs,
+		circuitBreakerThreshold,
+		circuitBreakerCooldown,
+		maxRequestsPerHost,
+		maxResponseSize,
+		apiVersion,
+		previewFeatures,
+		apiRegion,
+		endpointType,
+		resolve,
+		ipFamily,
+		tcpKeepAlive,
+		disableKeepAlives,
+		environmentClass,
+		recordActivity,
+		dryRun,
+		demoFixtures,
+		columns,
+		verbosity,
+		telemetrySampleRate,
+		telemetryBatchSize,
+		telemetryFlushInterval,
+		crashReports,
+		telemetryConsent,
+		telemetrySpoolMaxSize,
+		telemetrySpoolMaxAge,
 	}
 }
 
@@ -111,6 +222,26 @@ func BooleanProperties() []string {
 	return []string{
 		skipUpdateCheck,
 		TelemetryEnabledProperty,
+		readOnly,
+		encryptSecrets,
+		offline,
+		fipsMode,
+		previewFeatures,
+		disableKeepAlives,
+		recordActivity,
+		dryRun,
+		crashReports,
+	}
+}
+
+// ProfileOverridableProperties returns the global properties that can also be set per profile.
+// For these the precedence is: flag, environment variable, profile, global setting, default.
+func ProfileOverridableProperties() []string {
+	return []string{
+		skipUpdateCheck,
+		TelemetryEnabledProperty,
+		offline,
+		verbosity,
 	}
 }
 
@@ -119,6 +250,17 @@ func GlobalProperties() []string {
 		skipUpdateCheck,
 		TelemetryEnabledProperty,
 		mongoShellPath,
+		encryptSecrets,
+		offline,
+		fipsMode,
+		verbosity,
+		telemetrySampleRate,
+		telemetryBatchSize,
+		telemetryFlushInterval,
+		crashReports,
+		telemetryConsent,
+		telemetrySpoolMaxSize,
+		telemetrySpoolMaxAge,
 	}
 }
 
@@ -131,12 +273,14 @@ func IsTrue(s string) bool {
 	}
 }
 
-func UserAgent(version string) string {
-	return fmt.Sprintf("%s/%s (%s;%s;%s)", AtlasCLI, version, runtime.GOOS, runtime.GOARCH, HostName)
-}
-
 // List returns the names of available profiles.
-func List() []string {
+func List() []string { return Default().List() }
+func (p *Profile) List() []string {
+	p.ensureLoaded()
+	if names, ok := indexedProfiles(); ok {
+		return names
+	}
+
 	m := viper.AllSettings()
 
 	keys := make([]string, 0, len(m))
@@ -151,26 +295,28 @@ func List() []string {
 }
 
 // Exists returns true if there are any set settings for the profile name.
-func Exists(name string) bool {
-	return slices.Contains(List(), name)
+func Exists(name string) bool { return Default().Exists(name) }
+func (p *Profile) Exists(name string) bool {
+	return slices.Contains(p.List(), name)
 }
 
-// getConfigHostnameFromEnvs patches the agent hostname based on set env vars.
+// getConfigHostnameFromEnvs patches the agent hostname based on the detected environment.
 func getConfigHostnameFromEnvs() string {
 	var builder strings.Builder
+	env := environment.Detect()
 
-	envVars := []struct {
-		envName  string
+	detected := []struct {
+		isSet    bool
 		hostName string
 	}{
-		{AtlasActionHostNameEnv, AtlasActionHostName},
-		{GitHubActionsHostNameEnv, GitHubActionsHostName},
-		{ContainerizedHostNameEnv, DockerContainerHostName},
+		{env.AtlasAction, AtlasActionHostName},
+		{env.CI == environment.GitHubActions, GitHubActionsHostName},
+		{env.Container, DockerContainerHostName},
 	}
 
-	for _, envVar := range envVars {
-		if envIsTrue(envVar.envName) {
-			appendToHostName(&builder, envVar.hostName)
+	for _, d := range detected {
+		if d.isSet {
+			appendToHostName(&builder, d.hostName)
 		} else {
 			appendToHostName(&builder, "-")
 		}
@@ -192,10 +338,6 @@ func newCLIUserTypeFromEnvs() string {
 	return DefaultUser
 }
 
-func envIsTrue(env string) bool {
-	return IsTrue(os.Getenv(env))
-}
-
 func appendToHostName(builder *strings.Builder, configVal string) {
 	if builder.Len() > 0 {
 		builder.WriteString("|")
@@ -215,11 +357,28 @@ func boolEnv(key string) bool {
 }
 
 func isTelemetryFeatureAllowed() bool {
-	doNotTrack := boolEnv("DO_NOT_TRACK")
+	doNotTrack := boolEnv(DoNotTrackEnv)
 	return !doNotTrack
 }
 
+// Values derived from the environment are computed once per process, see ResetEnvCache.
+var (
+	cachedMongoCLIEnvVars = sync.OnceValue(scanMongoCLIEnvVars)
+	cachedCLIConfigHome   = sync.OnceValues(cliConfigHome)
+)
+
+// ResetEnvCache clears the values derived from the environment, like CLIConfigHome,
+// so they are computed again after the environment changes, for example in tests.
+func ResetEnvCache() {
+	cachedMongoCLIEnvVars = sync.OnceValue(scanMongoCLIEnvVars)
+	cachedCLIConfigHome = sync.OnceValues(cliConfigHome)
+}
+
 func hasMongoCLIEnvVars() bool {
+	return cachedMongoCLIEnvVars()
+}
+
+func scanMongoCLIEnvVars() bool {
 	envVars := os.Environ()
 	for _, v := range envVars {
 		if strings.HasPrefix(v, MongoCLIEnvPrefix) {
@@ -232,6 +391,10 @@ func hasMongoCLIEnvVars() bool {
 
 // CLIConfigHome retrieves configHome path.
 func CLIConfigHome() (string, error) {
+	return cachedCLIConfigHome()
+}
+
+func cliConfigHome() (string, error) {
 	home, err := os.UserConfigDir()
 	if err != nil {
 		return "", err
@@ -17,11 +17,15 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path"
+	"strings"
 	"testing"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCLIConfigHome(t *testing.T) {
@@ -189,3 +193,77 @@ func Test_getConfigHostname(t *testing.T) {
 		})
 	}
 }
+
+func TestResetEnvCache(t *testing.T) {
+	t.Cleanup(ResetEnvCache)
+	t.Setenv("XDG_CONFIG_HOME", "/first")
+	t.Setenv("HOME", "/home/first")
+	ResetEnvCache()
+	first, err := CLIConfigHome()
+	assert.NoError(t, err)
+
+	t.Setenv("XDG_CONFIG_HOME", "/second")
+	t.Setenv("HOME", "/home/second")
+	cached, err := CLIConfigHome()
+	assert.NoError(t, err)
+	assert.Equal(t, first, cached)
+
+	ResetEnvCache()
+	second, err := CLIConfigHome()
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, second)
+}
+
+func TestList(t *testing.T) {
+	p := newTestProfile(t, `skip_update_check = true
+work.org_id = "a"
+inline = { org_id = "b" }
+pager = """
+[not_a_profile]
+"""
+
+[default]
+  org_id = "c"
+  [default.nested]
+    key = "value"
+
+# comment
+['Quoted.Name']
+  org_id = "d"
+
+[output]
+  org_id = "f"
+`)
+	require.Equal(t, []string{"default", "inline", "output", "quoted.name", "work"}, List(), "profiles can be named like a profile setting")
+
+	other := p.sibling("new")
+	other.SetOrgID("e")
+	require.Equal(t, []string{"default", "inline", "new", "output", "quoted.name", "work"}, List(), "unsaved profiles are listed")
+
+	require.NoError(t, p.Delete())
+	require.NotContains(t, List(), "default")
+	require.True(t, Exists("new"))
+}
+
+func TestList_WithoutIndex(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.Set("work", map[string]any{orgID: "a"})
+	viper.Set(skipUpdateCheck, true)
+
+	require.Equal(t, []string{"work"}, List())
+}
+
+func BenchmarkList(b *testing.B) {
+	var contents strings.Builder
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&contents, "[profile%d]\n  org_id = \"%d\"\n  project_id = \"%d\"\n  output = \"json\"\n\n", i, i, i)
+	}
+	newTestProfile(b, contents.String())
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = List()
+	}
+}
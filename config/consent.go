@@ -0,0 +1,85 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mongodb/atlas-cli-core/environment"
+)
+
+// ConsentState is the answer of the user to the telemetry consent prompt, stored once per installation.
+//
+//	ConsentUnknown -> ConsentRequested -> ConsentGranted or ConsentDenied
+//
+// Users can change their decision later, see SetTelemetryConsent.
+type ConsentState string
+
+const (
+	ConsentUnknown   ConsentState = ""          // ConsentUnknown the user was never asked
+	ConsentRequested ConsentState = "requested" // ConsentRequested the user was asked but didn't answer, like when interrupting the prompt
+	ConsentGranted   ConsentState = "granted"
+	ConsentDenied    ConsentState = "denied"
+)
+
+var ErrConsentDecided = errors.New("telemetry consent was already decided")
+
+// TelemetryConsent gets the state of the telemetry consent, invalid values are ConsentUnknown.
+func TelemetryConsent() ConsentState { return Default().TelemetryConsent() }
+func (p *Profile) TelemetryConsent() ConsentState {
+	switch s := ConsentState(p.GetString(telemetryConsent)); s {
+	case ConsentRequested, ConsentGranted, ConsentDenied:
+		return s
+	default:
+		return ConsentUnknown
+	}
+}
+
+// ShouldPromptForConsent returns true when the CLI should ask the user whether telemetry can be collected:
+// the user didn't decide yet, telemetry_enabled isn't set, nothing like DO_NOT_TRACK disables telemetry
+// and the user can be prompted, see environment.IsInteractive.
+func ShouldPromptForConsent() bool { return Default().ShouldPromptForConsent() }
+func (p *Profile) ShouldPromptForConsent() bool {
+	switch p.TelemetryConsent() {
+	case ConsentGranted, ConsentDenied:
+		return false
+	}
+	return p.TelemetryPolicy().Source == SourceDefault && environment.IsInteractive()
+}
+
+// RequestTelemetryConsent records that the user is being asked for the telemetry consent, before showing the prompt.
+// It returns ErrConsentDecided when the user already answered. Call Save to persist the state.
+func RequestTelemetryConsent() error { return Default().RequestTelemetryConsent() }
+func (p *Profile) RequestTelemetryConsent() error {
+	if s := p.TelemetryConsent(); s == ConsentGranted || s == ConsentDenied {
+		return fmt.Errorf("%w: %s", ErrConsentDecided, s)
+	}
+	p.SetGlobal(telemetryConsent, string(ConsentRequested))
+	return nil
+}
+
+// SetTelemetryConsent records the decision of the user and enables or disables telemetry accordingly.
+// Granting the consent while DO_NOT_TRACK is set records the decision but returns a TelemetryOverriddenError.
+// Call Save to persist the decision.
+func SetTelemetryConsent(granted bool) error { return Default().SetTelemetryConsent(granted) }
+func (p *Profile) SetTelemetryConsent(granted bool) error {
+	state := ConsentDenied
+	if granted {
+		state = ConsentGranted
+	}
+	p.SetGlobal(telemetryConsent, string(state))
+	return p.SetTelemetryEnabled(granted)
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/mongodb/atlas-cli-core/environment"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_TelemetryConsent(t *testing.T) {
+	t.Setenv(DoNotTrackEnv, "")
+	t.Setenv(environment.NonInteractiveEnv, "false")
+	p := newTestProfile(t, "")
+	assert.Equal(t, ConsentUnknown, p.TelemetryConsent())
+	assert.True(t, p.ShouldPromptForConsent())
+
+	require.NoError(t, p.RequestTelemetryConsent())
+	assert.Equal(t, ConsentRequested, p.TelemetryConsent())
+	assert.True(t, p.ShouldPromptForConsent(), "the prompt is shown again until the user answers")
+
+	require.NoError(t, p.SetTelemetryConsent(false))
+	require.NoError(t, p.Save())
+	assert.Equal(t, ConsentDenied, p.TelemetryConsent())
+	assert.False(t, p.TelemetryPolicy().Enabled)
+	assert.False(t, p.ShouldPromptForConsent())
+	require.ErrorIs(t, p.RequestTelemetryConsent(), ErrConsentDecided)
+
+	require.NoError(t, p.SetTelemetryConsent(true))
+	assert.Equal(t, ConsentGranted, p.TelemetryConsent())
+	assert.True(t, p.TelemetryPolicy().Enabled)
+
+	p.SetGlobal(telemetryConsent, "maybe")
+	assert.Equal(t, ConsentUnknown, p.TelemetryConsent(), "invalid values are unknown")
+}
+
+func TestProfile_ShouldPromptForConsent(t *testing.T) {
+	t.Setenv(DoNotTrackEnv, "")
+	t.Setenv(environment.NonInteractiveEnv, "false")
+
+	p := newTestProfile(t, "telemetry_enabled = false\n")
+	assert.False(t, p.ShouldPromptForConsent(), "telemetry_enabled was set by the user")
+
+	p = newTestProfile(t, "")
+	t.Setenv(environment.NonInteractiveEnv, "true")
+	assert.False(t, p.ShouldPromptForConsent(), "the user can't be prompted")
+
+	t.Setenv(environment.NonInteractiveEnv, "false")
+	t.Setenv(DoNotTrackEnv, "1")
+	assert.False(t, p.ShouldPromptForConsent())
+	require.ErrorIs(t, p.SetTelemetryConsent(true), ErrTelemetryOverridden)
+	assert.Equal(t, ConsentGranted, p.TelemetryConsent(), "the decision is recorded anyway")
+}
@@ -0,0 +1,164 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const crashReportsDir = "crashes"
+
+// CrashReport describes a panic of the CLI, saved when crash_reports is enabled, see SaveCrashReport.
+type CrashReport struct {
+	ID        string      `json:"id"`
+	Time      time.Time   `json:"time"`
+	Version   string      `json:"version,omitempty"` // Version of the CLI
+	Command   string      `json:"command,omitempty"`
+	Panic     string      `json:"panic"`
+	Stack     string      `json:"stack"`
+	OS        string      `json:"os"`
+	Arch      string      `json:"arch"`
+	GoVersion string      `json:"go_version"`
+	Config    ProfileView `json:"config"` // Config is the profile without its secrets, see View
+}
+
+// CrashReports get whether panics are saved as crash reports, it's disabled by default.
+func CrashReports() bool { return Default().CrashReports() }
+func (p *Profile) CrashReports() bool {
+	return p.GetBool(crashReports)
+}
+
+// SetCrashReports sets whether panics are saved as crash reports, to be sent with the telemetry on the next run.
+func SetCrashReports(v bool) { Default().SetCrashReports(v) }
+func (p *Profile) SetCrashReports(v bool) {
+	p.SetGlobal(crashReports, v)
+}
+
+// CrashReportsDir returns the directory keeping the crash reports, next to the config file.
+func CrashReportsDir() string { return Default().CrashReportsDir() }
+func (p *Profile) CrashReportsDir() string {
+	return filepath.Join(p.configDir, crashReportsDir)
+}
+
+// NewCrashReport returns the report of a panic with its recovered value and stack. The credentials of the profile
+// are redacted from the panic and the stack, and the config snapshot has no secrets.
+func NewCrashReport(recovered any, stack []byte, version, command string) CrashReport {
+	return Default().NewCrashReport(recovered, stack, version, command)
+}
+func (p *Profile) NewCrashReport(recovered any, stack []byte, version, command string) CrashReport {
+	now := time.Now().UTC()
+	return CrashReport{
+		ID:        fmt.Sprintf("%s-%s", now.Format("20060102T150405Z"), SessionID()),
+		Time:      now,
+		Version:   version,
+		Command:   command,
+		Panic:     p.redactCredentials(fmt.Sprint(recovered)),
+		Stack:     p.redactCredentials(string(stack)),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		GoVersion: runtime.Version(),
+		Config:    p.View(false),
+	}
+}
+
+// redactCredentials replaces the credentials of the profile found in s.
+func (p *Profile) redactCredentials(s string) string {
+	for _, key := range credentialProperties() {
+		if key == publicAPIKey {
+			continue
+		}
+		if v := p.GetString(key); v != "" {
+			s = strings.ReplaceAll(s, v, redacted)
+		}
+	}
+	return s
+}
+
+// SaveCrashReport writes a crash report to CrashReportsDir.
+func SaveCrashReport(r CrashReport) error { return Default().SaveCrashReport(r) }
+func (p *Profile) SaveCrashReport(r CrashReport) error {
+	if err := p.fs.MkdirAll(p.CrashReportsDir(), defaultPermissions); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return afero.WriteFile(p.fs, p.crashReportFilename(r.ID), b, configPerm)
+}
+
+func (p *Profile) crashReportFilename(id string) string {
+	return filepath.Join(p.CrashReportsDir(), id+".json")
+}
+
+// ListCrashReports returns the saved crash reports, oldest first. Unreadable reports are skipped.
+func ListCrashReports() ([]CrashReport, error) { return Default().ListCrashReports() }
+func (p *Profile) ListCrashReports() ([]CrashReport, error) {
+	entries, err := afero.ReadDir(p.fs, p.CrashReportsDir())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var reports []CrashReport
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		b, err := afero.ReadFile(p.fs, filepath.Join(p.CrashReportsDir(), e.Name()))
+		if err != nil {
+			continue
+		}
+		var r CrashReport
+		if json.Unmarshal(b, &r) != nil || r.ID == "" {
+			continue
+		}
+		reports = append(reports, r)
+	}
+	sort.SliceStable(reports, func(i, j int) bool { return reports[i].Time.Before(reports[j].Time) })
+	return reports, nil
+}
+
+// DeleteCrashReports deletes the crash reports with the given IDs, or all of them when no ID is given.
+func DeleteCrashReports(ids ...string) error { return Default().DeleteCrashReports(ids...) }
+func (p *Profile) DeleteCrashReports(ids ...string) error {
+	if len(ids) == 0 {
+		err := p.fs.RemoveAll(p.CrashReportsDir())
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	for _, id := range ids {
+		if strings.ContainsAny(id, `/\`) {
+			return fmt.Errorf("%w: invalid crash report ID %q", fs.ErrInvalid, id)
+		}
+		if err := p.fs.Remove(p.crashReportFilename(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
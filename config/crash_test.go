@@ -0,0 +1,95 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_CrashReports(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  public_api_key = \"public\"\n  private_api_key = \"private-secret\"\n")
+	assert.False(t, p.CrashReports(), "crash reports are opt-in")
+	p.SetCrashReports(true)
+	assert.True(t, p.CrashReports())
+
+	reports, err := p.ListCrashReports()
+	require.NoError(t, err)
+	assert.Empty(t, reports)
+
+	first := p.NewCrashReport("can't use private-secret", []byte("goroutine 1 [running]:\nmain(private-secret)"), "1.2.3", "clusters list")
+	assert.Equal(t, "can't use redacted", first.Panic)
+	assert.NotContains(t, first.Stack, "private-secret")
+	assert.Equal(t, redacted, first.Config.PrivateAPIKey)
+	assert.Equal(t, "public", first.Config.PublicAPIKey)
+
+	second := first
+	second.ID = "second"
+	second.Time = first.Time.Add(time.Second)
+	require.NoError(t, p.SaveCrashReport(second))
+	require.NoError(t, p.SaveCrashReport(first))
+	require.NoError(t, afero.WriteFile(p.fs, p.CrashReportsDir()+"/invalid.json", []byte("{"), configPerm))
+
+	reports, err = p.ListCrashReports()
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	assert.Equal(t, []string{first.ID, "second"}, []string{reports[0].ID, reports[1].ID}, "oldest first")
+
+	require.Error(t, p.DeleteCrashReports("../config"))
+	require.NoError(t, p.DeleteCrashReports(first.ID, "missing"))
+	reports, err = p.ListCrashReports()
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+
+	require.NoError(t, p.DeleteCrashReports())
+	reports, err = p.ListCrashReports()
+	require.NoError(t, err)
+	assert.Empty(t, reports)
+	require.NoError(t, p.DeleteCrashReports())
+}
+
+// unreadableFs fails to open the file named unreadable.
+type unreadableFs struct {
+	afero.Fs
+	unreadable string
+}
+
+func (f unreadableFs) Open(name string) (afero.File, error) {
+	if name == f.unreadable {
+		return nil, fs.ErrPermission
+	}
+	return f.Fs.Open(name)
+}
+
+func TestProfile_ListCrashReports_unreadable(t *testing.T) {
+	p := newTestProfile(t, "")
+	report := p.NewCrashReport("boom", nil, "1.2.3", "clusters list")
+	require.NoError(t, p.SaveCrashReport(report))
+	require.NoError(t, afero.WriteFile(p.fs, filepath.Join(p.CrashReportsDir(), "locked.json"), []byte("{}"), configPerm))
+	p.fs = unreadableFs{Fs: p.fs, unreadable: filepath.Join(p.CrashReportsDir(), "locked.json")}
+
+	reports, err := p.ListCrashReports()
+	require.NoError(t, err, "unreadable reports are skipped")
+	require.Len(t, reports, 1)
+	assert.Equal(t, report.ID, reports[0].ID)
+}
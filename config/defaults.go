@@ -0,0 +1,79 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"sync"
+	"time"
+)
+
+var defaults = struct {
+	sync.RWMutex
+	byKey map[string]any
+}{byKey: map[string]any{
+	service:                string(CloudService),
+	output:                 string(PlaintextOutput),
+	color:                  string(ColorAuto),
+	timeFormat:             string(RFC3339Time),
+	httpTimeout:            time.Duration(DefaultHTTPTimeout),
+	dialTimeout:            DefaultDialTimeout,
+	responseHeaderTimeout:  DefaultResponseHeaderTimeout,
+	idleConnTimeout:        DefaultIdleConnTimeout,
+	tcpKeepAlive:           DefaultKeepAlive,
+	maxIdleConnsPerHost:    DefaultMaxIdleConnsPerHost,
+	circuitBreakerCooldown: DefaultCircuitBreakerCooldown,
+	telemetrySampleRate:    DefaultTelemetrySampleRate,
+	telemetryBatchSize:     DefaultTelemetryBatchSize,
+	telemetryFlushInterval: DefaultTelemetryFlushInterval,
+	telemetrySpoolMaxSize:  DefaultTelemetrySpoolMaxSize,
+	telemetrySpoolMaxAge:   DefaultTelemetrySpoolMaxAge,
+}}
+
+// RegisterDefault sets the value Get returns for a setting that isn't set anywhere, nil removes it.
+// Defaults of output, service and the timeouts are registered by this package.
+//
+// Migration: Get and the accessors used to return an empty value for these settings when unset,
+// Service now returns cloud and Output plaintext. Callers that compared them to "" to detect an unset
+// setting should use IsDefault instead.
+func RegisterDefault(key string, value any) {
+	defaults.Lock()
+	defer defaults.Unlock()
+	if value == nil {
+		delete(defaults.byKey, key)
+		return
+	}
+	defaults.byKey[key] = value
+}
+
+// DefaultValue returns the registered default of a setting, see RegisterDefault.
+func DefaultValue(key string) (any, bool) {
+	defaults.RLock()
+	defer defaults.RUnlock()
+	v, ok := defaults.byKey[key]
+	return v, ok
+}
+
+// IsDefault returns true when a setting isn't set in the profile, its parents, environment variables or flags,
+// so Get returns its default value, UIs can show it as "(default)".
+func IsDefault(key string) bool { return Default().IsDefault(key) }
+func (p *Profile) IsDefault(key string) bool {
+	return p.configured(key) == nil
+}
+
+// configured returns a setting like Get without its default value, nil when not set.
+func (p *Profile) configured(key string) any {
+	p.ensureLoaded()
+	return p.resolveSecret(key, p.configuredValue(p.currentOrg(key), key))
+}
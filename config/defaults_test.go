@@ -0,0 +1,57 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_IsDefault(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  output = \"json\"\n")
+
+	assert.False(t, p.IsDefault(output))
+	assert.True(t, p.IsDefault(service))
+	assert.Equal(t, CloudService, p.Service())
+	assert.Equal(t, SourceDefault, p.Source(service))
+	assert.Equal(t, "30s", p.GetString(dialTimeout))
+	assert.Equal(t, DefaultDialTimeout, p.DialTimeout())
+	assert.True(t, p.IsDefault(projectID))
+	assert.Nil(t, p.Get(projectID), "settings without a default stay unset")
+
+	p.SetDialTimeout(time.Second)
+	assert.False(t, p.IsDefault(dialTimeout))
+}
+
+func TestRegisterDefault(t *testing.T) {
+	RegisterDefault(pager, "less")
+	t.Cleanup(func() { RegisterDefault(pager, nil) })
+
+	p := newTestProfile(t, "")
+	assert.Equal(t, "less", p.Pager())
+	assert.True(t, p.IsDefault(pager))
+	v, ok := DefaultValue(pager)
+	require.True(t, ok)
+	assert.Equal(t, "less", v)
+
+	RegisterDefault(pager, nil)
+	_, ok = DefaultValue(pager)
+	assert.False(t, ok)
+}
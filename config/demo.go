@@ -0,0 +1,114 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// demoWildcard is a directory name of a fixture path matching any path segment, like the ID of a project.
+const demoWildcard = "_"
+
+// DemoFixtures get the directory HttpClient serves fixtures from instead of calling the API, see SetDemoFixtures.
+func DemoFixtures() string { return Default().DemoFixtures() }
+func (p *Profile) DemoFixtures() string {
+	return p.GetString(demoFixtures)
+}
+
+// SetDemoFixtures sets a directory HttpClient serves the responses from, for demos and trainings with realistic fake data
+// and without credentials. The response to a request is the file named after its method and path with a .json suffix,
+// like GET/api/atlas/v2/groups/_/clusters.json for GET /api/atlas/v2/groups/{groupId}/clusters, where a _ directory
+// matches any path segment. Queries are ignored, requests without a fixture get a 404 Not Found Atlas error.
+// An empty value disables the demo mode.
+func SetDemoFixtures(v string) { Default().SetDemoFixtures(v) }
+func (p *Profile) SetDemoFixtures(v string) {
+	p.Set(demoFixtures, v)
+}
+
+// demoTransport answers the requests with the fixtures of a directory, it's the transport of HttpClient in demo mode.
+type demoTransport struct {
+	dir string
+	fs  afero.Fs
+}
+
+func (t *demoTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	segments := strings.Split(strings.Trim(path.Clean("/"+req.URL.Path), "/"), "/")
+	fixture, err := t.find(filepath.Join(t.dir, req.Method), segments)
+	if errors.Is(err, fs.ErrNotExist) {
+		return demoResponse(req, http.StatusNotFound, map[string]any{
+			"detail":     fmt.Sprintf("No demo fixture for %s %s.", req.Method, req.URL.Path),
+			"error":      http.StatusNotFound,
+			"errorCode":  "RESOURCE_NOT_FOUND",
+			"parameters": []any{},
+			"reason":     http.StatusText(http.StatusNotFound),
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return demoResponse(req, http.StatusOK, json.RawMessage(fixture))
+}
+
+// find reads the fixture for the path segments under dir, exact names take precedence over wildcards.
+func (t *demoTransport) find(dir string, segments []string) ([]byte, error) {
+	if len(segments) == 1 {
+		for _, name := range []string{segments[0], demoWildcard} {
+			b, err := afero.ReadFile(t.fs, filepath.Join(dir, name+".json"))
+			if !errors.Is(err, fs.ErrNotExist) {
+				return b, err
+			}
+		}
+		return nil, fs.ErrNotExist
+	}
+	for _, name := range []string{segments[0], demoWildcard} {
+		b, err := t.find(filepath.Join(dir, name), segments[1:])
+		if !errors.Is(err, fs.ErrNotExist) {
+			return b, err
+		}
+	}
+	return nil, fs.ErrNotExist
+}
+
+func demoResponse(req *http.Request, status int, body any) (*http.Response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid demo fixture for %s %s: %w", req.Method, req.URL.Path, err)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(b)),
+		ContentLength: int64(len(b)),
+		Request:       req,
+	}, nil
+}
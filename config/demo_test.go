@@ -0,0 +1,76 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_DemoFixtures(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  demo_fixtures = \"/fixtures\"\n")
+	require.Equal(t, "/fixtures", p.DemoFixtures())
+	require.NoError(t, afero.WriteFile(p.fs, "/fixtures/GET/api/atlas/v2/groups/_/clusters.json", []byte(`{"results": [{"name": "demo"}]}`), 0600))
+	require.NoError(t, afero.WriteFile(p.fs, "/fixtures/GET/api/atlas/v2/groups/special/clusters.json", []byte(`{"results": []}`), 0600))
+	require.NoError(t, afero.WriteFile(p.fs, "/fixtures/POST/api/atlas/v2/groups.json", []byte(`{"id": "1"}`), 0600))
+	require.NoError(t, afero.WriteFile(p.fs, "/fixtures/GET/api/atlas/v2/invalid.json", []byte(`{`), 0600))
+	client := p.HttpClient()
+
+	get := func(method, url string) (int, string) {
+		t.Helper()
+		req, err := http.NewRequest(method, url, strings.NewReader("{}"))
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		return resp.StatusCode, string(b)
+	}
+
+	status, body := get(http.MethodGet, "https://cloud.mongodb.com/api/atlas/v2/groups/5e2211c17a3e5a48f5497de4/clusters?pageNum=2")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"results": [{"name": "demo"}]}`, body)
+
+	status, body = get(http.MethodGet, "https://cloud.mongodb.com/api/atlas/v2/groups/special/clusters")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"results": []}`, body, "exact names take precedence over wildcards")
+
+	status, body = get(http.MethodPost, "https://cloud.mongodb.com/api/atlas/v2/groups")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"id": "1"}`, body)
+
+	status, body = get(http.MethodDelete, "https://cloud.mongodb.com/api/atlas/v2/groups/x")
+	assert.Equal(t, http.StatusNotFound, status)
+	var atlasErr map[string]any
+	require.NoError(t, json.Unmarshal([]byte(body), &atlasErr))
+	assert.Equal(t, "RESOURCE_NOT_FOUND", atlasErr["errorCode"])
+
+	status, _ = get(http.MethodGet, "https://cloud.mongodb.com/api/atlas/v2/groups/../../../../fixtures/POST/api/atlas/v2/groups")
+	assert.Equal(t, http.StatusNotFound, status, "paths can't leave the method directory")
+
+	_, err := client.Get("https://cloud.mongodb.com/api/atlas/v2/invalid")
+	require.Error(t, err)
+}
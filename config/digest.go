@@ -0,0 +1,300 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"crypto/md5" //nolint:gosec // required by the digest authentication spec
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mongodb-forks/digest"
+)
+
+var ErrUnsupportedDigestChallenge = errors.New("unsupported digest challenge")
+
+// digestChallenge is the WWW-Authenticate challenge of a host, reused until the server rejects its nonce.
+type digestChallenge struct {
+	realm      string
+	nonce      string
+	opaque     string
+	algorithm  string
+	qop        string
+	nonceCount int // nonceCount is the nc of the last request sent with the nonce
+}
+
+// digestTransport authenticates requests with HTTP digest authentication.
+// The first request to a host is authenticated by digest.Transport and its challenge is cached,
+// the next requests reuse the nonce with an increasing nonce count, so only the first request,
+// and the first one after the server expires the nonce, need an extra round trip.
+type digestTransport struct {
+	username string
+	password string
+	base     http.RoundTripper
+	fips     bool // fips rejects MD5 challenges, see FIPSMode
+
+	mu         sync.Mutex
+	challenges map[string]*digestChallenge
+}
+
+func newDigestTransport(username, password string, base http.RoundTripper) *digestTransport {
+	return &digestTransport{
+		username:   username,
+		password:   password,
+		base:       base,
+		challenges: map[string]*digestChallenge{},
+	}
+}
+
+func (t *digestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, err := replayable(req)
+	if err != nil {
+		return nil, err
+	}
+	if t.challenge(req.URL.Host) == nil {
+		return t.negotiate(req)
+	}
+
+	resp, err := t.send(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	// the nonce expired, use the challenge of the 401
+	if !t.record(req.URL.Host, resp, 0) {
+		return resp, nil
+	}
+	drain(resp)
+	return t.send(req)
+}
+
+func (t *digestTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
+
+// negotiate authenticates a request to a host without a cached challenge with digest.Transport.
+func (t *digestTransport) negotiate(req *http.Request) (*http.Response, error) {
+	d := &digest.Transport{
+		Username:  t.username,
+		Password:  t.password,
+		Transport: &challengeTransport{digest: t},
+	}
+	return d.RoundTrip(req)
+}
+
+// challengeTransport is the transport of digest.Transport, it caches the challenge of the host.
+type challengeTransport struct {
+	digest *digestTransport
+}
+
+func (t *challengeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.digest.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || req.Header.Get("Authorization") != "" {
+		return resp, err
+	}
+	// digest.Transport answers the challenge with nc 1
+	if t.digest.record(req.URL.Host, resp, 1) {
+		if err := t.digest.checkAlgorithm(t.digest.challenge(req.URL.Host)); err != nil {
+			drain(resp)
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// send sends a request authenticated with the cached challenge of its host and the next nonce count.
+func (t *digestTransport) send(req *http.Request) (*http.Response, error) {
+	c, _ := t.nextNonce(req.URL.Host)
+	authorization, err := t.authorization(req, &c)
+	if err != nil {
+		return nil, err
+	}
+	attempt, err := cloneWithBody(req)
+	if err != nil {
+		return nil, err
+	}
+	attempt.Header.Set("Authorization", authorization)
+	return t.base.RoundTrip(attempt)
+}
+
+// record caches the digest challenge of a 401 response, nonceCount is the last nc already sent with it.
+// It returns false when the response has no digest challenge.
+func (t *digestTransport) record(host string, resp *http.Response, nonceCount int) bool {
+	c, err := parseDigestChallenge(resp.Header.Get("WWW-Authenticate"))
+	if err != nil {
+		return false
+	}
+	c.nonceCount = nonceCount
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.challenges[host] = c
+	return true
+}
+
+func (t *digestTransport) challenge(host string) *digestChallenge {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.challenges[host]
+}
+
+// nextNonce increments the nonce count of the challenge of a host and returns a copy of it.
+func (t *digestTransport) nextNonce(host string) (digestChallenge, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c, ok := t.challenges[host]
+	if !ok {
+		return digestChallenge{}, false
+	}
+	c.nonceCount++
+	return *c, true
+}
+
+// checkAlgorithm rejects the algorithms the profile doesn't allow, only SHA-256 in FIPS mode.
+func (t *digestTransport) checkAlgorithm(c *digestChallenge) error {
+	if t.fips && c.algorithm != digest.AlgSha256 {
+		return fmt.Errorf("%w: algorithm %s is not allowed in FIPS mode", ErrUnsupportedDigestChallenge, c.algorithm)
+	}
+	return nil
+}
+
+// authorization returns the Authorization header of a request, computed like digest.Transport does.
+func (t *digestTransport) authorization(req *http.Request, c *digestChallenge) (string, error) {
+	if err := t.checkAlgorithm(c); err != nil {
+		return "", err
+	}
+	var newHash func() hash.Hash
+	switch c.algorithm {
+	case digest.AlgMD5:
+		newHash = md5.New
+	case digest.AlgSha256:
+		newHash = sha256.New
+	default:
+		return "", fmt.Errorf("%w: algorithm %q", ErrUnsupportedDigestChallenge, c.algorithm)
+	}
+	if c.qop != "" && c.qop != digest.MsgAuth {
+		return "", fmt.Errorf("%w: qop %q", ErrUnsupportedDigestChallenge, c.qop)
+	}
+
+	h := func(s string) string {
+		hf := newHash()
+		_, _ = io.WriteString(hf, s)
+		return hex.EncodeToString(hf.Sum(nil))
+	}
+	uri := req.URL.RequestURI()
+	ha1 := h(t.username + ":" + c.realm + ":" + t.password)
+	ha2 := h(req.Method + ":" + uri)
+
+	params := []string{
+		fmt.Sprintf("username=%q", t.username),
+		fmt.Sprintf("realm=%q", c.realm),
+		fmt.Sprintf("nonce=%q", c.nonce),
+		fmt.Sprintf("uri=%q", uri),
+	}
+	var cnonce string
+	if c.qop == "" {
+		params = append(params, fmt.Sprintf("response=%q", h(ha1+":"+c.nonce+":"+ha2)))
+	} else {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		cnonce = hex.EncodeToString(b)
+		params = append(params, fmt.Sprintf("response=%q", h(fmt.Sprintf("%s:%s:%08x:%s:%s:%s", ha1, c.nonce, c.nonceCount, cnonce, c.qop, ha2))))
+	}
+	params = append(params, fmt.Sprintf("algorithm=%q", c.algorithm))
+	if c.opaque != "" {
+		params = append(params, fmt.Sprintf("opaque=%q", c.opaque))
+	}
+	if c.qop != "" {
+		params = append(params, "qop="+c.qop, fmt.Sprintf("nc=%08x", c.nonceCount), fmt.Sprintf("cnonce=%q", cnonce))
+	}
+	return "Digest " + strings.Join(params, ", "), nil
+}
+
+// parseDigestChallenge parses a WWW-Authenticate header like: Digest realm="x", nonce="y", qop="auth".
+// It splits the parameters like digest.Transport does, so both read the same challenge.
+func parseDigestChallenge(header string) (*digestChallenge, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(header), "Digest ")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedDigestChallenge, header)
+	}
+	c := &digestChallenge{algorithm: digest.AlgMD5}
+	for _, param := range strings.Split(strings.TrimSpace(rest), ", ") {
+		key, value, _ := strings.Cut(param, "=")
+		value = strings.Trim(value, `"`)
+		switch key {
+		case "realm":
+			c.realm = value
+		case "nonce":
+			c.nonce = value
+		case "opaque":
+			c.opaque = value
+		case "algorithm":
+			c.algorithm = value
+		case "qop":
+			c.qop = value
+		}
+	}
+	if c.nonce == "" {
+		return nil, fmt.Errorf("%w: missing nonce", ErrUnsupportedDigestChallenge)
+	}
+	return c, nil
+}
+
+// replayable returns a request whose body can be read several times.
+func replayable(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return req, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	r.Body, _ = r.GetBody()
+	return r, nil
+}
+
+// cloneWithBody returns a copy of a replayable request with a fresh body, safe to modify and send.
+func cloneWithBody(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+// drain reads and closes a response that won't be used so the connection can be reused.
+func drain(resp *http.Response) {
+	const maxDrain = 2 << 10
+	_, _ = io.CopyN(io.Discard, resp.Body, maxDrain)
+	resp.Body.Close()
+}
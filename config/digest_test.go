@@ -0,0 +1,152 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"crypto/md5" //nolint:gosec // required by the digest authentication spec
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// digestServer is a minimal digest authentication server, it accepts the current nonce only,
+// with a nonce count greater than the last one like RFC 7616 recommends.
+type digestServer struct {
+	mu         sync.Mutex
+	nonce      int
+	nonceCount int64
+	requests   int
+	bodies     []string
+}
+
+func md5Hex(s string) string {
+	h := md5.Sum([]byte(s)) //nolint:gosec // required by the digest authentication spec
+	return hex.EncodeToString(h[:])
+}
+
+func (s *digestServer) expireNonce() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nonce++
+	s.nonceCount = 0
+}
+
+func (s *digestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	nonce := fmt.Sprintf("nonce-%d", s.nonce)
+
+	params := map[string]string{}
+	if rest, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Digest "); ok {
+		for _, kv := range strings.Split(rest, ", ") {
+			k, v, _ := strings.Cut(kv, "=")
+			params[k] = strings.Trim(v, `"`)
+		}
+	}
+
+	ha1 := md5Hex("user:realm:pass")
+	ha2 := md5Hex(r.Method + ":" + r.URL.RequestURI())
+	expected := md5Hex(strings.Join([]string{ha1, params["nonce"], params["nc"], params["cnonce"], "auth", ha2}, ":"))
+	nc, _ := strconv.ParseInt(params["nc"], 16, 64)
+	if params["response"] != expected || params["nonce"] != nonce || nc <= s.nonceCount {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Digest realm="realm", nonce=%q, qop="auth", stale=%t`, nonce, params["nonce"] != ""))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	s.nonceCount = nc
+	body, _ := io.ReadAll(r.Body)
+	s.bodies = append(s.bodies, string(body))
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *digestServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func Test_digestTransport(t *testing.T) {
+	s := &digestServer{}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	client := &http.Client{Transport: newDigestTransport("user", "pass", http.DefaultTransport)}
+	post := func() *http.Response {
+		t.Helper()
+		resp, err := client.Post(ts.URL+"/api/groups?pretty=true", "text/plain", strings.NewReader("body"))
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	t.Run("negotiates the first request", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, post().StatusCode)
+		assert.Equal(t, 2, s.count())
+	})
+	t.Run("reuses the nonce", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, post().StatusCode)
+		assert.Equal(t, http.StatusOK, post().StatusCode)
+		assert.Equal(t, 4, s.count())
+	})
+	t.Run("renegotiates an expired nonce", func(t *testing.T) {
+		s.expireNonce()
+		assert.Equal(t, http.StatusOK, post().StatusCode)
+		assert.Equal(t, 6, s.count())
+		assert.Equal(t, http.StatusOK, post().StatusCode)
+		assert.Equal(t, 7, s.count())
+	})
+	assert.Equal(t, []string{"body", "body", "body", "body", "body"}, s.bodies)
+}
+
+func Test_digestTransport_wrongCredentials(t *testing.T) {
+	s := &digestServer{}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	client := &http.Client{Transport: newDigestTransport("user", "wrong", http.DefaultTransport)}
+	resp, err := client.Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, 2, s.count())
+}
+
+func Test_parseDigestChallenge(t *testing.T) {
+	c, err := parseDigestChallenge(`Digest realm="MMS Public API", domain="", nonce="abc", algorithm="SHA-256", qop="auth", stale=false`)
+	require.NoError(t, err)
+	assert.Equal(t, &digestChallenge{realm: "MMS Public API", nonce: "abc", algorithm: "SHA-256", qop: "auth"}, c)
+
+	c, err = parseDigestChallenge(`Digest realm="MMS Public API", nonce="abc"`)
+	require.NoError(t, err)
+	assert.Equal(t, "MD5", c.algorithm, "MD5 is the default")
+
+	_, err = parseDigestChallenge(`Basic realm="x"`)
+	require.ErrorIs(t, err, ErrUnsupportedDigestChallenge)
+	_, err = parseDigestChallenge(`Digest realm="x"`)
+	require.ErrorIs(t, err, ErrUnsupportedDigestChallenge)
+}
@@ -0,0 +1,83 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// DryRunHeader is set on the responses synthesized in dry run mode.
+const DryRunHeader = "X-Dry-Run"
+
+// DryRun get whether HttpClient only pretends to send the requests changing something, see SetDryRun.
+func DryRun() bool { return Default().DryRun() }
+func (p *Profile) DryRun() bool {
+	return p.GetBool(dryRun)
+}
+
+// SetDryRun sets whether HttpClient only pretends to send the requests changing something.
+// In dry run mode POST, PUT, PATCH and DELETE requests are logged instead of being sent and get an empty
+// 200 OK JSON response with the DryRunHeader set, read requests are sent as usual.
+// Bind it to a --dry-run flag with BindFlags to support it in every command.
+func SetDryRun(v bool) { Default().SetDryRun(v) }
+func (p *Profile) SetDryRun(v bool) {
+	p.Set(dryRun, v)
+}
+
+func (p *Profile) dryRunTransport(base http.RoundTripper) http.RoundTripper {
+	if !p.DryRun() {
+		return base
+	}
+	return &dryRunTransport{base: base, log: p.Logger()}
+}
+
+type dryRunTransport struct {
+	base http.RoundTripper
+	log  *slog.Logger
+}
+
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isMutation(req.Method) {
+		return t.base.RoundTrip(req)
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	t.log.Info("dry run, request not sent",
+		"method", req.Method, "url", req.URL.Redacted(), "content_type", req.Header.Get("Content-Type"), "content_length", req.ContentLength)
+
+	const body = "{}"
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set(DryRunHeader, "true")
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *dryRunTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_DryRun(t *testing.T) {
+	var sent []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Method)
+		_, _ = w.Write([]byte(`{"name": "c1"}`))
+	}))
+	defer s.Close()
+
+	p := newTestProfile(t, "")
+	p.SetAccessToken("secret-token")
+	p.SetDryRun(true)
+	p.SetRecordActivity(true)
+	l, logs := testLogger()
+	p.SetLogger(l)
+	client := p.HttpClient()
+
+	resp, err := client.Get(s.URL + "/api/clusters/c1")
+	require.NoError(t, err)
+	b, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.JSONEq(t, `{"name": "c1"}`, string(b), "reads are sent")
+
+	resp, err = client.Post(s.URL+"/api/clusters", "application/json", strings.NewReader(`{"name": "c2"}`))
+	require.NoError(t, err)
+	b, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "true", resp.Header.Get(DryRunHeader))
+	assert.Equal(t, "{}", string(b))
+
+	assert.Equal(t, []string{http.MethodGet}, sent)
+	assert.Contains(t, logs.String(), "method=POST")
+	assert.Contains(t, logs.String(), "/api/clusters")
+	assert.NotContains(t, logs.String(), "secret-token")
+	entries, err := p.ReadActivity("")
+	require.NoError(t, err)
+	assert.Empty(t, entries, "nothing changed so nothing is recorded")
+}
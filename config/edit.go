@@ -0,0 +1,210 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"github.com/spf13/afero"
+)
+
+const (
+	VisualEnv = "VISUAL"
+	EditorEnv = "EDITOR"
+)
+
+var ErrInvalidConfig = errors.New("invalid config")
+
+// ConfigEditError is returned by EditConfig when the edited config is not valid,
+// the edits are kept in Path so they aren't lost.
+type ConfigEditError struct {
+	Path string
+	Err  error
+}
+
+func (e *ConfigEditError) Error() string {
+	return fmt.Sprintf("%v, the config file was not changed and your edits are in %s", e.Err, e.Path)
+}
+
+func (e *ConfigEditError) Unwrap() error {
+	return e.Err
+}
+
+// runEditor runs the editor attached to the terminal, it's replaced in tests.
+var runEditor = func(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// EditConfig opens a copy of the config file in an editor and replaces the config file with it once the editor exits,
+// only if it's valid TOML with values of the expected types, otherwise a ConfigEditError is returned.
+// editorCmd can have arguments, like "code --wait", it defaults to $VISUAL, then $EDITOR, then vi or notepad.
+func EditConfig(editorCmd string) error { return Default().EditConfig(editorCmd) }
+func (p *Profile) EditConfig(editorCmd string) error {
+	if p.IsEphemeral() {
+		return ErrEphemeralProfile
+	}
+	if err := p.EnsureLoaded(); err != nil {
+		return err
+	}
+	if p.isFileReadOnly() {
+		return fmt.Errorf("%w: %q", ErrProfileReadOnly, p.Filename())
+	}
+	if err := p.verifyIntegrity(); err != nil {
+		return err
+	}
+	contents, err := p.fileContents()
+	if err != nil {
+		return err
+	}
+
+	// the editor runs outside the process, so the copy is on disk even when p.fs is not
+	f, err := os.CreateTemp("", "config-*.toml")
+	if err != nil {
+		return err
+	}
+	path := f.Name()
+	_, err = f.Write(contents)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return err
+	}
+
+	args := strings.Fields(editorCommand(editorCmd))
+	if err := runEditor(args[0], append(args[1:], path)...); err != nil {
+		_ = os.Remove(path)
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(edited, contents) {
+		return os.Remove(path)
+	}
+	if err := validateConfig(edited); err != nil {
+		return &ConfigEditError{Path: path, Err: err}
+	}
+
+	if err := p.replaceFile(edited); err != nil {
+		return err
+	}
+	_ = os.Remove(path)
+	return p.readConfig()
+}
+
+func editorCommand(editorCmd string) string {
+	for _, cmd := range []string{editorCmd, os.Getenv(VisualEnv), os.Getenv(EditorEnv)} {
+		if strings.TrimSpace(cmd) != "" {
+			return cmd
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// replaceFile writes the config file to a temporary file next to it and renames it,
+// so the config file is either the old or the new one if the process is interrupted.
+func (p *Profile) replaceFile(contents []byte) error {
+	if err := p.fs.MkdirAll(p.configDir, defaultPermissions); err != nil {
+		return err
+	}
+	tmp := p.Filename() + ".tmp"
+	if err := afero.WriteFile(p.fs, tmp, contents, configPerm); err != nil {
+		return err
+	}
+	if err := p.fs.Rename(tmp, p.Filename()); err != nil {
+		_ = p.fs.Remove(tmp)
+		return err
+	}
+	return p.fileWritten()
+}
+
+// settingTypes are the types of the known settings, from the fields of Settings.
+var settingTypes = func() map[string]reflect.Type {
+	st := reflect.TypeOf(Settings{})
+	types := make(map[string]reflect.Type, st.NumField())
+	for i := range st.NumField() {
+		types[st.Field(i).Tag.Get("config")] = st.Field(i).Type
+	}
+	return types
+}()
+
+// validateConfig checks a config file is valid TOML, that the global settings are known
+// and that the known settings have values of the expected type.
+func validateConfig(contents []byte) error {
+	tree, err := toml.LoadBytes(contents)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	for key, value := range tree.ToMap() {
+		settings, isProfile := value.(map[string]any)
+		if !isProfile {
+			if !slices.Contains(Properties(), key) {
+				return fmt.Errorf("%w: unknown setting %q, profiles must be tables", ErrInvalidConfig, key)
+			}
+			settings, key = map[string]any{key: value}, ""
+		}
+		if err := validateSettings(key, settings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateSettings(profile string, settings map[string]any) error {
+	for key, value := range settings {
+		if err := validateSetting(key, value); err != nil {
+			if profile == "" {
+				return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+			}
+			return fmt.Errorf("%w: profile %q: %w", ErrInvalidConfig, profile, err)
+		}
+	}
+	return nil
+}
+
+func validateSetting(key string, value any) error {
+	t, ok := settingTypes[key]
+	if !ok {
+		return nil
+	}
+	if _, err := convertSetting(key, value, t); err != nil {
+		return err
+	}
+	if key == service {
+		_, err := ParseService(fmt.Sprint(value))
+		return err
+	}
+	return nil
+}
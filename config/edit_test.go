@@ -0,0 +1,90 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEditor replaces the editor with a function writing contents to the edited file.
+func fakeEditor(t *testing.T, contents string) *[]string {
+	t.Helper()
+	t.Setenv("TMPDIR", t.TempDir())
+	previous := runEditor
+	t.Cleanup(func() { runEditor = previous })
+
+	var command []string
+	runEditor = func(name string, args ...string) error {
+		command = append([]string{name}, args...)
+		return os.WriteFile(args[len(args)-1], []byte(contents), 0o600)
+	}
+	return &command
+}
+
+func TestProfile_EditConfig(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  output = \"json\"\n")
+	command := fakeEditor(t, "[default]\n  output = \"yaml\"\n  http_timeout = \"1m\"\n")
+
+	require.NoError(t, p.EditConfig("code --wait"))
+	assert.Equal(t, "code", (*command)[0])
+	assert.Equal(t, "--wait", (*command)[1])
+	assert.Equal(t, "yaml", p.Output())
+	assert.Equal(t, "1m0s", p.HTTPTimeout().String())
+	_, err := os.Stat((*command)[2])
+	assert.ErrorIs(t, err, os.ErrNotExist, "the copy is removed")
+}
+
+func TestProfile_EditConfig_invalid(t *testing.T) {
+	for name, contents := range map[string]string{
+		"toml":     "[default\n",
+		"type":     "[default]\n  http_timeout = \"soon\"\n",
+		"service":  "[default]\n  service = \"atlas\"\n",
+		"property": "unknown = 1\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			const original = "[default]\n  output = \"json\"\n"
+			p := newTestProfile(t, original)
+			fakeEditor(t, contents)
+
+			err := p.EditConfig("vi")
+			require.ErrorIs(t, err, ErrInvalidConfig)
+			var editErr *ConfigEditError
+			require.ErrorAs(t, err, &editErr)
+			kept, readErr := os.ReadFile(editErr.Path)
+			require.NoError(t, readErr)
+			assert.Equal(t, contents, string(kept), "the edits are kept")
+
+			b, readErr := p.fileContents()
+			require.NoError(t, readErr)
+			assert.Equal(t, original, string(b))
+			assert.Equal(t, "json", p.Output())
+		})
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv(VisualEnv, "")
+	t.Setenv(EditorEnv, "nano")
+	assert.Equal(t, "code --wait", editorCommand("code --wait"))
+	assert.Equal(t, "nano", editorCommand(""))
+	t.Setenv(VisualEnv, "emacs")
+	assert.Equal(t, "emacs", editorCommand(" "))
+}
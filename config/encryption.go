@@ -0,0 +1,139 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// encryptedSecretPrefix marks credentials encrypted for the current user, like dpapi:AQAAANCMnd8B...
+const encryptedSecretPrefix = "dpapi:"
+
+var ErrSecretEncryptionUnsupported = errors.New("encrypting secrets is only supported on Windows")
+
+// The platform encryption of secrets, scoped to the current user, see encryption_windows.go.
+var (
+	secretEncryptionSupported = platformEncryptionSupported
+	protectData               = platformProtectData
+	unprotectData             = platformUnprotectData
+)
+
+// EncryptSecrets returns true when credentials are encrypted in the config file for the current user.
+func EncryptSecrets() bool { return Default().EncryptSecrets() }
+func (p *Profile) EncryptSecrets() bool {
+	return p.GetBool(encryptSecrets)
+}
+
+// SetEncryptSecrets sets whether credentials are encrypted in the config file, with DPAPI on Windows.
+// Credentials already in the file are encrypted on the next Save.
+func SetEncryptSecrets(v bool) error { return Default().SetEncryptSecrets(v) }
+func (p *Profile) SetEncryptSecrets(v bool) error {
+	if v && !secretEncryptionSupported {
+		return ErrSecretEncryptionUnsupported
+	}
+	p.SetGlobal(encryptSecrets, v)
+	return nil
+}
+
+func isEncryptedSecret(v any) bool {
+	s, ok := v.(string)
+	return ok && strings.HasPrefix(s, encryptedSecretPrefix)
+}
+
+func encryptSecret(value string) (string, error) {
+	b, err := protectData([]byte(value))
+	if err != nil {
+		return "", fmt.Errorf("encrypting secret: %w", err)
+	}
+	return encryptedSecretPrefix + base64.StdEncoding.EncodeToString(b), nil
+}
+
+func decryptSecret(value string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedSecretPrefix))
+	if err != nil {
+		return "", fmt.Errorf("decrypting secret: %w", err)
+	}
+	if b, err = unprotectData(b); err != nil {
+		return "", fmt.Errorf("decrypting secret: %w", err)
+	}
+	return string(b), nil
+}
+
+// revealSecret decrypts an encrypted credential, values that can't be decrypted,
+// like the ones encrypted by another user, are treated as unset.
+func revealSecret(key string, v any) any {
+	if !isEncryptedSecret(v) || !slices.Contains(credentialProperties(), key) {
+		return v
+	}
+	value, err := decryptSecret(v.(string))
+	if err != nil {
+		return nil
+	}
+	return value
+}
+
+// encryptFileSecrets encrypts the plaintext credentials of every profile of the config file.
+func encryptFileSecrets(v *viper.Viper) error {
+	for name, settings := range v.AllSettings() {
+		m, ok := settings.(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, key := range credentialProperties() {
+			value, ok := m[key].(string)
+			if !ok || value == "" || isEncryptedSecret(value) {
+				continue
+			}
+			encrypted, err := encryptSecret(value)
+			if err != nil {
+				return err
+			}
+			v.Set(name+"."+key, encrypted)
+		}
+		if err := encryptIdentities(v, name, m[identities]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encryptIdentities encrypts the plaintext tokens of the identities of a profile, see StoreIdentity.
+func encryptIdentities(v *viper.Viper, profile string, value any) error {
+	list := identityList(value)
+	if len(list) == 0 {
+		return nil
+	}
+	for _, id := range list {
+		for _, key := range []string{AccessTokenField, RefreshTokenField} {
+			value, ok := id[key].(string)
+			if !ok || value == "" || isEncryptedSecret(value) {
+				continue
+			}
+			encrypted, err := encryptSecret(value)
+			if err != nil {
+				return err
+			}
+			id[key] = encrypted
+		}
+	}
+	v.Set(profile+"."+identities, list)
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+var testUserKey = []byte("user:")
+
+// fakeEncryption replaces DPAPI with a reversible encoding for the current user.
+func fakeEncryption(t *testing.T) {
+	t.Helper()
+	supported, protect, unprotect := secretEncryptionSupported, protectData, unprotectData
+	t.Cleanup(func() {
+		secretEncryptionSupported, protectData, unprotectData = supported, protect, unprotect
+	})
+
+	secretEncryptionSupported = true
+	protectData = func(b []byte) ([]byte, error) {
+		return append(slices.Clone(testUserKey), b...), nil
+	}
+	unprotectData = func(b []byte) ([]byte, error) {
+		if !bytes.HasPrefix(b, testUserKey) {
+			return nil, errors.New("encrypted by another user")
+		}
+		return b[len(testUserKey):], nil
+	}
+}
+
+func TestProfile_EncryptSecrets(t *testing.T) {
+	fakeEncryption(t)
+	p := newTestProfile(t, "[default]\n  public_api_key = \"public\"\n  org_id = \"a\"\n")
+
+	require.NoError(t, p.SetEncryptSecrets(true))
+	p.SetPrivateAPIKey("private")
+	require.NoError(t, p.Save())
+
+	b, err := afero.ReadFile(p.fs, p.Filename())
+	require.NoError(t, err)
+	got := string(b)
+	require.NotContains(t, got, "private'")
+	require.NotContains(t, got, "public'")
+	require.Contains(t, got, "org_id = 'a'")
+	require.Contains(t, got, encryptedSecretPrefix)
+
+	require.Equal(t, "private", p.PrivateAPIKey())
+	require.Equal(t, "public", p.PublicAPIKey())
+	require.Equal(t, "a", p.OrgID())
+}
+
+func TestProfile_EncryptedSecretOfAnotherUser(t *testing.T) {
+	fakeEncryption(t)
+	p := newTestProfile(t, "[default]\n  private_api_key = \"dpapi:b3RoZXI6cHJpdmF0ZQ==\"\n")
+	require.Empty(t, p.PrivateAPIKey())
+}
+
+func TestProfile_SetEncryptSecretsUnsupported(t *testing.T) {
+	supported := secretEncryptionSupported
+	t.Cleanup(func() { secretEncryptionSupported = supported })
+	secretEncryptionSupported = false
+
+	p := newTestProfile(t, "")
+	require.ErrorIs(t, p.SetEncryptSecrets(true), ErrSecretEncryptionUnsupported)
+	require.NoError(t, p.SetEncryptSecrets(false))
+}
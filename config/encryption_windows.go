@@ -0,0 +1,55 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package config
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const platformEncryptionSupported = true
+
+// platformProtectData encrypts data with DPAPI, only the current user can decrypt it.
+func platformProtectData(data []byte) ([]byte, error) {
+	return cryptData(data, func(in, out *windows.DataBlob) error {
+		return windows.CryptProtectData(in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, out)
+	})
+}
+
+// platformUnprotectData decrypts data encrypted by platformProtectData.
+func platformUnprotectData(data []byte) ([]byte, error) {
+	return cryptData(data, func(in, out *windows.DataBlob) error {
+		return windows.CryptUnprotectData(in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, out)
+	})
+}
+
+func cryptData(data []byte, crypt func(in, out *windows.DataBlob) error) ([]byte, error) {
+	in := &windows.DataBlob{Size: uint32(len(data))}
+	if len(data) > 0 {
+		in.Data = &data[0]
+	}
+	out := &windows.DataBlob{}
+	if err := crypt(in, out); err != nil {
+		return nil, err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data))) //nolint:errcheck // nothing to do if freeing fails
+
+	result := make([]byte, out.Size)
+	copy(result, unsafe.Slice(out.Data, out.Size))
+	return result, nil
+}
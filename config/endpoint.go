@@ -0,0 +1,210 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	PublicEndpoint  = "public"  // PublicEndpoint the API is reached over the internet, the default
+	PrivateEndpoint = "private" // PrivateEndpoint the API is reached over a private link, see ServiceEndpoints
+
+	regionPlaceholder = "{region}"
+)
+
+var (
+	ErrInvalidRegion               = errors.New("invalid API region")
+	ErrInvalidEndpointType         = errors.New("invalid endpoint type, expected public or private")
+	ErrPrivateEndpointNotSupported = errors.New("the service has no private endpoint")
+	ErrRegionNotSupported          = errors.New("the service has no regional endpoint")
+)
+
+var regionRegex = regexp.MustCompile(`^[a-z0-9]+([-_][a-z0-9]+)*$`)
+
+// ServiceEndpoints are the base URLs of a service other than its default one, {region} is replaced by the API region.
+type ServiceEndpoints struct {
+	Regional string   // Regional is the base URL used when an API region is set, like https://{region}.api.example.com/
+	Private  string   // Private is the base URL of the private link, used when the endpoint type is private
+	Regions  []string // Regions are the valid API regions, any region is accepted when empty
+}
+
+var serviceEndpoints = struct {
+	sync.RWMutex
+	byService map[ServiceType]ServiceEndpoints
+}{byService: map[ServiceType]ServiceEndpoints{}}
+
+// RegisterServiceEndpoints sets the regional and private base URLs of a service,
+// used by HttpBaseURL for profiles with an API region or a private endpoint type.
+func RegisterServiceEndpoints(s ServiceType, e ServiceEndpoints) {
+	serviceEndpoints.Lock()
+	defer serviceEndpoints.Unlock()
+	serviceEndpoints.byService[s] = e
+}
+
+// Endpoints returns the regional and private base URLs registered for the service.
+func (s ServiceType) Endpoints() ServiceEndpoints {
+	serviceEndpoints.RLock()
+	defer serviceEndpoints.RUnlock()
+	return serviceEndpoints.byService[s]
+}
+
+// APIRegion get the region of the API endpoint the profile uses, empty for the default endpoint.
+func APIRegion() string { return Default().APIRegion() }
+func (p *Profile) APIRegion() string {
+	return strings.ToLower(p.GetString(apiRegion))
+}
+
+// SetAPIRegion sets the region of the API endpoint after checking the service accepts it,
+// an endpoint using the region must be registered for the service, see RegisterServiceEndpoints.
+func SetAPIRegion(v string) error { return Default().SetAPIRegion(v) }
+func (p *Profile) SetAPIRegion(v string) error {
+	v = strings.ToLower(v)
+	if err := validateRegion(p.CurrentService(), v); err != nil {
+		return err
+	}
+	p.Set(apiRegion, v)
+	return nil
+}
+
+func validateRegion(s ServiceType, region string) error {
+	if region == "" {
+		return nil
+	}
+	if !regionRegex.MatchString(region) {
+		return fmt.Errorf("%w: %q", ErrInvalidRegion, region)
+	}
+	if e := s.Endpoints(); e.Regional == "" && !strings.Contains(e.Private, regionPlaceholder) {
+		return fmt.Errorf("%w: %q", ErrRegionNotSupported, s)
+	}
+	if regions := s.Endpoints().Regions; len(regions) > 0 && !slices.Contains(regions, region) {
+		return fmt.Errorf("%w: %q, %s supports %s", ErrInvalidRegion, region, s, strings.Join(regions, ", "))
+	}
+	return nil
+}
+
+// EndpointType get whether the profile reaches the API over the internet or a private link, PublicEndpoint by default.
+func EndpointType() string { return Default().EndpointType() }
+func (p *Profile) EndpointType() string {
+	if v := strings.ToLower(p.GetString(endpointType)); v != "" {
+		return v
+	}
+	return PublicEndpoint
+}
+
+// SetEndpointType sets whether the profile reaches the API over the internet or a private link,
+// a private endpoint must be registered for the service, see RegisterServiceEndpoints.
+func SetEndpointType(v string) error { return Default().SetEndpointType(v) }
+func (p *Profile) SetEndpointType(v string) error {
+	v = strings.ToLower(v)
+	switch v {
+	case PublicEndpoint:
+	case PrivateEndpoint:
+		if p.CurrentService().Endpoints().Private == "" {
+			return fmt.Errorf("%w: %q", ErrPrivateEndpointNotSupported, p.CurrentService())
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidEndpointType, v)
+	}
+	p.Set(endpointType, v)
+	return nil
+}
+
+// ValidateEndpoint checks the API region and endpoint type of the profile select a valid base URL for its service.
+func ValidateEndpoint() error { return Default().ValidateEndpoint() }
+func (p *Profile) ValidateEndpoint() error {
+	s := p.CurrentService()
+	if err := validateRegion(s, p.APIRegion()); err != nil {
+		return err
+	}
+	switch p.EndpointType() {
+	case PublicEndpoint:
+	case PrivateEndpoint:
+		if s.Endpoints().Private == "" {
+			return fmt.Errorf("%w: %q", ErrPrivateEndpointNotSupported, s)
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidEndpointType, p.EndpointType())
+	}
+
+	u, ok := p.endpointBaseURL()
+	if !ok {
+		if p.APIRegion() != "" {
+			return fmt.Errorf("%w: %q", ErrRegionNotSupported, s)
+		}
+		return nil
+	}
+	if strings.Contains(u, regionPlaceholder) {
+		return fmt.Errorf("%w: the %s endpoint of %s requires a region", ErrInvalidRegion, p.EndpointType(), s)
+	}
+	if _, err := NormalizeBaseURL(u); err != nil {
+		return err
+	}
+	if s == CloudGovService {
+		return checkCloudGovURL(p.endpointKey(), u)
+	}
+	return nil
+}
+
+// endpointKey returns the setting selecting the endpoint base URL, for errors.
+func (p *Profile) endpointKey() string {
+	if p.EndpointType() == PrivateEndpoint {
+		return endpointType
+	}
+	return apiRegion
+}
+
+// endpointBaseURL returns the base URL selected by the API region and endpoint type, if any.
+// A private endpoint without base URL returns an empty URL rather than falling back to the public one,
+// ValidateEndpoint reports it and HttpClient fails its requests.
+func (p *Profile) endpointBaseURL() (string, bool) {
+	e := p.CurrentService().Endpoints()
+	region := p.APIRegion()
+	template := ""
+	switch {
+	case p.EndpointType() == PrivateEndpoint:
+		template = e.Private
+		if template == "" {
+			return "", true
+		}
+	case region != "" && e.Regional != "":
+		template = e.Regional
+	default:
+		return "", false
+	}
+	if region != "" {
+		template = strings.ReplaceAll(template, regionPlaceholder, region)
+	}
+	return template, true
+}
+
+// invalidEndpointTransport fails every request, it's the transport of HttpClient when ValidateEndpoint fails,
+// so requests aren't sent to an empty or unintended base URL.
+type invalidEndpointTransport struct {
+	err error
+}
+
+func (t invalidEndpointTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, t.err
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func registerTestEndpoints(t *testing.T, s ServiceType, e ServiceEndpoints) {
+	t.Helper()
+	previous := s.Endpoints()
+	RegisterServiceEndpoints(s, e)
+	t.Cleanup(func() { RegisterServiceEndpoints(s, previous) })
+}
+
+func TestProfile_Endpoints(t *testing.T) {
+	registerTestEndpoints(t, CloudService, ServiceEndpoints{
+		Regional: "https://{region}.api.example.com/",
+		Private:  "https://{region}.private.example.com/",
+		Regions:  []string{"eu-west-1", "us-east-1"},
+	})
+	p := NewEphemeralProfile(map[string]any{"service": CloudService})
+	assert.Equal(t, "https://cloud.mongodb.com/", p.HttpBaseURL())
+	assert.Equal(t, PublicEndpoint, p.EndpointType())
+
+	require.ErrorIs(t, p.SetAPIRegion("ap-south-1"), ErrInvalidRegion)
+	require.ErrorIs(t, p.SetAPIRegion("EU WEST"), ErrInvalidRegion)
+	require.NoError(t, p.SetAPIRegion("EU-West-1"))
+	assert.Equal(t, "https://eu-west-1.api.example.com/", p.HttpBaseURL())
+
+	require.ErrorIs(t, p.SetEndpointType("vpn"), ErrInvalidEndpointType)
+	require.NoError(t, p.SetEndpointType(PrivateEndpoint))
+	assert.Equal(t, "https://eu-west-1.private.example.com/", p.HttpBaseURL())
+	require.NoError(t, p.ValidateEndpoint())
+
+	require.NoError(t, p.SetAPIRegion(""))
+	assert.Empty(t, p.HttpBaseURL(), "a private endpoint doesn't fall back to the public one")
+	require.ErrorIs(t, p.ValidateEndpoint(), ErrInvalidRegion)
+	_, err := p.HttpClient().Get("https://cloud.mongodb.com/api/atlas/v2")
+	require.ErrorIs(t, err, ErrInvalidRegion, "requests fail rather than going to an unintended endpoint")
+
+	p.SetOpsManagerURL("https://om.example.com/")
+	assert.Equal(t, "https://om.example.com/", p.HttpBaseURL(), "an explicit base URL takes precedence")
+}
+
+func TestProfile_EndpointsUnsupported(t *testing.T) {
+	p := NewEphemeralProfile(map[string]any{"service": OpsManagerService})
+	require.ErrorIs(t, p.SetEndpointType(PrivateEndpoint), ErrPrivateEndpointNotSupported)
+
+	p.Set(endpointType, PrivateEndpoint)
+	require.ErrorIs(t, p.ValidateEndpoint(), ErrPrivateEndpointNotSupported)
+}
+
+func TestProfile_RegionUnsupported(t *testing.T) {
+	p := NewEphemeralProfile(map[string]any{"service": OpsManagerService})
+	require.ErrorIs(t, p.SetAPIRegion("eu-west-1"), ErrRegionNotSupported)
+	assert.Empty(t, p.APIRegion())
+
+	p.Set(apiRegion, "eu-west-1")
+	require.ErrorIs(t, p.ValidateEndpoint(), ErrRegionNotSupported)
+	_, err := p.HttpClient().Get("https://om.example.com/api/public/v1.0")
+	require.ErrorIs(t, err, ErrRegionNotSupported)
+}
+
+func TestProfile_EndpointsCloudGov(t *testing.T) {
+	registerTestEndpoints(t, CloudGovService, ServiceEndpoints{Regional: "https://{region}.api.example.com/"})
+	p := NewEphemeralProfile(map[string]any{"service": CloudGovService})
+	require.NoError(t, p.SetAPIRegion("us-gov-west-1"))
+	require.ErrorIs(t, p.ValidateEndpoint(), ErrCloudGovMismatch)
+}
@@ -0,0 +1,206 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mongodb/atlas-cli-core/environment"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+// SetEnvPrefix sets the prefix of the environment variables read by LoadAtlasCLIConfig, AtlasCLIEnvPrefix by default.
+// It must be called before loading the config. With a custom prefix MongoCLIEnvPrefix variables are not read.
+func SetEnvPrefix(prefix string) { Default().SetEnvPrefix(prefix) }
+func (p *Profile) SetEnvPrefix(prefix string) {
+	p.customEnvPrefix = strings.TrimSuffix(prefix, "_")
+}
+
+// EnvPrefix returns the prefix of the environment variables read by the profile, empty if they are not read.
+func EnvPrefix() string { return Default().EnvPrefix() }
+func (p *Profile) EnvPrefix() string {
+	return p.envPrefix
+}
+
+// RegisterEnvAlias makes a setting also read from the given environment variables, without prefix.
+// The prefixed variable takes precedence, followed by the aliases in the given order.
+func RegisterEnvAlias(key string, names ...string) { Default().RegisterEnvAlias(key, names...) }
+func (p *Profile) RegisterEnvAlias(key string, names ...string) {
+	key = strings.ToLower(key)
+	if p.envAliases == nil {
+		p.envAliases = map[string][]string{}
+	}
+	p.envAliases[key] = append(p.envAliases[key], names...)
+
+	if p.envPrefix != "" {
+		p.bindEnvAliases()
+	}
+}
+
+// EnvVarNames returns the environment variables a setting is read from, in order of precedence.
+func EnvVarNames(key string) []string { return Default().EnvVarNames(key) }
+func (p *Profile) EnvVarNames(key string) []string {
+	if p.envPrefix == "" {
+		return nil
+	}
+	key = strings.ToLower(key)
+	return append([]string{p.envVarName(key)}, p.envAliases[key]...)
+}
+
+func (p *Profile) bindEnvAliases() {
+	for key, names := range p.envAliases {
+		// viper applies the env key replacer to bound names, so the prefixed name is passed before replacement
+		input := append([]string{key, strings.ToUpper(p.envPrefix + "_" + key)}, names...)
+		_ = viper.BindEnv(input...)
+	}
+}
+
+const envFileSuffix = "_FILE"
+
+// EnvVar describes an environment variable read by the CLI.
+type EnvVar struct {
+	Name        string
+	Key         string // Key is the setting the variable overrides, empty for variables that aren't settings
+	File        bool   // File is true when the variable holds the path of a file with the value, like a mounted secret
+	Legacy      bool   // Legacy is true for the MongoCLI variables, read instead of the AtlasCLI ones when any of them is set
+	Description string // Description of the variables that aren't settings
+}
+
+// fileEnvProperties returns the settings that can be read from the file named by their variable with a _FILE suffix.
+func fileEnvProperties() []string {
+	return credentialProperties()
+}
+
+// EnvVars returns the environment variables read by the CLI, sorted by setting.
+func EnvVars() []EnvVar { return Default().EnvVars() }
+func (p *Profile) EnvVars() []EnvVar {
+	prefix := p.customEnvPrefix
+	if prefix == "" {
+		prefix = AtlasCLIEnvPrefix
+	}
+
+	var vars []EnvVar
+	for _, key := range Properties() {
+		name := strings.ToUpper(prefix + "_" + key)
+		vars = append(vars, EnvVar{Name: name, Key: key})
+		for _, alias := range p.envAliases[key] {
+			vars = append(vars, EnvVar{Name: alias, Key: key})
+		}
+		isFile := slices.Contains(fileEnvProperties(), key)
+		if isFile {
+			vars = append(vars, EnvVar{Name: name + envFileSuffix, Key: key, File: true})
+		}
+
+		if p.customEnvPrefix != "" {
+			continue
+		}
+		legacy := strings.Replace(name, AtlasCLIEnvPrefix, MongoCLIEnvPrefix, 1)
+		vars = append(vars, EnvVar{Name: legacy, Key: key, Legacy: true})
+		if isFile {
+			vars = append(vars, EnvVar{Name: legacy + envFileSuffix, Key: key, File: true, Legacy: true})
+		}
+	}
+	sort.SliceStable(vars, func(i, j int) bool { return vars[i].Key < vars[j].Key })
+
+	return append(vars,
+		EnvVar{Name: ConfigPermissionsEnv, Description: "what to do when the config file is accessible by other users: warn, fix or ignore"},
+		EnvVar{Name: DoNotTrackEnv, Description: "disables telemetry when true"},
+		EnvVar{Name: NoColorEnv, Description: "disables colors when set"},
+		EnvVar{Name: PagerEnv, Description: "pager command, used when the pager setting is empty"},
+		EnvVar{Name: environment.NonInteractiveEnv, Description: "disables prompts when true, enables them when false"},
+		EnvVar{Name: environment.ContainerizedEnv, Description: "overrides the detection of containers"},
+		EnvVar{Name: CLIUserTypeEnv, Description: "type of user, for telemetry"},
+		EnvVar{Name: CredentialsDirectoryEnv, Description: "directory with the credentials passed by systemd, in files named like the setting or its variable"},
+		EnvVar{Name: AccessTokenExpiryEnv, Description: "when the access token set in the environment stops being used, in RFC 3339"},
+	)
+}
+
+// isAccessTokenEnvExpired returns true when the access token comes from the environment
+// and AccessTokenExpiryEnv is past or invalid, like the token of a delegated credential that outlived its lifetime.
+func (p *Profile) isAccessTokenEnvExpired() bool {
+	value := os.Getenv(AccessTokenExpiryEnv)
+	if value == "" || !p.isEnvSet(AccessTokenField) {
+		return false
+	}
+	expiry, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		p.warn(Warning{Kind: WarningCredentials, Key: AccessTokenField, Message: fmt.Sprintf("invalid %s %q, the access token isn't used", AccessTokenExpiryEnv, value)})
+		return true
+	}
+	if time.Now().Before(expiry) {
+		return false
+	}
+	p.warn(Warning{Kind: WarningCredentials, Key: AccessTokenField, Message: "the access token expired at " + expiry.Format(time.RFC3339) + ", see " + AccessTokenExpiryEnv})
+	return true
+}
+
+// loadEnvFiles reads the settings whose variable with a _FILE suffix names a file, unless the variable itself is set.
+func (p *Profile) loadEnvFiles() error {
+	for _, key := range fileEnvProperties() {
+		if p.isPlainEnvSet(key) {
+			continue
+		}
+		name := p.envVarName(key) + envFileSuffix
+		path := os.Getenv(name)
+		if path == "" {
+			continue
+		}
+		b, err := afero.ReadFile(p.fs, path)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", name, err)
+		}
+		viper.Set(key, strings.TrimRight(string(b), "\r\n"))
+	}
+	return nil
+}
+
+// loadCredentialsDirectory reads the credentials systemd passes with LoadCredential=, in files named like the setting,
+// private_api_key, or its variable, MONGODB_ATLAS_PRIVATE_API_KEY. Environment variables take precedence.
+func (p *Profile) loadCredentialsDirectory() error {
+	p.credentialFiles = nil
+	dir := os.Getenv(CredentialsDirectoryEnv)
+	if dir == "" {
+		return nil
+	}
+
+	for _, key := range fileEnvProperties() {
+		if p.isEnvSet(key) {
+			continue
+		}
+		for _, name := range []string{key, p.envVarName(key)} {
+			path := filepath.Join(dir, name)
+			b, err := afero.ReadFile(p.fs, path)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("reading credential %s: %w", path, err)
+			}
+			viper.Set(key, strings.TrimRight(string(b), "\r\n"))
+			p.credentialFiles = append(p.credentialFiles, key)
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_SetEnvPrefix(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Setenv("MONGODB_ATLAS_ORG_ID", "atlas")
+	t.Setenv("UNIVERSITY_ORG_ID", "university")
+	t.Setenv("UNIVERSITY_PROJECT", "project")
+	t.Setenv("LEGACY_PROJECT_ID", "legacy")
+
+	p := &Profile{
+		name:      DefaultProfile,
+		configDir: "/atlascli",
+		fs:        afero.NewMemMapFs(),
+	}
+	p.SetEnvPrefix("UNIVERSITY_")
+	p.RegisterEnvAlias(projectID, "UNIVERSITY_PROJECT", "LEGACY_PROJECT_ID")
+	require.NoError(t, p.LoadAtlasCLIConfig(true))
+
+	assert.Equal(t, "UNIVERSITY", p.EnvPrefix())
+	assert.Equal(t, "university", p.OrgID())
+	assert.Equal(t, "project", p.ProjectID())
+	assert.Equal(t, SourceEnv, p.Source(projectID))
+	assert.Equal(t, []string{"UNIVERSITY_PROJECT_ID", "UNIVERSITY_PROJECT", "LEGACY_PROJECT_ID"}, p.EnvVarNames(projectID))
+
+	t.Setenv("UNIVERSITY_PROJECT_ID", "prefixed")
+	assert.Equal(t, "prefixed", p.ProjectID(), "the prefixed variable takes precedence over aliases")
+
+	p.RegisterEnvAlias(output, "UNIVERSITY_FORMAT")
+	t.Setenv("UNIVERSITY_FORMAT", "json")
+	assert.Equal(t, "json", p.Output(), "aliases can be registered after loading")
+}
+
+func TestProfile_EnvVars(t *testing.T) {
+	p := newTestProfile(t, "")
+	vars := p.EnvVars()
+
+	assert.Contains(t, vars, EnvVar{Name: "MONGODB_ATLAS_ORG_ID", Key: orgID})
+	assert.Contains(t, vars, EnvVar{Name: "MCLI_ORG_ID", Key: orgID, Legacy: true})
+	assert.Contains(t, vars, EnvVar{Name: "MONGODB_ATLAS_PRIVATE_API_KEY_FILE", Key: privateAPIKey, File: true})
+	assert.Contains(t, vars, EnvVar{Name: "MCLI_PRIVATE_API_KEY_FILE", Key: privateAPIKey, File: true, Legacy: true})
+	assert.NotContains(t, vars, EnvVar{Name: "MONGODB_ATLAS_ORG_ID_FILE", Key: orgID, File: true})
+	for _, v := range vars {
+		assert.True(t, v.Key != "" || v.Description != "", "%s is documented", v.Name)
+	}
+
+	p.SetEnvPrefix("UNIVERSITY")
+	p.RegisterEnvAlias(orgID, "UNI_ORG")
+	vars = p.EnvVars()
+	assert.Contains(t, vars, EnvVar{Name: "UNIVERSITY_ORG_ID", Key: orgID})
+	assert.Contains(t, vars, EnvVar{Name: "UNI_ORG", Key: orgID})
+	assert.NotContains(t, vars, EnvVar{Name: "MCLI_ORG_ID", Key: orgID, Legacy: true})
+}
+
+func TestProfile_loadEnvFiles(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  private_api_key = \"from-config\"\n")
+	require.NoError(t, afero.WriteFile(p.fs, "/run/secrets/key", []byte("from-file\n"), configPerm))
+	t.Setenv("MONGODB_ATLAS_PRIVATE_API_KEY", "")
+	t.Setenv("MONGODB_ATLAS_PRIVATE_API_KEY_FILE", "/run/secrets/key")
+
+	require.NoError(t, p.load(true, AtlasCLIEnvPrefix))
+	assert.Equal(t, "from-file", p.PrivateAPIKey())
+	assert.Equal(t, SourceEnv, p.Source(privateAPIKey))
+
+	t.Setenv("MONGODB_ATLAS_PRIVATE_API_KEY_FILE", "/run/secrets/missing")
+	require.ErrorContains(t, p.load(true, AtlasCLIEnvPrefix), "MONGODB_ATLAS_PRIVATE_API_KEY_FILE")
+}
+
+func TestProfile_loadCredentialsDirectory(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  private_api_key = \"from-config\"\n  public_api_key = \"public\"\n")
+	require.NoError(t, afero.WriteFile(p.fs, "/run/credentials/atlas.service/private_api_key", []byte("private\n"), configPerm))
+	require.NoError(t, afero.WriteFile(p.fs, "/run/credentials/atlas.service/MONGODB_ATLAS_ACCESS_TOKEN", []byte("token"), configPerm))
+	t.Setenv(CredentialsDirectoryEnv, "/run/credentials/atlas.service")
+	t.Setenv("MONGODB_ATLAS_ACCESS_TOKEN", "")
+	t.Setenv("MONGODB_ATLAS_PRIVATE_API_KEY", "")
+
+	require.NoError(t, p.load(true, AtlasCLIEnvPrefix))
+	assert.Equal(t, "private", p.PrivateAPIKey())
+	assert.Equal(t, SourceCredentials, p.Source(privateAPIKey))
+	assert.Equal(t, "token", p.AccessToken())
+	assert.Equal(t, "public", p.PublicAPIKey())
+
+	t.Setenv("MONGODB_ATLAS_PRIVATE_API_KEY", "from-env")
+	viper.Reset()
+	require.NoError(t, p.load(true, AtlasCLIEnvPrefix))
+	assert.Equal(t, "from-env", p.PrivateAPIKey())
+	assert.Equal(t, SourceEnv, p.Source(privateAPIKey))
+}
+
+func TestProfile_AccessTokenExpiryEnv(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  access_token = \"from-config\"\n")
+	t.Setenv("MONGODB_ATLAS_ACCESS_TOKEN", "")
+	t.Setenv(AccessTokenExpiryEnv, time.Now().Add(-time.Minute).Format(time.RFC3339))
+	require.NoError(t, p.load(true, AtlasCLIEnvPrefix))
+	assert.Equal(t, "from-config", p.AccessToken(), "the expiry only applies to an access token set in the environment")
+
+	t.Setenv("MONGODB_ATLAS_ACCESS_TOKEN", "delegated")
+	assert.Empty(t, p.AccessToken())
+	assert.Equal(t, NotLoggedIn, p.AuthType())
+
+	t.Setenv(AccessTokenExpiryEnv, time.Now().Add(time.Minute).Format(time.RFC3339))
+	assert.Equal(t, "delegated", p.AccessToken())
+
+	t.Setenv(AccessTokenExpiryEnv, "tomorrow")
+	assert.Empty(t, p.AccessToken(), "an invalid expiry doesn't extend the token")
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	DevelopmentEnvironment = "development" // DevelopmentEnvironment destructive operations don't need confirmation
+	StagingEnvironment     = "staging"     // StagingEnvironment destructive operations need to be confirmed
+	ProductionEnvironment  = "production"  // ProductionEnvironment destructive operations need the project name to be typed
+)
+
+var ErrInvalidEnvironment = errors.New("invalid environment, expected one of development, staging or production")
+
+// ConfirmationLevel is the confirmation CLIs should require before destructive operations, like deleting a cluster.
+type ConfirmationLevel int
+
+const (
+	ConfirmNone      ConfirmationLevel = iota // ConfirmNone no confirmation is needed
+	ConfirmPrompt                             // ConfirmPrompt the user confirms the operation, like answering a yes or no prompt
+	ConfirmTypedName                          // ConfirmTypedName the user types the name of the project or resource affected
+)
+
+func (l ConfirmationLevel) String() string {
+	switch l {
+	case ConfirmPrompt:
+		return "prompt"
+	case ConfirmTypedName:
+		return "typed-name"
+	default:
+		return "none"
+	}
+}
+
+// Environment get the class of environment the profile is tagged with, like production, empty when not tagged.
+func Environment() string { return Default().Environment() }
+func (p *Profile) Environment() string {
+	return strings.ToLower(p.GetString(environmentClass))
+}
+
+// SetEnvironment tags the profile with a class of environment, an empty value removes the tag.
+func SetEnvironment(v string) error { return Default().SetEnvironment(v) }
+func (p *Profile) SetEnvironment(v string) error {
+	v = strings.ToLower(v)
+	switch v {
+	case "", DevelopmentEnvironment, StagingEnvironment, ProductionEnvironment:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidEnvironment, v)
+	}
+	p.Set(environmentClass, v)
+	return nil
+}
+
+// GuardLevel returns the confirmation CLIs should require before destructive operations with the profile,
+// ConfirmNone for untagged and development profiles, ConfirmPrompt for staging ones and ConfirmTypedName otherwise,
+// so a misspelled production tag errs on the safe side.
+func GuardLevel() ConfirmationLevel { return Default().GuardLevel() }
+func (p *Profile) GuardLevel() ConfirmationLevel {
+	switch p.Environment() {
+	case "", DevelopmentEnvironment:
+		return ConfirmNone
+	case StagingEnvironment:
+		return ConfirmPrompt
+	default:
+		return ConfirmTypedName
+	}
+}
+
+// RequiresConfirmation returns true when destructive operations with the profile need to be confirmed, see GuardLevel.
+func RequiresConfirmation() bool { return Default().RequiresConfirmation() }
+func (p *Profile) RequiresConfirmation() bool {
+	return p.GuardLevel() > ConfirmNone
+}
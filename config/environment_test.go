@@ -0,0 +1,45 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_GuardLevel(t *testing.T) {
+	p := newTestProfile(t, "")
+	assert.Equal(t, ConfirmNone, p.GuardLevel())
+	assert.False(t, p.RequiresConfirmation())
+
+	require.ErrorIs(t, p.SetEnvironment("qa"), ErrInvalidEnvironment)
+	require.NoError(t, p.SetEnvironment("Production"))
+	assert.Equal(t, ProductionEnvironment, p.Environment())
+	assert.Equal(t, ConfirmTypedName, p.GuardLevel())
+	assert.True(t, p.RequiresConfirmation())
+
+	require.NoError(t, p.SetEnvironment(StagingEnvironment))
+	assert.Equal(t, ConfirmPrompt, p.GuardLevel())
+	require.NoError(t, p.SetEnvironment(DevelopmentEnvironment))
+	assert.Equal(t, ConfirmNone, p.GuardLevel())
+
+	p = newTestProfile(t, "[default]\n  environment = \"prod\"\n")
+	assert.Equal(t, ConfirmTypedName, p.GuardLevel(), "unknown tags err on the safe side")
+	assert.Equal(t, "typed-name", p.GuardLevel().String())
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+const ephemeralProfileName = "ephemeral"
+
+var ErrEphemeralProfile = errors.New("ephemeral profiles only live in memory")
+
+// NewEphemeralProfile returns a profile with the given settings that only lives in memory,
+// for automation that gets its credentials at runtime, like CI jobs.
+// The profile never reads or writes the config file, it isn't listed by List, and Save does nothing.
+// Flags bound with BindFlag take precedence over its settings, environment variables are not read.
+func NewEphemeralProfile(settings map[string]any) *Profile {
+	memory := make(map[string]any, len(settings))
+	for k, v := range settings {
+		memory[strings.ToLower(k)] = v
+	}
+	return &Profile{
+		name:   ephemeralProfileName,
+		fs:     afero.NewMemMapFs(),
+		loaded: true,
+		memory: memory,
+	}
+}
+
+// IsEphemeral returns true for profiles created with NewEphemeralProfile.
+func (p *Profile) IsEphemeral() bool {
+	return p.memory != nil
+}
+
+// profileSettings returns the settings of a profile of the config file, or the ones of an ephemeral profile.
+func (p *Profile) profileSettings(name string) map[string]any {
+	if !p.IsEphemeral() {
+		return viper.GetStringMap(name)
+	}
+	if name != p.name {
+		return nil
+	}
+	return p.memory
+}
+
+func (p *Profile) memoryValue(name string) any {
+	if v, ok := p.memory[name]; ok && v != nil && v != "" {
+		return v
+	}
+	if v, ok := p.flagDefault(name); ok {
+		return v
+	}
+	return nil
+}
+
+func (p *Profile) memorySource(key string) SettingSource {
+	if v, ok := p.memory[key]; ok && v != nil && v != "" {
+		return SourceSet
+	}
+	if _, ok := p.flagDefault(key); ok {
+		return SourceDefault
+	}
+	if _, ok := DefaultValue(key); ok {
+		return SourceDefault
+	}
+	return SourceUnset
+}
+
+func memoryStringSettings(memory map[string]any) map[string]string {
+	settings := make(map[string]string, len(memory))
+	for k, v := range memory {
+		settings[k] = fmt.Sprint(v)
+	}
+	return settings
+}
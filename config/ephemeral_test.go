@@ -0,0 +1,77 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEphemeralProfile(t *testing.T) {
+	newTestProfile(t, "output = \"json\"\n[default]\n  project_id = \"from-file\"\n")
+	p := NewEphemeralProfile(map[string]any{
+		"Org_ID":         "a",
+		AccessTokenField: "token",
+	})
+
+	require.True(t, p.IsEphemeral())
+	require.Equal(t, "a", p.OrgID())
+	require.Empty(t, p.ProjectID(), "the config file is not read")
+	require.Equal(t, string(PlaintextOutput), p.Output(), "the global output is not read")
+	require.Equal(t, OAuth, p.AuthType())
+	require.Equal(t, SourceSet, p.Source(orgID))
+	require.Equal(t, SourceUnset, p.Source(projectID))
+	require.Equal(t, map[string]string{orgID: "a", AccessTokenField: "redacted"}, p.Map())
+
+	p.SetProjectID("b")
+	p.SetSkipUpdateCheck(true)
+	require.Equal(t, "b", p.ProjectID())
+	require.True(t, p.SkipUpdateCheck())
+	require.NoError(t, p.Save())
+	require.NotContains(t, List(), ephemeralProfileName)
+
+	files, err := afero.ReadDir(p.fs, "/")
+	require.NoError(t, err)
+	require.Empty(t, files, "nothing is written")
+
+	require.ErrorIs(t, p.Delete(), ErrEphemeralProfile)
+	require.ErrorIs(t, p.Rename("other"), ErrEphemeralProfile)
+	require.ErrorIs(t, p.SetInherits(DefaultProfile), ErrEphemeralProfile)
+	require.ErrorIs(t, p.LoadAtlasCLIConfig(true), ErrEphemeralProfile)
+}
+
+func TestNewEphemeralProfile_HttpClient(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer s.Close()
+
+	p := NewEphemeralProfile(map[string]any{
+		AccessTokenField:   "token",
+		service:            CloudService,
+		OpsManagerURLField: s.URL,
+	})
+	resp, err := p.HttpClient().Get(p.HttpBaseURL())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
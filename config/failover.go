@@ -0,0 +1,208 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// failoverCooldown is how long a base URL is skipped after a connection error.
+const failoverCooldown = 30 * time.Second
+
+// FallbackBaseURLs get the configured base URLs used when the base URL can't be reached, in order of preference.
+// It can be set as an array or as a comma separated string, invalid URLs are ignored.
+func FallbackBaseURLs() []string { return Default().FallbackBaseURLs() }
+func (p *Profile) FallbackBaseURLs() []string {
+	var values []string
+	switch v := p.Get(fallbackBaseURLs).(type) {
+	case string:
+		values = strings.Split(v, ",")
+	case []any:
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				values = append(values, s)
+			}
+		}
+	case []string:
+		values = v
+	}
+
+	urls := make([]string, 0, len(values))
+	for _, v := range values {
+		if normalized, err := NormalizeBaseURL(v); err == nil {
+			urls = append(urls, normalized)
+		}
+	}
+	return urls
+}
+
+// SetFallbackBaseURLs sets the base URLs used when the base URL can't be reached.
+func SetFallbackBaseURLs(v []string) { Default().SetFallbackBaseURLs(v) }
+func (p *Profile) SetFallbackBaseURLs(v []string) {
+	p.Set(fallbackBaseURLs, v)
+}
+
+// unhealthyHosts are the hosts skipped by failover until the time they're mapped to, shared by every HttpClient
+// so a client created per request doesn't try an unreachable host again.
+var unhealthyHosts = struct {
+	sync.Mutex
+	until map[string]time.Time
+}{until: map[string]time.Time{}}
+
+// failoverTransport sends requests for the primary base URL to the first healthy base URL,
+// moving on to the next one when a connection can't be established.
+type failoverTransport struct {
+	targets  []*url.URL // targets[0] is the primary base URL
+	base     http.RoundTripper
+	cooldown time.Duration
+	now      func() time.Time
+	log      *slog.Logger
+}
+
+// failoverTransport wraps base with failover to the fallback base URLs, if any are configured.
+func (p *Profile) failoverTransport(base http.RoundTripper) http.RoundTripper {
+	fallbacks := p.FallbackBaseURLs()
+	primary, err := url.Parse(p.HttpBaseURL())
+	if len(fallbacks) == 0 || err != nil || primary.Host == "" {
+		return base
+	}
+
+	targets := []*url.URL{primary}
+	for _, f := range fallbacks {
+		if u, err := url.Parse(f); err == nil {
+			targets = append(targets, u)
+		}
+	}
+	return &failoverTransport{
+		targets:  targets,
+		base:     base,
+		cooldown: failoverCooldown,
+		now:      time.Now,
+		log:      p.Logger(),
+	}
+}
+
+func (t *failoverTransport) healthy(u *url.URL) bool {
+	unhealthyHosts.Lock()
+	defer unhealthyHosts.Unlock()
+	return !t.now().Before(unhealthyHosts.until[u.Host])
+}
+
+func (t *failoverTransport) markUnhealthy(u *url.URL) {
+	unhealthyHosts.Lock()
+	defer unhealthyHosts.Unlock()
+	unhealthyHosts.until[u.Host] = t.now().Add(t.cooldown)
+}
+
+// isConnectError returns true for the errors raised before the request is sent, like dial, DNS
+// and TLS handshake errors. Only those fail over: retrying a request the host may have received
+// could duplicate a mutation, like creating a cluster twice.
+func isConnectError(err error) bool {
+	var (
+		opErr     *net.OpError
+		dnsErr    *net.DNSError
+		certErr   *tls.CertificateVerificationError
+		alertErr  tls.AlertError
+		recordErr tls.RecordHeaderError
+	)
+	switch {
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		return true
+	case errors.As(err, &dnsErr), errors.As(err, &certErr), errors.As(err, &alertErr), errors.As(err, &recordErr):
+		return true
+	default:
+		return false
+	}
+}
+
+// candidates returns the healthy targets first, followed by the unhealthy ones in case all of them are down.
+func (t *failoverTransport) candidates() []*url.URL {
+	healthy := make([]*url.URL, 0, len(t.targets))
+	var unhealthy []*url.URL
+	for _, u := range t.targets {
+		if t.healthy(u) {
+			healthy = append(healthy, u)
+		} else {
+			unhealthy = append(unhealthy, u)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	primary := t.targets[0]
+	if req.URL.Host != primary.Host || !strings.HasPrefix(req.URL.Path, primary.Path) {
+		return t.base.RoundTrip(req)
+	}
+	// requests with a body can only be retried if it can be read again
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	var lastErr error
+	for i, target := range t.candidates() {
+		if i > 0 {
+			countRetry(req.Context())
+		}
+		attempt, err := rebase(req, primary, target)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := t.base.RoundTrip(attempt)
+		if err == nil {
+			return resp, nil
+		}
+		if req.Context().Err() != nil || !isConnectError(err) {
+			return nil, err
+		}
+		t.markUnhealthy(target)
+		t.log.Warn("base URL can't be reached", "url", target.String(), "error", err, "skipped_for", t.cooldown)
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+func (t *failoverTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
+
+// rebase returns a copy of req sent to target instead of primary.
+func rebase(req *http.Request, primary, target *url.URL) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+
+	u := *req.URL
+	u.Scheme = target.Scheme
+	u.Host = target.Host
+	u.Path = target.Path + strings.TrimPrefix(req.URL.Path, primary.Path)
+	u.RawPath = ""
+	r.URL = &u
+	r.Host = ""
+	return r, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingTransport struct {
+	base  http.RoundTripper
+	hosts []string
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.hosts = append(t.hosts, req.URL.Host)
+	return t.base.RoundTrip(req)
+}
+
+func TestProfile_FallbackBaseURLs(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  fallback_base_urls = [\"https://om2.example.com\", \"invalid\", \"https://om3.example.com/\"]\n")
+	assert.Equal(t, []string{"https://om2.example.com/", "https://om3.example.com/"}, p.FallbackBaseURLs())
+
+	p.Set(fallbackBaseURLs, "https://om4.example.com, https://om5.example.com")
+	assert.Equal(t, []string{"https://om4.example.com/", "https://om5.example.com/"}, p.FallbackBaseURLs())
+}
+
+func TestFailoverTransport(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer up.Close()
+
+	p := newTestProfile(t, "")
+	p.SetOpsManagerURL(downURL)
+	p.SetFallbackBaseURLs([]string{up.URL})
+
+	counter := &countingTransport{base: http.DefaultTransport}
+	transport, ok := p.failoverTransport(counter).(*failoverTransport)
+	require.True(t, ok)
+	now := time.Now()
+	transport.now = func() time.Time { return now }
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Post(downURL+"/api/public/v1.0/groups", "text/plain", strings.NewReader("body"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, counter.hosts, 2, "tries the primary first")
+
+	resp, err = client.Get(downURL + "/api/public/v1.0/groups")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Len(t, counter.hosts, 3, "skips the unhealthy primary")
+
+	shared, ok := p.failoverTransport(counter).(*failoverTransport)
+	require.True(t, ok)
+	shared.now = transport.now
+	resp, err = (&http.Client{Transport: shared}).Get(downURL + "/api/public/v1.0/groups")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Len(t, counter.hosts, 4, "the health of the hosts is shared by the clients")
+
+	now = now.Add(failoverCooldown)
+	resp, err = client.Get(downURL + "/api/public/v1.0/groups")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Len(t, counter.hosts, 6, "retries the primary after the cooldown")
+}
+
+func TestFailoverTransport_requestSent(t *testing.T) {
+	// the primary accepts the request and drops the connection, like a reset after a cluster was created
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		conn.Close()
+	}))
+	defer primary.Close()
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer fallback.Close()
+
+	p := newTestProfile(t, "")
+	p.SetOpsManagerURL(primary.URL)
+	p.SetFallbackBaseURLs([]string{fallback.URL})
+	counter := &countingTransport{base: &http.Transport{DisableKeepAlives: true}}
+	client := &http.Client{Transport: p.failoverTransport(counter)}
+
+	_, err := client.Post(primary.URL+"/api/public/v1.0/groups/1/clusters", "application/json", strings.NewReader("{}"))
+	require.Error(t, err)
+	assert.Len(t, counter.hosts, 1, "requests the primary may have received aren't sent again")
+}
+
+func TestIsConnectError(t *testing.T) {
+	assert.True(t, isConnectError(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	assert.True(t, isConnectError(&url.Error{Op: "Get", Err: &net.DNSError{Err: "no such host"}}))
+	assert.True(t, isConnectError(&tls.CertificateVerificationError{Err: errors.New("unknown authority")}))
+	assert.False(t, isConnectError(&net.OpError{Op: "read", Err: errors.New("connection reset by peer")}))
+	assert.False(t, isConnectError(io.ErrUnexpectedEOF))
+}
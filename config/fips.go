@@ -0,0 +1,69 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"crypto/tls"
+	"errors"
+)
+
+var ErrFIPSBuild = errors.New("FIPS mode can't be disabled, the binary is built with boringcrypto")
+
+// FIPSMode reports whether the crypto is restricted to FIPS 140 approved algorithms, for FedRAMP environments like cloudgov.
+// It's always enabled in binaries built with GOEXPERIMENT=boringcrypto, which also restricts crypto/tls to the
+// validated BoringCrypto module, and can be enabled with the fips_mode setting otherwise.
+// In FIPS mode HttpClient only negotiates TLS 1.2 with AES-GCM and NIST curves,
+// HTTP digest authentication only accepts SHA-256, the NTLM and Negotiate proxy authentication is refused,
+// and JWTSigningMethods excludes EdDSA.
+func FIPSMode() bool { return Default().FIPSMode() }
+func (p *Profile) FIPSMode() bool {
+	return fipsBuild || p.GetBool(fipsMode)
+}
+
+// SetFIPSMode sets the global FIPS mode, it can't be disabled in binaries built with boringcrypto.
+func SetFIPSMode(v bool) error { return Default().SetFIPSMode(v) }
+func (p *Profile) SetFIPSMode(v bool) error {
+	if !v && fipsBuild {
+		return ErrFIPSBuild
+	}
+	p.SetGlobal(fipsMode, v)
+	return nil
+}
+
+// fipsCipherSuites are the TLS 1.2 cipher suites approved by SP 800-52.
+var fipsCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+}
+
+// restrictToFIPS limits c to FIPS approved algorithms. TLS 1.3 is disabled because its cipher suites
+// can't be configured and include ChaCha20.
+func restrictToFIPS(c *tls.Config) {
+	c.MinVersion = tls.VersionTLS12
+	c.MaxVersion = tls.VersionTLS12
+	c.CipherSuites = fipsCipherSuites
+	c.CurvePreferences = []tls.CurveID{tls.CurveP256, tls.CurveP384, tls.CurveP521}
+}
+
+// JWTSigningMethods returns the JWT signing algorithms tokens may be verified with, nil when any is allowed.
+func JWTSigningMethods() []string { return Default().JWTSigningMethods() }
+func (p *Profile) JWTSigningMethods() []string {
+	if !p.FIPSMode() {
+		return nil
+	}
+	return []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+}
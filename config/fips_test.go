@@ -0,0 +1,69 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_FIPSMode(t *testing.T) {
+	p := newTestProfile(t, "")
+	if fipsBuild {
+		t.Skip("FIPS mode is always enabled with boringcrypto")
+	}
+	require.False(t, p.FIPSMode())
+	require.Nil(t, p.tlsConfig())
+	require.Nil(t, p.JWTSigningMethods())
+
+	require.NoError(t, p.SetFIPSMode(true))
+	require.True(t, p.FIPSMode())
+	c := p.baseTransport().TLSClientConfig
+	require.NotNil(t, c)
+	require.Equal(t, uint16(tls.VersionTLS12), c.MaxVersion)
+	require.Equal(t, fipsCipherSuites, c.CipherSuites)
+	require.NotContains(t, p.JWTSigningMethods(), "EdDSA")
+}
+
+func TestDigestTransport_FIPS(t *testing.T) {
+	var challenge string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("WWW-Authenticate", challenge)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	for _, c := range []string{`Digest realm="r", nonce="n", qop="auth"`, `Digest realm="r", nonce="n", qop="auth", algorithm=MD5`} {
+		challenge = c
+		d := newDigestTransport("user", "pass", http.DefaultTransport)
+		d.fips = true
+		_, err := (&http.Client{Transport: d}).Get(ts.URL)
+		require.ErrorIs(t, err, ErrUnsupportedDigestChallenge)
+	}
+
+	challenge = `Digest realm="r", nonce="n", qop="auth", algorithm=SHA-256`
+	d := newDigestTransport("user", "pass", http.DefaultTransport)
+	d.fips = true
+	resp, err := (&http.Client{Transport: d}).Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var ErrFlagNotFound = errors.New("no flag found for key")
+
+// flagNames returns the flag names a key can be bound to, for example projectId, project-id and project_id for project_id.
+func flagNames(key string) []string {
+	parts := strings.Split(key, "_")
+	camel := parts[0]
+	for _, part := range parts[1:] {
+		if part != "" {
+			camel += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return []string{camel, strings.ReplaceAll(key, "_", "-"), key}
+}
+
+// BindFlag makes a flag take precedence over every other source of a key.
+// When the flag is not set the key is resolved as usual, falling back to the flag default.
+func BindFlag(key string, flag *pflag.Flag) { Default().BindFlag(key, flag) }
+func (p *Profile) BindFlag(key string, flag *pflag.Flag) {
+	if p.flags == nil {
+		p.flags = map[string]*pflag.Flag{}
+	}
+	p.flags[strings.ToLower(key)] = flag
+}
+
+// BindFlags binds the flags of a command to profile keys, so flags take precedence over env variables,
+// which take precedence over the profile, which takes precedence over the flag default.
+// Flags are looked up by the camel case, kebab case or snake case version of the key, for example projectId for project_id.
+func BindFlags(cmd *cobra.Command, keys ...string) error { return Default().BindFlags(cmd, keys...) }
+func (p *Profile) BindFlags(cmd *cobra.Command, keys ...string) error {
+	for _, key := range keys {
+		flag := lookupFlag(cmd, key)
+		if flag == nil {
+			return fmt.Errorf("%w: %q", ErrFlagNotFound, key)
+		}
+		p.BindFlag(key, flag)
+	}
+	return nil
+}
+
+func lookupFlag(cmd *cobra.Command, key string) *pflag.Flag {
+	for _, name := range flagNames(key) {
+		if f := cmd.Flags().Lookup(name); f != nil {
+			return f
+		}
+		if f := cmd.InheritedFlags().Lookup(name); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// flagValue returns the value of the flag bound to a key if it was set in the command line.
+func (p *Profile) flagValue(key string) (string, bool) {
+	f := p.flags[key]
+	if f == nil || !f.Changed {
+		return "", false
+	}
+	return f.Value.String(), true
+}
+
+// flagDefault returns the default value of the flag bound to a key.
+func (p *Profile) flagDefault(key string) (string, bool) {
+	f := p.flags[key]
+	if f == nil || f.DefValue == "" {
+		return "", false
+	}
+	return f.DefValue, true
+}
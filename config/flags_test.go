@@ -0,0 +1,54 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_flagNames(t *testing.T) {
+	assert.Equal(t, []string{"projectId", "project-id", "project_id"}, flagNames(projectID))
+	assert.Equal(t, []string{"output", "output", "output"}, flagNames(output))
+}
+
+func TestProfile_BindFlags(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  org_id = \"file-org\"\n  project_id = \"file-project\"\n")
+
+	root := &cobra.Command{Use: "atlas"}
+	root.PersistentFlags().String("output", "plaintext", "")
+	cmd := &cobra.Command{Use: "list", Run: func(*cobra.Command, []string) {}}
+	cmd.Flags().String("projectId", "", "")
+	cmd.Flags().String("org-id", "", "")
+	root.AddCommand(cmd)
+
+	require.ErrorIs(t, p.BindFlags(cmd, "private_api_key"), ErrFlagNotFound)
+
+	root.SetArgs([]string{"list", "--projectId", "flag-project"})
+	require.NoError(t, root.Execute())
+	require.NoError(t, p.BindFlags(cmd, projectID, orgID, output))
+
+	assert.Equal(t, "flag-project", p.ProjectID())
+	assert.Equal(t, SourceFlag, p.Source(projectID))
+	assert.Equal(t, "file-org", p.OrgID())
+	assert.Equal(t, SourceProfile, p.Source(orgID))
+	assert.Equal(t, "plaintext", p.Output())
+	assert.Equal(t, SourceDefault, p.Source(output))
+}
@@ -0,0 +1,52 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import "reflect"
+
+// ChangeHook is called after a setting of a profile changes, old is nil when the setting wasn't set.
+type ChangeHook func(key string, old, new any)
+
+type registeredHook struct {
+	hook ChangeHook
+}
+
+// OnChange registers a hook called after a setting of the profile is changed by this process,
+// like credentials being refreshed or the project being switched, and returns a function removing it.
+// Hooks are called synchronously by the goroutine making the change, changes made to the config file
+// by other processes are not reported.
+func OnChange(hook ChangeHook) func() { return Default().OnChange(hook) }
+func (p *Profile) OnChange(hook ChangeHook) func() {
+	h := &registeredHook{hook: hook}
+	p.hooks = append(p.hooks, h)
+	return func() {
+		for i, registered := range p.hooks {
+			if registered == h {
+				p.hooks = append(p.hooks[:i:i], p.hooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// notifyChange calls the hooks when the value of a setting changed.
+func (p *Profile) notifyChange(key string, oldValue, newValue any) {
+	if len(p.hooks) == 0 || reflect.DeepEqual(oldValue, newValue) {
+		return
+	}
+	for _, h := range p.hooks {
+		h.hook(key, oldValue, newValue)
+	}
+}
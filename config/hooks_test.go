@@ -0,0 +1,69 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type change struct {
+	key      string
+	old, new any
+}
+
+func recordChanges(p *Profile) (*[]change, func()) {
+	var changes []change
+	remove := p.OnChange(func(key string, old, new any) {
+		changes = append(changes, change{key, old, new})
+	})
+	return &changes, remove
+}
+
+func TestProfile_OnChange(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  project_id = \""+testProject1+"\"\n")
+	changes, remove := recordChanges(p)
+
+	p.SetProjectID(testProject2)
+	p.SetProjectID(testProject2)
+	p.SetAccessToken("token")
+	p.SetGlobal(skipUpdateCheck, true)
+	require.NoError(t, p.PushProject(testProject1))
+	_, err := p.PopProject()
+	require.NoError(t, err)
+
+	assert.Equal(t, []change{
+		{projectID, testProject1, testProject2},
+		{AccessTokenField, nil, "token"},
+		{skipUpdateCheck, nil, true},
+		{projectID, testProject2, testProject1},
+		{projectID, testProject1, testProject2},
+	}, *changes, "setting the same value isn't a change")
+
+	remove()
+	p.SetProjectID(testProject1)
+	assert.Len(t, *changes, 5)
+}
+
+func TestProfile_OnChangeEphemeral(t *testing.T) {
+	p := NewEphemeralProfile(map[string]any{AccessTokenField: "a"})
+	changes, _ := recordChanges(p)
+	p.SetAccessToken("b")
+	assert.Equal(t, []change{{AccessTokenField, "a", "b"}}, *changes)
+}
@@ -0,0 +1,134 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+const identitySubject = "subject"
+
+var ErrUnknownIdentity = errors.New("unknown identity")
+
+// identityList returns the identities setting as a list of tables, it's []any when read from the config file.
+func identityList(v any) []map[string]any {
+	var list []map[string]any
+	switch v := v.(type) {
+	case []map[string]any:
+		for _, m := range v {
+			list = append(list, cloneIdentity(m))
+		}
+	case []any:
+		for _, e := range v {
+			if m, ok := e.(map[string]any); ok {
+				list = append(list, cloneIdentity(m))
+			}
+		}
+	}
+	return list
+}
+
+func cloneIdentity(m map[string]any) map[string]any {
+	c := make(map[string]any, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func identitySubjectOf(m map[string]any) string {
+	s, _ := m[identitySubject].(string)
+	return s
+}
+
+// Identities returns the subjects of the token sets stored in the profile, see StoreIdentity.
+func Identities() []string { return Default().Identities() }
+func (p *Profile) Identities() []string {
+	var subjects []string
+	for _, id := range identityList(p.Get(identities)) {
+		if s := identitySubjectOf(id); s != "" {
+			subjects = append(subjects, s)
+		}
+	}
+	sort.Strings(subjects)
+	return subjects
+}
+
+// StoreIdentity keeps the access and refresh tokens of the profile under the subject of the access token,
+// so the user can log in with another account and switch back with UseIdentity. It returns the subject.
+// Like other changes, the identity is written to the config file on Save.
+func StoreIdentity() (string, error) { return Default().StoreIdentity() }
+func (p *Profile) StoreIdentity() (string, error) {
+	subject, err := p.AccessTokenSubject()
+	if err != nil {
+		return "", err
+	}
+	if subject == "" {
+		return "", fmt.Errorf("%w: the access token has no subject", ErrUnknownIdentity)
+	}
+	p.setIdentity(subject, map[string]any{
+		identitySubject:   subject,
+		AccessTokenField:  p.AccessToken(),
+		RefreshTokenField: p.RefreshToken(),
+	})
+	return subject, nil
+}
+
+// UseIdentity makes the tokens stored for the subject the credentials of the profile.
+// The current tokens are stored first, so tokens refreshed since they were stored are kept.
+func UseIdentity(subject string) error { return Default().UseIdentity(subject) }
+func (p *Profile) UseIdentity(subject string) error {
+	list := identityList(p.Get(identities))
+	i := slices.IndexFunc(list, func(m map[string]any) bool { return identitySubjectOf(m) == subject })
+	if i < 0 {
+		return fmt.Errorf("%w: %q", ErrUnknownIdentity, subject)
+	}
+	if current, err := p.AccessTokenSubject(); err == nil && current != "" && current != subject {
+		if _, err := p.StoreIdentity(); err != nil {
+			return err
+		}
+	}
+
+	access, _ := revealSecret(AccessTokenField, list[i][AccessTokenField]).(string)
+	refresh, _ := revealSecret(RefreshTokenField, list[i][RefreshTokenField]).(string)
+	p.SetAccessToken(access)
+	p.SetRefreshToken(refresh)
+	return nil
+}
+
+// RemoveIdentity forgets the tokens stored for the subject.
+func RemoveIdentity(subject string) error { return Default().RemoveIdentity(subject) }
+func (p *Profile) RemoveIdentity(subject string) error {
+	list := identityList(p.Get(identities))
+	i := slices.IndexFunc(list, func(m map[string]any) bool { return identitySubjectOf(m) == subject })
+	if i < 0 {
+		return fmt.Errorf("%w: %q", ErrUnknownIdentity, subject)
+	}
+	p.Set(identities, slices.Delete(list, i, i+1))
+	return nil
+}
+
+func (p *Profile) setIdentity(subject string, id map[string]any) {
+	list := identityList(p.Get(identities))
+	if i := slices.IndexFunc(list, func(m map[string]any) bool { return identitySubjectOf(m) == subject }); i >= 0 {
+		list[i] = id
+	} else {
+		list = append(list, id)
+	}
+	p.Set(identities, list)
+}
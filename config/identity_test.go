@@ -0,0 +1,101 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func testSubjectToken(t *testing.T, subject string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: subject}).
+		SignedString([]byte("secret"))
+	require.NoError(t, err)
+	return token
+}
+
+func TestProfile_UseIdentity(t *testing.T) {
+	alice, bob := testSubjectToken(t, "alice@example.com"), testSubjectToken(t, "bob@example.com")
+	p := newTestProfile(t, "")
+
+	p.SetAccessToken(alice)
+	p.SetRefreshToken("alice-refresh")
+	subject, err := p.StoreIdentity()
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com", subject)
+
+	p.SetAccessToken(bob)
+	p.SetRefreshToken("bob-refresh")
+	_, err = p.StoreIdentity()
+	require.NoError(t, err)
+	require.NoError(t, p.Save())
+	require.Equal(t, []string{"alice@example.com", "bob@example.com"}, p.Identities())
+
+	b, err := afero.ReadFile(p.fs, p.Filename())
+	require.NoError(t, err)
+	p = newTestProfile(t, string(b))
+	require.Equal(t, []string{"alice@example.com", "bob@example.com"}, p.Identities())
+
+	// bob's token was refreshed since it was stored, switching keeps the new one
+	p.SetRefreshToken("bob-refreshed")
+	require.NoError(t, p.UseIdentity("alice@example.com"))
+	require.Equal(t, alice, p.AccessToken())
+	require.Equal(t, "alice-refresh", p.RefreshToken())
+
+	require.NoError(t, p.UseIdentity("bob@example.com"))
+	require.Equal(t, bob, p.AccessToken())
+	require.Equal(t, "bob-refreshed", p.RefreshToken())
+
+	require.ErrorIs(t, p.UseIdentity("carol@example.com"), ErrUnknownIdentity)
+	require.NoError(t, p.RemoveIdentity("alice@example.com"))
+	require.Equal(t, []string{"bob@example.com"}, p.Identities())
+	require.ErrorIs(t, p.RemoveIdentity("alice@example.com"), ErrUnknownIdentity)
+}
+
+func TestProfile_StoreIdentityWithoutSubject(t *testing.T) {
+	p := newTestProfile(t, "")
+	_, err := p.StoreIdentity()
+	require.Error(t, err)
+
+	p.SetAccessToken(testSubjectToken(t, ""))
+	_, err = p.StoreIdentity()
+	require.ErrorIs(t, err, ErrUnknownIdentity)
+}
+
+func TestProfile_EncryptIdentities(t *testing.T) {
+	fakeEncryption(t)
+	p := newTestProfile(t, "")
+	require.NoError(t, p.SetEncryptSecrets(true))
+	p.SetAccessToken(testSubjectToken(t, "alice@example.com"))
+	p.SetRefreshToken("alice-refresh")
+	_, err := p.StoreIdentity()
+	require.NoError(t, err)
+	require.NoError(t, p.Save())
+
+	b, err := afero.ReadFile(p.fs, p.Filename())
+	require.NoError(t, err)
+	require.NotContains(t, string(b), "alice-refresh")
+
+	p = newTestProfile(t, string(b))
+	p.SetAccessToken("")
+	require.NoError(t, p.UseIdentity("alice@example.com"))
+	require.Equal(t, "alice-refresh", p.RefreshToken())
+}
@@ -0,0 +1,140 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// profileIndex keeps the names of the profiles so List doesn't need to materialize every setting with viper.AllSettings.
+// Profiles share viper, so the index is shared too and belongs to the viper instance it was built for,
+// after a viper.Reset List falls back to reading the settings.
+var profileIndex struct {
+	sync.Mutex
+	v      *viper.Viper
+	file   []string            // profiles in the config file when it was last read or written
+	memory map[string]struct{} // profiles set by this process
+}
+
+// indexFile records the profiles of the config file contents.
+func indexFile(contents string) {
+	names := profileNames(contents)
+
+	profileIndex.Lock()
+	defer profileIndex.Unlock()
+	if profileIndex.v != viper.GetViper() {
+		profileIndex.v = viper.GetViper()
+		profileIndex.memory = map[string]struct{}{}
+	}
+	profileIndex.file = names
+}
+
+// indexProfile records a profile set by this process, which may not be in the config file yet.
+func indexProfile(name string) {
+	profileIndex.Lock()
+	defer profileIndex.Unlock()
+	if profileIndex.v != viper.GetViper() {
+		return
+	}
+	profileIndex.memory[name] = struct{}{}
+}
+
+// indexedProfiles returns the sorted profile names, false if there's no index for the current viper instance.
+func indexedProfiles() ([]string, bool) {
+	profileIndex.Lock()
+	defer profileIndex.Unlock()
+	if profileIndex.v == nil || profileIndex.v != viper.GetViper() {
+		return nil, false
+	}
+
+	names := slices.Clone(profileIndex.file)
+	for name := range profileIndex.memory {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, true
+}
+
+// profileNames returns the profiles of a TOML document without parsing their settings,
+// they are the tables plus the top level dotted keys and inline tables that aren't global properties,
+// a profile can be named like a profile setting such as output.
+func profileNames(contents string) []string {
+	lines := strings.Split(contents, "\n")
+	spans := findTables(lines)
+
+	header := len(lines)
+	if len(spans) > 0 {
+		header = spans[0].start
+	}
+
+	var names []string
+	add := func(name string) {
+		if name != "" && !slices.Contains(GlobalProperties(), name) && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	inString := false
+	for _, line := range lines[:header] {
+		if isMultilineDelimiter(line) {
+			inString = !inString
+			if !inString {
+				continue
+			}
+		} else if inString {
+			continue
+		}
+		add(topLevelKey(line))
+	}
+	for _, s := range spans {
+		add(s.root)
+	}
+	return names
+}
+
+// topLevelKey returns the root of the key of a line like name.key = value, or name = { ... },
+// empty for other lines like the name = value of a global setting.
+func topLevelKey(line string) string {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ""
+	}
+	var name, rest string
+	if q := line[0]; q == '"' || q == '\'' {
+		end := strings.IndexByte(line[1:], q)
+		if end < 0 {
+			return ""
+		}
+		name, rest = line[1:end+1], line[end+2:]
+	} else {
+		end := strings.IndexAny(line, ".= \t")
+		if end < 0 {
+			return ""
+		}
+		name, rest = line[:end], line[end:]
+	}
+	rest = strings.TrimSpace(rest)
+	if !strings.HasPrefix(rest, ".") && !strings.HasPrefix(strings.TrimSpace(strings.TrimPrefix(rest, "=")), "{") {
+		return ""
+	}
+	return strings.ToLower(name)
+}
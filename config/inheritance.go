@@ -0,0 +1,113 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrInheritanceCycle = errors.New("profile inheritance cycle")
+	ErrProfileNotFound  = errors.New("profile not found")
+)
+
+// isInheritable returns false for the keys that only apply to the profile declaring them.
+func isInheritable(key string) bool {
+	return key != inherits && key != readOnly
+}
+
+// chain returns the name of the profile followed by the names of the profiles it inherits from, closest first.
+func (p *Profile) chain() []string {
+	p.ensureLoaded()
+	cache := p.settingsCache()
+	if chain := cache.getChain(); chain != nil {
+		return chain
+	}
+
+	names := []string{p.Name()}
+	for {
+		parent, _ := p.profileSettings(names[len(names)-1])[inherits].(string)
+		parent = strings.ToLower(parent)
+		if parent == "" || slices.Contains(names, parent) {
+			cache.setChain(names)
+			return names
+		}
+		names = append(names, parent)
+	}
+}
+
+// lookup returns the value of a key and the name of the profile it's set in,
+// falling back to the profiles this profile inherits from.
+func (p *Profile) lookup(key string) (any, string) {
+	names := p.chain()
+	cache := p.settingsCache()
+	if r, ok := cache.lookup(key); ok {
+		return r.value, r.profile
+	}
+
+	r := lookupResult{}
+	for i, name := range names {
+		if i > 0 && !isInheritable(key) {
+			break
+		}
+		if v, ok := p.profileSettings(name)[key]; ok {
+			r = lookupResult{value: v, profile: name}
+			break
+		}
+	}
+	cache.setLookup(key, r)
+	return r.value, r.profile
+}
+
+// Inherits returns the name of the profile used for the settings that are not set in this profile.
+func Inherits() string { return Default().Inherits() }
+func (p *Profile) Inherits() string {
+	v, _ := p.profileSettings(p.Name())[inherits].(string)
+	return strings.ToLower(v)
+}
+
+// SetInherits makes the profile fall back to an existing parent profile for the settings it doesn't set,
+// an empty name removes the parent.
+func SetInherits(parent string) error { return Default().SetInherits(parent) }
+func (p *Profile) SetInherits(parent string) error {
+	if p.IsEphemeral() {
+		return ErrEphemeralProfile
+	}
+	parent = strings.ToLower(parent)
+	if parent != "" {
+		if !p.Exists(parent) {
+			return fmt.Errorf("%w: %q", ErrProfileNotFound, parent)
+		}
+		if slices.Contains(p.sibling(parent).chain(), p.Name()) {
+			return fmt.Errorf("%w: %q already inherits from %q", ErrInheritanceCycle, parent, p.Name())
+		}
+	}
+
+	p.Set(inherits, parent)
+	return nil
+}
+
+// InheritedFrom returns the name of the profile the value of a key comes from
+// when it's inherited, or an empty string when it's set in this profile or not set at all.
+func InheritedFrom(key string) string { return Default().InheritedFrom(key) }
+func (p *Profile) InheritedFrom(key string) string {
+	if _, name := p.lookup(key); name != p.Name() {
+		return name
+	}
+	return ""
+}
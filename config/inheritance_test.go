@@ -0,0 +1,79 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const inheritanceConfig = `
+[base]
+  service = "ops-manager"
+  ops_manager_url = "https://om.example.com/"
+  output = "json"
+  read_only = true
+
+[team]
+  inherits = "base"
+  output = "plaintext"
+
+[default]
+  inherits = "team"
+  public_api_key = "public"
+  private_api_key = "private"
+`
+
+func TestProfile_Inheritance(t *testing.T) {
+	p := newTestProfile(t, inheritanceConfig)
+
+	assert.Equal(t, "team", p.Inherits())
+	assert.Equal(t, "ops-manager", p.Service())
+	assert.Equal(t, "https://om.example.com/", p.OpsManagerURL())
+	assert.Equal(t, "plaintext", p.Output())
+	assert.False(t, p.IsReadOnly(), "read_only is not inherited")
+
+	assert.Equal(t, "base", p.InheritedFrom(service))
+	assert.Equal(t, "team", p.InheritedFrom(output))
+	assert.Empty(t, p.InheritedFrom(publicAPIKey))
+
+	assert.Equal(t, map[string]string{
+		inherits:           "team",
+		publicAPIKey:       "public",
+		privateAPIKey:      "redacted",
+		output:             "plaintext",
+		service:            "ops-manager",
+		OpsManagerURLField: "https://om.example.com/",
+	}, p.Map())
+}
+
+func TestProfile_SetInherits(t *testing.T) {
+	p := newTestProfile(t, inheritanceConfig)
+	base := p.sibling("base")
+
+	require.ErrorIs(t, base.SetInherits("missing"), ErrProfileNotFound)
+	require.ErrorIs(t, base.SetInherits("default"), ErrInheritanceCycle)
+
+	require.NoError(t, p.SetInherits("base"))
+	assert.Equal(t, "json", p.Output())
+
+	require.NoError(t, p.SetInherits(""))
+	assert.True(t, p.IsDefault(output))
+	assert.Equal(t, string(PlaintextOutput), p.Output())
+}
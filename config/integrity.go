@@ -0,0 +1,117 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+var ErrConfigIntegrity = errors.New("config file integrity check failed")
+
+// IntegrityError is returned when loading a config file that doesn't match its signature.
+type IntegrityError struct {
+	Path   string
+	Reason string
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("%v: %s: %s", ErrConfigIntegrity, e.Path, e.Reason)
+}
+
+func (*IntegrityError) Unwrap() error {
+	return ErrConfigIntegrity
+}
+
+// SetIntegrityKey enables signing the config file with an HMAC keyed by the given secret,
+// for example a machine secret or a keyring entry.
+// Once set, the signature is verified every time the config is read and updated every time it's written.
+func SetIntegrityKey(key []byte) { Default().SetIntegrityKey(key) }
+func (p *Profile) SetIntegrityKey(key []byte) {
+	p.integrityKey = key
+}
+
+// SignatureFilename returns the path where the config file HMAC is stored.
+func (p *Profile) SignatureFilename() string {
+	return p.Filename() + ".hmac"
+}
+
+// Sign stores the HMAC of the current config file, use it to trust a config edited by hand.
+// It's a no-op unless an integrity key was set.
+func Sign() error { return Default().Sign() }
+func (p *Profile) Sign() error {
+	if len(p.integrityKey) == 0 {
+		return nil
+	}
+
+	mac, err := p.fileHMAC()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(p.fs, p.SignatureFilename(), []byte(hex.EncodeToString(mac)), configPerm)
+}
+
+func (p *Profile) fileHMAC() ([]byte, error) {
+	b, err := afero.ReadFile(p.fs, p.Filename())
+	if err != nil {
+		return nil, err
+	}
+
+	h := hmac.New(sha256.New, p.integrityKey)
+	h.Write(b)
+	return h.Sum(nil), nil
+}
+
+// verifyIntegrity checks the config file against its signature before viper parses it,
+// so tampered or corrupted files are reported as such instead of as a parse error.
+func (p *Profile) verifyIntegrity() error {
+	if len(p.integrityKey) == 0 {
+		return nil
+	}
+
+	mac, err := p.fileHMAC()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	signature, err := afero.ReadFile(p.fs, p.SignatureFilename())
+	if errors.Is(err, fs.ErrNotExist) {
+		return &IntegrityError{Path: p.Filename(), Reason: "signature not found"}
+	}
+	if err != nil {
+		return err
+	}
+
+	expected, err := hex.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil || !hmac.Equal(mac, expected) {
+		return &IntegrityError{Path: p.Filename(), Reason: "contents don't match the signature, the file was modified or is corrupted"}
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_verifyIntegrity(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  org_id = \"a\"\n")
+	require.NoError(t, p.verifyIntegrity(), "no key disables the check")
+
+	p.SetIntegrityKey([]byte("machine-secret"))
+	var integrityErr *IntegrityError
+	err := p.verifyIntegrity()
+	require.ErrorAs(t, err, &integrityErr)
+	assert.Equal(t, "signature not found", integrityErr.Reason)
+
+	require.NoError(t, p.Sign())
+	require.NoError(t, p.verifyIntegrity())
+
+	p.SetOrgID("b")
+	require.NoError(t, p.Save())
+	require.NoError(t, p.verifyIntegrity(), "saving updates the signature")
+
+	require.NoError(t, afero.WriteFile(p.fs, p.Filename(), []byte("[default]\n  org_id = \"c\"\n"), configPerm))
+	require.ErrorIs(t, p.verifyIntegrity(), ErrConfigIntegrity)
+	require.ErrorIs(t, p.readConfig(), ErrConfigIntegrity)
+}
+
+func TestProfile_integrityOnWrite(t *testing.T) {
+	const tampered = "[default]\n  org_id = \"tampered\"\n\n[other]\n  org_id = \"c\"\n"
+	p := newTestProfile(t, "[default]\n  org_id = \"a\"\n\n[other]\n  org_id = \"b\"\n")
+	p.SetIntegrityKey([]byte("machine-secret"))
+	require.NoError(t, p.Sign())
+	require.NoError(t, afero.WriteFile(p.fs, p.Filename(), []byte(tampered), configPerm))
+
+	p.SetProjectID("p")
+	require.ErrorIs(t, p.Save(), ErrConfigIntegrity, "a file modified after it was loaded isn't signed again")
+	require.ErrorIs(t, p.sibling("other").Delete(), ErrConfigIntegrity)
+	require.ErrorIs(t, p.sibling("other").Rename("renamed"), ErrConfigIntegrity)
+	_, err := p.Recover()
+	require.ErrorIs(t, err, ErrConfigIntegrity)
+
+	b, err := afero.ReadFile(p.fs, p.Filename())
+	require.NoError(t, err)
+	assert.Equal(t, tampered, string(b), "nothing was written")
+	require.ErrorIs(t, p.verifyIntegrity(), ErrConfigIntegrity)
+
+	require.NoError(t, p.Sign(), "the user trusts the file")
+	_, err = p.Recover()
+	require.NoError(t, err)
+	require.NoError(t, p.verifyIntegrity())
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// introspectionPath is the OAuth token introspection endpoint, relative to AuthURL.
+const introspectionPath = "api/oauth/introspect"
+
+var (
+	ErrIntrospection = errors.New("token introspection failed")
+	ErrNoAccessToken = errors.New("no access token configured")
+)
+
+// TokenIntrospection is what the authorization server reports about an access token, see IntrospectToken.
+type TokenIntrospection struct {
+	Active    bool // Active is false for expired, revoked or unknown tokens, the other fields are empty then
+	Scopes    []string
+	ExpiresAt time.Time // ExpiresAt is the zero time when the server doesn't report it
+	Subject   string
+	ClientID  string
+}
+
+// IntrospectToken asks the authorization server whether the access token is still active (RFC 7662),
+// which unlike checking its expiry locally detects tokens revoked by the server.
+func IntrospectToken(ctx context.Context) (*TokenIntrospection, error) {
+	return Default().IntrospectToken(ctx)
+}
+func (p *Profile) IntrospectToken(ctx context.Context) (*TokenIntrospection, error) {
+	token := p.AccessToken()
+	if token == "" {
+		return nil, ErrNoAccessToken
+	}
+	base := p.AuthURL()
+	if base == "" {
+		return nil, fmt.Errorf("%w: %q doesn't support OAuth", ErrIntrospection, p.CurrentService())
+	}
+
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	if id := p.ClientID(); id != "" {
+		form.Set("client_id", id)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+introspectionPath, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.unauthenticatedClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIntrospection, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %s", ErrIntrospection, resp.Status)
+	}
+
+	var body struct {
+		Active   bool   `json:"active"`
+		Scope    string `json:"scope"`
+		Exp      int64  `json:"exp"`
+		Sub      string `json:"sub"`
+		ClientID string `json:"client_id"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIntrospection, err)
+	}
+
+	result := &TokenIntrospection{Active: body.Active}
+	if !body.Active {
+		return result, nil
+	}
+	result.Scopes = strings.Fields(body.Scope)
+	result.Subject = body.Sub
+	result.ClientID = body.ClientID
+	if body.Exp > 0 {
+		result.ExpiresAt = time.Unix(body.Exp, 0)
+	}
+	return result, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_IntrospectToken(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/"+introspectionPath, r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"))
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "cli", r.PostForm.Get("client_id"))
+		if r.PostForm.Get("token") == "revoked" {
+			_, _ = w.Write([]byte(`{"active": false}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"active": true, "scope": "openid profile", "exp": 1700000000, "sub": "user@example.com"}`))
+	}))
+	defer s.Close()
+
+	p := newTestProfile(t, "[default]\n  client_id = \"cli\"\n")
+	_, err := p.IntrospectToken(context.Background())
+	require.ErrorIs(t, err, ErrNoAccessToken)
+
+	p.SetOpsManagerURL(s.URL)
+	p.SetAccessToken("token")
+	got, err := p.IntrospectToken(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &TokenIntrospection{
+		Active:    true,
+		Scopes:    []string{"openid", "profile"},
+		ExpiresAt: time.Unix(1700000000, 0),
+		Subject:   "user@example.com",
+	}, got)
+
+	p.SetAccessToken("revoked")
+	got, err = p.IntrospectToken(context.Background())
+	require.NoError(t, err)
+	require.False(t, got.Active)
+}
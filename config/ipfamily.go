@@ -0,0 +1,79 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+const (
+	IPFamilyAuto = "auto" // IPFamilyAuto connects over IPv6 and IPv4 in parallel (Happy Eyeballs), the default
+	IPFamilyIPv4 = "ipv4" // IPFamilyIPv4 connects over IPv4 only
+	IPFamilyIPv6 = "ipv6" // IPFamilyIPv6 connects over IPv6 only
+)
+
+var ErrInvalidIPFamily = errors.New("invalid IP family, expected one of auto, ipv4 or ipv6")
+
+// IPFamily gets the IP family HttpClient connects over, IPFamilyAuto by default or when the value is invalid.
+// Set it to ipv4 on networks with broken IPv6, where connections hang before falling back to IPv4.
+func IPFamily() string { return Default().IPFamily() }
+func (p *Profile) IPFamily() string {
+	switch v := strings.ToLower(p.GetString(ipFamily)); v {
+	case IPFamilyIPv4, IPFamilyIPv6:
+		return v
+	default:
+		return IPFamilyAuto
+	}
+}
+
+// SetIPFamily sets the IP family HttpClient connects over.
+func SetIPFamily(v string) error { return Default().SetIPFamily(v) }
+func (p *Profile) SetIPFamily(v string) error {
+	v = strings.ToLower(v)
+	switch v {
+	case IPFamilyAuto, IPFamilyIPv4, IPFamilyIPv6:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidIPFamily, v)
+	}
+	p.Set(ipFamily, v)
+	return nil
+}
+
+// ipFamilyDialer wraps dial to restrict tcp connections to the IP family, net.Dialer already
+// races IPv6 and IPv4 for auto.
+func ipFamilyDialer(
+	family string,
+	dial func(ctx context.Context, network, addr string) (net.Conn, error),
+) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	suffix := ""
+	switch family {
+	case IPFamilyIPv4:
+		suffix = "4"
+	case IPFamilyIPv6:
+		suffix = "6"
+	default:
+		return dial
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if network == "tcp" {
+			network += suffix
+		}
+		return dial(ctx, network, addr)
+	}
+}
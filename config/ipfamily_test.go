@@ -0,0 +1,65 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_IPFamily(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  ip_family = \"tcp\"\n")
+	assert.Equal(t, IPFamilyAuto, p.IPFamily(), "invalid values fall back to auto")
+
+	require.ErrorIs(t, p.SetIPFamily("ipv5"), ErrInvalidIPFamily)
+	require.NoError(t, p.SetIPFamily("IPv4"))
+	assert.Equal(t, IPFamilyIPv4, p.IPFamily())
+
+	var networks []string
+	dial := func(_ context.Context, network, _ string) (net.Conn, error) {
+		networks = append(networks, network)
+		return nil, net.ErrClosed
+	}
+	for _, family := range []string{IPFamilyAuto, IPFamilyIPv4, IPFamilyIPv6} {
+		_, _ = ipFamilyDialer(family, dial)(context.Background(), "tcp", "localhost:443")
+	}
+	_, _ = ipFamilyDialer(IPFamilyIPv4, dial)(context.Background(), "udp", "localhost:53")
+	assert.Equal(t, []string{"tcp", "tcp4", "tcp6", "udp"}, networks)
+}
+
+func TestProfile_IPFamily_HttpClient(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer s.Close()
+	_, port, err := net.SplitHostPort(s.Listener.Addr().String())
+	require.NoError(t, err)
+
+	p := newTestProfile(t, "")
+	require.NoError(t, p.SetIPFamily(IPFamilyIPv4))
+	resp, err := p.HttpClient().Get("http://localhost:" + port + "/")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.NoError(t, p.SetIPFamily(IPFamilyIPv6))
+	_, err = p.HttpClient().Get("http://127.0.0.1:" + port + "/")
+	require.Error(t, err, "an IPv4 address can't be reached over IPv6")
+}
@@ -0,0 +1,151 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrSSHJump = errors.New("can't connect through the SSH jump host")
+
+// SSHJumpHost get the configured bastion the connections go through, like user@bastion or bastion:2222.
+func SSHJumpHost() string { return Default().SSHJumpHost() }
+func (p *Profile) SSHJumpHost() string {
+	return p.GetString(sshJumpHost)
+}
+
+// SetSSHJumpHost sets the bastion the connections go through.
+func SetSSHJumpHost(v string) { Default().SetSSHJumpHost(v) }
+func (p *Profile) SetSSHJumpHost(v string) {
+	p.Set(sshJumpHost, v)
+}
+
+// newSSHCommand returns the ssh command forwarding the connection, it's replaced in tests.
+var newSSHCommand = func(args ...string) *exec.Cmd {
+	return exec.Command("ssh", args...)
+}
+
+// sshJumpDialer tunnels the connections through a bastion with ssh -W, like OpenSSH ProxyJump,
+// so the keys, agent and known hosts of the user's SSH configuration are used.
+type sshJumpDialer struct {
+	host string
+}
+
+func (p *Profile) sshJumpDialer() *sshJumpDialer {
+	host := p.SSHJumpHost()
+	if host == "" {
+		return nil
+	}
+	return &sshJumpDialer{host: host}
+}
+
+func (d *sshJumpDialer) args(addr string) []string {
+	args := []string{"-o", "BatchMode=yes", "-W", addr}
+	host := d.host
+	if h, port, err := net.SplitHostPort(host); err == nil {
+		host = h
+		args = append(args, "-p", port)
+	}
+	return append(args, "--", host)
+}
+
+// DialContext starts ssh forwarding to addr. The context only limits starting ssh,
+// the connection outlives it like connections dialed by net.Dialer.
+func (d *sshJumpDialer) DialContext(ctx context.Context, _, addr string) (net.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	cmd := newSSHCommand(d.args(addr)...)
+	c := &commandConn{cmd: cmd, addr: addr}
+	cmd.Stderr = &c.stderr
+	var err error
+	if c.stdin, err = cmd.StdinPipe(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSSHJump, err)
+	}
+	if c.stdout, err = cmd.StdoutPipe(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSSHJump, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSSHJump, err)
+	}
+	return c, nil
+}
+
+// commandConn is a connection over the standard input and output of a command.
+type commandConn struct {
+	cmd    *exec.Cmd
+	addr   string
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	stderr bytes.Buffer
+
+	waitOnce sync.Once
+	waitErr  error
+}
+
+// Read reports the error of the command, like a refused host key, when it exits.
+func (c *commandConn) Read(b []byte) (int, error) {
+	n, err := c.stdout.Read(b)
+	if errors.Is(err, io.EOF) {
+		if c.wait() != nil {
+			if msg := strings.TrimSpace(c.stderr.String()); msg != "" {
+				return n, fmt.Errorf("%w: %s", ErrSSHJump, msg)
+			}
+		}
+	}
+	return n, err
+}
+
+func (c *commandConn) Write(b []byte) (int, error) {
+	return c.stdin.Write(b)
+}
+
+func (c *commandConn) Close() error {
+	c.stdin.Close()
+	if c.cmd.Process != nil {
+		_ = c.cmd.Process.Kill()
+	}
+	_ = c.wait()
+	return nil
+}
+
+// wait waits for the command to exit, after which stderr is complete.
+func (c *commandConn) wait() error {
+	c.waitOnce.Do(func() { c.waitErr = c.cmd.Wait() })
+	return c.waitErr
+}
+
+func (c *commandConn) LocalAddr() net.Addr  { return commandAddr("ssh") }
+func (c *commandConn) RemoteAddr() net.Addr { return commandAddr(c.addr) }
+
+// Deadlines are not supported by the pipes of a command, the transport closes the connection on timeouts instead.
+func (c *commandConn) SetDeadline(time.Time) error      { return os.ErrNoDeadline }
+func (c *commandConn) SetReadDeadline(time.Time) error  { return os.ErrNoDeadline }
+func (c *commandConn) SetWriteDeadline(time.Time) error { return os.ErrNoDeadline }
+
+type commandAddr string
+
+func (commandAddr) Network() string  { return "ssh" }
+func (a commandAddr) String() string { return string(a) }
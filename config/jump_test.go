@@ -0,0 +1,84 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func stubSSHCommand(t *testing.T, name string, args ...string) *[]string {
+	t.Helper()
+	var got []string
+	orig := newSSHCommand
+	t.Cleanup(func() { newSSHCommand = orig })
+	newSSHCommand = func(sshArgs ...string) *exec.Cmd {
+		got = sshArgs
+		return exec.Command(name, args...)
+	}
+	return &got
+}
+
+func TestSSHJumpDialer(t *testing.T) {
+	args := stubSSHCommand(t, "cat")
+
+	p := newTestProfile(t, "")
+	require.Nil(t, p.sshJumpDialer())
+	p.SetSSHJumpHost("admin@bastion:2222")
+
+	conn, err := p.sshJumpDialer().DialContext(context.Background(), "tcp", "opsmanager.internal:8443")
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Equal(t, []string{"-o", "BatchMode=yes", "-W", "opsmanager.internal:8443", "-p", "2222", "--", "admin@bastion"}, *args)
+	require.Equal(t, "opsmanager.internal:8443", conn.RemoteAddr().String())
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	b := make([]byte, 4)
+	_, err = io.ReadFull(conn, b)
+	require.NoError(t, err)
+	require.Equal(t, "ping", string(b))
+}
+
+func TestSSHJumpDialer_Error(t *testing.T) {
+	stubSSHCommand(t, "sh", "-c", "echo 'Host key verification failed.' >&2; exit 255")
+
+	conn, err := (&sshJumpDialer{host: "bastion"}).DialContext(context.Background(), "tcp", "opsmanager.internal:8443")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Read(make([]byte, 1))
+	require.ErrorIs(t, err, ErrSSHJump)
+	require.ErrorContains(t, err, "Host key verification failed.")
+}
+
+func TestProfile_Proxy(t *testing.T) {
+	p := newTestProfile(t, "")
+	require.ErrorIs(t, p.SetProxy("ftp://proxy"), ErrInvalidProxy)
+	require.NoError(t, p.SetProxy("socks5://proxy:1080"))
+
+	u, err := p.baseTransport().Proxy(nil)
+	require.NoError(t, err)
+	require.Equal(t, "socks5://proxy:1080", u.String())
+
+	p.SetSSHJumpHost("bastion")
+	require.Nil(t, p.baseTransport().Proxy, "the jump host is used instead of the proxy")
+}
@@ -0,0 +1,275 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	DefaultJWKSCacheTTL = time.Hour // DefaultJWKSCacheTTL how long the keys of an identity provider are kept, see SetJWKSCacheTTL
+	jwksMinRefresh      = time.Minute
+	maxJWKSSize         = 1 << 20
+)
+
+var (
+	ErrJWKSNotConfigured = errors.New("jwks_url is not configured")
+	ErrInvalidToken      = errors.New("invalid access token")
+)
+
+// JWKSURL get the configured URL of the JSON Web Key Set used to verify access tokens, see VerifyToken.
+func JWKSURL() string { return Default().JWKSURL() }
+func (p *Profile) JWKSURL() string {
+	return p.GetString(jwksURL)
+}
+
+// SetJWKSURL sets the URL of the JSON Web Key Set used to verify access tokens.
+func SetJWKSURL(v string) { Default().SetJWKSURL(v) }
+func (p *Profile) SetJWKSURL(v string) {
+	p.Set(jwksURL, v)
+}
+
+// keySet is a JSON Web Key Set by key ID.
+type keySet struct {
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+// jwksCache keeps the key sets by URL, shared by all the profiles.
+var jwksCache = struct {
+	sync.Mutex
+	ttl  time.Duration
+	sets map[string]*keySet
+}{
+	ttl:  DefaultJWKSCacheTTL,
+	sets: map[string]*keySet{},
+}
+
+// SetJWKSCacheTTL sets how long the keys of an identity provider are kept before fetching them again.
+// Keys are fetched earlier when a token is signed with an unknown key, as providers rotate them.
+func SetJWKSCacheTTL(d time.Duration) {
+	jwksCache.Lock()
+	defer jwksCache.Unlock()
+	jwksCache.ttl = d
+}
+
+// VerifyToken verifies the signature and expiry of the access token with the keys published at JWKSURL,
+// and returns its claims. Unlike AccessTokenSubject, the claims can be trusted.
+// In FIPS mode only the algorithms of JWTSigningMethods are accepted.
+func VerifyToken(ctx context.Context) (*jwt.RegisteredClaims, error) {
+	return Default().VerifyToken(ctx)
+}
+func (p *Profile) VerifyToken(ctx context.Context) (*jwt.RegisteredClaims, error) {
+	u := p.JWKSURL()
+	if u == "" {
+		return nil, ErrJWKSNotConfigured
+	}
+	methods := p.JWTSigningMethods()
+	if methods == nil {
+		methods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+	}
+
+	client := p.unauthenticatedClient()
+	claims := &jwt.RegisteredClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods(methods))
+	if _, err := parser.ParseWithClaims(p.AccessToken(), claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return verificationKey(ctx, client, u, kid)
+	}); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
+
+// verificationKey returns the key of the set, fetching the set again if the key is unknown and the set is not too recent.
+// Tokens without a key ID are accepted if the set has a single key.
+func verificationKey(ctx context.Context, client *http.Client, u, kid string) (crypto.PublicKey, error) {
+	jwksCache.Lock()
+	set, ttl := jwksCache.sets[u], jwksCache.ttl
+	jwksCache.Unlock()
+
+	if set == nil || time.Since(set.fetched) > ttl {
+		var err error
+		if set, err = fetchKeySet(ctx, client, u); err != nil {
+			return nil, err
+		}
+	}
+	if key, ok := set.key(kid); ok {
+		return key, nil
+	}
+	if time.Since(set.fetched) < jwksMinRefresh {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	set, err := fetchKeySet(ctx, client, u)
+	if err != nil {
+		return nil, err
+	}
+	if key, ok := set.key(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+func (s *keySet) key(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// jsonWebKey is a public key of a JSON Web Key Set, see RFC 7517.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func fetchKeySet(ctx context.Context, client *http.Client, u string) (*keySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: unexpected status %s", u, resp.Status)
+	}
+
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxJWKSSize)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", u, err)
+	}
+
+	set := &keySet{keys: map[string]crypto.PublicKey{}, fetched: time.Now()}
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		// keys of unsupported types are skipped, tokens signed with them fail as signed with an unknown key
+		if key, err := k.publicKey(); err == nil {
+			set.keys[k.Kid] = key
+		}
+	}
+
+	jwksCache.Lock()
+	jwksCache.sets[u] = set
+	jwksCache.Unlock()
+	return set, nil
+}
+
+func (k *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		return k.ecdsaKey()
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if k.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func (k *jsonWebKey) ecdsaKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	var check ecdh.Curve
+	switch k.Crv {
+	case "P-256":
+		curve, check = elliptic.P256(), ecdh.P256()
+	case "P-384":
+		curve, check = elliptic.P384(), ecdh.P384()
+	case "P-521":
+		curve, check = elliptic.P521(), ecdh.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, err
+	}
+	size := (curve.Params().BitSize + 7) / 8
+	if len(x) != size || len(y) != size {
+		return nil, errors.New("invalid EC point")
+	}
+	// ecdh validates the point is on the curve
+	if _, err := check.NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
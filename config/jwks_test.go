@@ -0,0 +1,117 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func testJWKS(t *testing.T, kid string, key *ecdsa.PrivateKey) (*httptest.Server, *int) {
+	t.Helper()
+	fetches := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches++
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []jsonWebKey{{
+			Kty: "EC",
+			Kid: kid,
+			Crv: "P-256",
+			X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+			Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+		}}})
+	}))
+	t.Cleanup(s.Close)
+	t.Cleanup(func() {
+		jwksCache.Lock()
+		delete(jwksCache.sets, s.URL)
+		jwksCache.Unlock()
+	})
+	return s, &fetches
+}
+
+func signTestToken(t *testing.T, kid string, key *ecdsa.PrivateKey, expiresAt time.Time) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+		Subject:   "user@example.com",
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	})
+	token.Header["kid"] = kid
+	s, err := token.SignedString(key)
+	require.NoError(t, err)
+	return s
+}
+
+func TestProfile_VerifyToken(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	s, fetches := testJWKS(t, "key-1", key)
+
+	p := newTestProfile(t, "")
+	p.SetAccessToken(signTestToken(t, "key-1", key, time.Now().Add(time.Hour)))
+	_, err = p.VerifyToken(context.Background())
+	require.ErrorIs(t, err, ErrJWKSNotConfigured)
+
+	p.SetJWKSURL(s.URL)
+	claims, err := p.VerifyToken(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "user@example.com", claims.Subject)
+	_, err = p.VerifyToken(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, *fetches, "the key set is cached")
+
+	p.SetAccessToken(signTestToken(t, "key-1", key, time.Now().Add(-time.Minute)))
+	_, err = p.VerifyToken(context.Background())
+	require.ErrorIs(t, err, ErrInvalidToken, "expired")
+
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p.SetAccessToken(signTestToken(t, "key-1", other, time.Now().Add(time.Hour)))
+	_, err = p.VerifyToken(context.Background())
+	require.ErrorIs(t, err, ErrInvalidToken, "signed with another key")
+
+	p.SetAccessToken(signTestToken(t, "key-2", key, time.Now().Add(time.Hour)))
+	_, err = p.VerifyToken(context.Background())
+	require.ErrorIs(t, err, ErrInvalidToken, "unknown key")
+	require.Equal(t, 1, *fetches, "recently fetched sets are not fetched again for unknown keys")
+}
+
+func TestProfile_VerifyToken_Unsigned(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	s, _ := testJWKS(t, "key-1", key)
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.RegisteredClaims{Subject: "admin"}).
+		SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	p := newTestProfile(t, "")
+	p.SetJWKSURL(s.URL)
+	p.SetAccessToken(unsigned)
+	_, err = p.VerifyToken(context.Background())
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
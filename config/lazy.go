@@ -0,0 +1,67 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// SetLazyLoad defers reading the config file until a setting is first needed,
+// so commands that never read settings, like version or shell completion, skip the file I/O and parsing.
+// It must be called before loading the config. Errors reading the file are returned by EnsureLoaded and Save.
+func SetLazyLoad(v bool) { Default().SetLazyLoad(v) }
+func (p *Profile) SetLazyLoad(v bool) {
+	p.lazy = v
+}
+
+// EnsureLoaded reads the config file if its loading was deferred by SetLazyLoad and returns any error reading it.
+// It's safe to call from several goroutines, they all wait for the file to be read.
+func EnsureLoaded() error { return Default().EnsureLoaded() }
+func (p *Profile) EnsureLoaded() error {
+	p.lazyMu.Lock()
+	pending := p.loadPending.Swap(false)
+	if pending {
+		// nothing read while the lock is held may read settings back, they would call EnsureLoaded again
+		if p.lazyErr = p.scanPermissions(); p.lazyErr == nil {
+			p.lazyErr = p.readSettings()
+		}
+	}
+	err := p.lazyErr
+	p.lazyMu.Unlock()
+
+	if pending {
+		p.warnPermissions()
+		if err == nil {
+			p.checkSettings()
+		}
+	}
+	return err
+}
+
+// deferLoad makes EnsureLoaded read the config file.
+func (p *Profile) deferLoad() {
+	p.lazyMu.Lock()
+	defer p.lazyMu.Unlock()
+	p.loadPending.Store(true)
+	p.lazyErr = nil
+}
+
+// ensureLoaded is EnsureLoaded for accessors that can't return errors.
+func (p *Profile) ensureLoaded() {
+	_ = p.EnsureLoaded()
+}
+
+func (p *Profile) readConfigFile() error {
+	if err := p.checkPermissions(); err != nil {
+		return err
+	}
+	return p.readConfig()
+}
@@ -0,0 +1,83 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLazyTestProfile(t *testing.T) *Profile {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	p := &Profile{
+		name:      DefaultProfile,
+		configDir: "/atlascli",
+		fs:        afero.NewMemMapFs(),
+	}
+	p.SetLazyLoad(true)
+	require.NoError(t, p.load(false, AtlasCLIEnvPrefix))
+	return p
+}
+
+func TestProfile_SetLazyLoad(t *testing.T) {
+	p := newLazyTestProfile(t)
+	assert.False(t, p.loaded, "the file is not read when loading")
+
+	require.NoError(t, afero.WriteFile(p.fs, p.Filename(), []byte("[default]\n  org_id = \"a\"\n"), configPerm))
+	assert.Equal(t, "a", p.OrgID(), "the file is read on first access")
+	assert.True(t, p.loaded)
+
+	p.SetOrgID("b")
+	require.NoError(t, p.Save())
+	assert.Equal(t, "b", p.OrgID())
+}
+
+func TestProfile_SetLazyLoad_error(t *testing.T) {
+	p := newLazyTestProfile(t)
+	require.NoError(t, afero.WriteFile(p.fs, p.Filename(), []byte("[default\n"), configPerm))
+
+	assert.Empty(t, p.OrgID())
+	require.ErrorIs(t, p.EnsureLoaded(), ErrConfigCorrupted)
+	require.ErrorIs(t, p.Save(), ErrConfigCorrupted)
+}
+
+func TestProfile_SetLazyLoad_concurrent(t *testing.T) {
+	p := newLazyTestProfile(t)
+	l, buf := testLogger()
+	p.SetLogger(l)
+	require.NoError(t, afero.WriteFile(p.fs, p.Filename(), []byte("[default]\n  org_id = \"a\"\n"), 0644))
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, "a", p.OrgID(), "no goroutine sees the config before it's read")
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, strings.Count(buf.String(), "level=WARN"), "the permissions are checked once")
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+const DefaultMaxRequestsPerHost = 0 // DefaultMaxRequestsPerHost no limit
+
+// MaxRequestsPerHost get the configured limit of requests HttpClient sends to a host at the same time,
+// zero means no limit. Requests over the limit wait for another one to complete, until their context is done.
+// A request completes when its response body is closed.
+func MaxRequestsPerHost() int { return Default().MaxRequestsPerHost() }
+func (p *Profile) MaxRequestsPerHost() int {
+	return p.GetIntWithDefault(maxRequestsPerHost, DefaultMaxRequestsPerHost)
+}
+
+// SetMaxRequestsPerHost sets the limit of requests sent to a host at the same time.
+func SetMaxRequestsPerHost(v int) { Default().SetMaxRequestsPerHost(v) }
+func (p *Profile) SetMaxRequestsPerHost(v int) {
+	p.Set(maxRequestsPerHost, v)
+}
+
+// requestSlots are the semaphores limiting the requests per host, shared by every HttpClient
+// so the limit holds for consumers creating a client per goroutine.
+var requestSlots = struct {
+	sync.Mutex
+	hosts map[string]chan struct{}
+}{hosts: map[string]chan struct{}{}}
+
+// hostSlots returns the semaphore of a host, replacing it when the limit changed.
+func hostSlots(host string, limit int) chan struct{} {
+	requestSlots.Lock()
+	defer requestSlots.Unlock()
+	slots, ok := requestSlots.hosts[host]
+	if !ok || cap(slots) != limit {
+		slots = make(chan struct{}, limit)
+		requestSlots.hosts[host] = slots
+	}
+	return slots
+}
+
+type limitTransport struct {
+	base  http.RoundTripper
+	limit int
+}
+
+// limitTransport wraps base to limit the requests in flight per host, if a limit is set.
+func (p *Profile) limitTransport(base http.RoundTripper) http.RoundTripper {
+	limit := p.MaxRequestsPerHost()
+	if limit <= 0 {
+		return base
+	}
+	return &limitTransport{base: base, limit: limit}
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	slots := hostSlots(req.URL.Host, t.limit)
+	select {
+	case slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	release := sync.OnceFunc(func() { <-slots })
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+func (t *limitTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
+
+// releasingBody frees the slot of the request when the response body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TimeFormat defines how timestamps are printed in human readable output.
+type TimeFormat string
+
+const (
+	RFC3339Time  TimeFormat = "rfc3339"  // RFC3339Time prints timestamps in UTC as RFC 3339, this is the default
+	LocalTime    TimeFormat = "local"    // LocalTime prints timestamps in the local time zone with a layout based on the locale
+	RelativeTime TimeFormat = "relative" // RelativeTime prints how long ago or in how long, like 5 minutes ago
+)
+
+var ErrInvalidTimeFormat = errors.New("invalid time format, expected one of rfc3339, local or relative")
+
+// ParseTimeFormat parses one of rfc3339, local or relative, empty means rfc3339.
+func ParseTimeFormat(s string) (TimeFormat, error) {
+	switch f := TimeFormat(strings.ToLower(s)); f {
+	case "":
+		return RFC3339Time, nil
+	case RFC3339Time, LocalTime, RelativeTime:
+		return f, nil
+	default:
+		return RFC3339Time, fmt.Errorf("%w: %q", ErrInvalidTimeFormat, s)
+	}
+}
+
+// GetTimeFormat gets the configured time format, invalid values fall back to rfc3339.
+func GetTimeFormat() TimeFormat { return Default().TimeFormat() }
+func (p *Profile) TimeFormat() TimeFormat {
+	f, _ := ParseTimeFormat(p.GetString(timeFormat))
+	return f
+}
+
+// SetTimeFormat sets the time format.
+func SetTimeFormat(v TimeFormat) { Default().SetTimeFormat(v) }
+func (p *Profile) SetTimeFormat(v TimeFormat) {
+	p.Set(timeFormat, string(v))
+}
+
+// Locale gets the configured locale as a BCP 47 tag like en-US,
+// falling back to the LC_ALL, LC_TIME and LANG env vars. It's empty when none is set.
+func Locale() string { return Default().Locale() }
+func (p *Profile) Locale() string {
+	if v := p.GetString(locale); v != "" {
+		return normalizeLocale(v)
+	}
+	for _, env := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		if v := normalizeLocale(os.Getenv(env)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// SetLocale sets the locale.
+func SetLocale(v string) { Default().SetLocale(v) }
+func (p *Profile) SetLocale(v string) {
+	p.Set(locale, v)
+}
+
+// normalizeLocale turns POSIX locales like en_US.UTF-8 into BCP 47 tags, the C and POSIX locales are ignored.
+func normalizeLocale(s string) string {
+	s, _, _ = strings.Cut(s, ".")
+	s, _, _ = strings.Cut(s, "@")
+	if s == "C" || s == "POSIX" {
+		return ""
+	}
+	return strings.ReplaceAll(s, "_", "-")
+}
@@ -0,0 +1,47 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_TimeFormat(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  time_format = \"Relative\"\n")
+	assert.Equal(t, RelativeTime, p.TimeFormat())
+
+	p.SetTimeFormat("invalid")
+	assert.Equal(t, RFC3339Time, p.TimeFormat())
+
+	_, err := ParseTimeFormat("invalid")
+	require.ErrorIs(t, err, ErrInvalidTimeFormat)
+}
+
+func TestProfile_Locale(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_TIME", "C")
+	t.Setenv("LANG", "pt_BR.UTF-8")
+
+	p := newTestProfile(t, "")
+	assert.Equal(t, "pt-BR", p.Locale())
+
+	p.SetLocale("en_US")
+	assert.Equal(t, "en-US", p.Locale())
+}
@@ -0,0 +1,49 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"log/slog"
+)
+
+// discardHandler drops every record, it's the handler of the logger used until SetLogger is called.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
+
+var discardLogger = slog.New(discardHandler{})
+
+// SetLogger sets the logger receiving the warnings of the profile and of its HTTP client, like config files
+// with permissions that are too open, credentials that can't be decrypted or resolved and requests sent to
+// a fallback base URL. Set it before loading the config to get the warnings found while loading.
+// Nothing is logged by default, a nil logger restores that.
+func SetLogger(l *slog.Logger) { Default().SetLogger(l) }
+func (p *Profile) SetLogger(l *slog.Logger) {
+	p.log = l
+}
+
+// Logger returns the logger set with SetLogger, dropping the records below the log level of the profile's verbosity,
+// or a logger discarding everything. The verbosity is read once, call Logger again after changing it.
+func Logger() *slog.Logger { return Default().Logger() }
+func (p *Profile) Logger() *slog.Logger {
+	if p.log == nil {
+		return discardLogger
+	}
+	return slog.New(verbosityHandler{Handler: p.log.Handler(), level: p.Verbosity().LogLevel()})
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLogger() (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})), &buf
+}
+
+func TestProfile_Logger(t *testing.T) {
+	p := &Profile{configDir: "/atlascli", fs: afero.NewMemMapFs()}
+	assert.NotNil(t, p.Logger(), "nothing is logged by default")
+
+	l, buf := testLogger()
+	p.SetLogger(l)
+	require.NoError(t, p.fs.MkdirAll(p.configDir, defaultPermissions))
+	require.NoError(t, afero.WriteFile(p.fs, p.Filename(), []byte(""), 0644))
+	require.NoError(t, p.checkPermissions())
+	assert.Contains(t, buf.String(), "level=WARN")
+	assert.Contains(t, buf.String(), p.Filename())
+}
+
+func TestProfile_LoggerSecretReference(t *testing.T) {
+	fakeCommand(t, func(string, ...string) ([]byte, error) {
+		return nil, errors.New("item not found")
+	})
+	p := newTestProfile(t, "[default]\n  private_api_key = \"op://atlas/prod/missing\"\n")
+	l, buf := testLogger()
+	p.SetLogger(l)
+
+	require.Empty(t, p.PrivateAPIKey())
+	assert.Contains(t, buf.String(), "key=private_api_key")
+	assert.Contains(t, buf.String(), "item not found")
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// RequestMetrics describes a request sent by HttpClient, once its response headers are received or it failed.
+type RequestMetrics struct {
+	Method      string
+	Host        string
+	StatusCode  int           // StatusCode is 0 when no response was received
+	StatusClass string        // StatusClass is like "2xx" or "5xx", "error" when no response was received
+	Duration    time.Duration // Duration is the time until the response headers were received
+	Retries     int           // Retries is the number of times the request was sent again, like to a fallback base URL
+	Err         error
+}
+
+// TransportMetrics receives the metrics of the requests sent by HttpClient,
+// to export them to a monitoring system like Prometheus or StatsD.
+// RequestDone is called by the goroutine sending the request and must not block.
+type TransportMetrics interface {
+	RequestDone(m RequestMetrics)
+}
+
+// TransportMetricsFunc adapts a function to the TransportMetrics interface.
+type TransportMetricsFunc func(m RequestMetrics)
+
+func (f TransportMetricsFunc) RequestDone(m RequestMetrics) {
+	f(m)
+}
+
+// SetTransportMetrics sets where HttpClient reports the metrics of its requests, nil stops reporting them.
+func SetTransportMetrics(m TransportMetrics) { Default().SetTransportMetrics(m) }
+func (p *Profile) SetTransportMetrics(m TransportMetrics) {
+	p.metrics = m
+}
+
+type retriesKey struct{}
+
+// countRetry records that a request is sent again, for the metrics of the request.
+func countRetry(ctx context.Context) {
+	if retries, ok := ctx.Value(retriesKey{}).(*atomic.Int32); ok {
+		retries.Add(1)
+	}
+}
+
+func statusClass(code int) string {
+	if code == 0 {
+		return "error"
+	}
+	return strconv.Itoa(code/100) + "xx"
+}
+
+type metricsTransport struct {
+	base    http.RoundTripper
+	metrics TransportMetrics
+	now     func() time.Time
+}
+
+// metricsTransport wraps base to report the metrics of its requests, if SetTransportMetrics was called.
+func (p *Profile) metricsTransport(base http.RoundTripper) http.RoundTripper {
+	if p.metrics == nil {
+		return base
+	}
+	return &metricsTransport{base: base, metrics: p.metrics, now: time.Now}
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	retries := &atomic.Int32{}
+	start := t.now()
+	resp, err := t.base.RoundTrip(req.WithContext(context.WithValue(req.Context(), retriesKey{}, retries)))
+
+	m := RequestMetrics{
+		Method:   req.Method,
+		Host:     req.URL.Host,
+		Duration: t.now().Sub(start),
+		Retries:  int(retries.Load()),
+		Err:      err,
+	}
+	if resp != nil {
+		m.StatusCode = resp.StatusCode
+	}
+	m.StatusClass = statusClass(m.StatusCode)
+	t.metrics.RequestDone(m)
+	return resp, err
+}
+
+func (t *metricsTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_TransportMetrics(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer up.Close()
+
+	p := newTestProfile(t, "")
+	p.SetOpsManagerURL(downURL)
+	p.SetFallbackBaseURLs([]string{up.URL})
+	var got []RequestMetrics
+	p.SetTransportMetrics(TransportMetricsFunc(func(m RequestMetrics) {
+		got = append(got, m)
+	}))
+	client := p.HttpClient()
+
+	resp, err := client.Get(downURL + "/groups")
+	require.NoError(t, err)
+	resp.Body.Close()
+	resp, err = client.Get(up.URL + "/missing")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Len(t, got, 2)
+	u, err := url.Parse(downURL)
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodGet, got[0].Method)
+	assert.Equal(t, u.Host, got[0].Host)
+	assert.Equal(t, "2xx", got[0].StatusClass)
+	assert.Equal(t, 1, got[0].Retries)
+	assert.Positive(t, got[0].Duration)
+	assert.Equal(t, http.StatusNotFound, got[1].StatusCode)
+	assert.Equal(t, "4xx", got[1].StatusClass)
+	assert.Zero(t, got[1].Retries)
+
+	p.SetTransportMetrics(nil)
+	resp, err = p.HttpClient().Get(up.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Len(t, got, 2)
+}
+
+func TestStatusClass(t *testing.T) {
+	assert.Equal(t, "error", statusClass(0))
+	assert.Equal(t, "5xx", statusClass(http.StatusServiceUnavailable))
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// TokenPath is the OAuth token endpoint, relative to AuthURL.
+const TokenPath = "api/oauth/token"
+
+// AuthURL get the base URL of the OAuth endpoints, like https://cloud.mongodb.com/.
+// It defaults to the base URL of the profile for services supporting OAuth, and is empty for the others.
+// Set it for private identity deployments and test environments.
+func AuthURL() string { return Default().AuthURL() }
+func (p *Profile) AuthURL() string {
+	if v := p.GetString(authURL); v != "" {
+		if normalized, err := NormalizeBaseURL(v); err == nil {
+			return normalized
+		}
+		return v
+	}
+	if !p.CurrentService().Capabilities().OAuth {
+		return ""
+	}
+	return p.HttpBaseURL()
+}
+
+// SetAuthURL sets the base URL of the OAuth endpoints.
+func SetAuthURL(v string) { Default().SetAuthURL(v) }
+func (p *Profile) SetAuthURL(v string) {
+	p.Set(authURL, v)
+}
+
+// TokenURL get the OAuth token endpoint, TokenPath of AuthURL unless it's set.
+func TokenURL() string { return Default().TokenURL() }
+func (p *Profile) TokenURL() string {
+	if v := p.GetString(tokenURL); v != "" {
+		return v
+	}
+	if base := p.AuthURL(); base != "" {
+		return base + TokenPath
+	}
+	return ""
+}
+
+// SetTokenURL sets the OAuth token endpoint.
+func SetTokenURL(v string) { Default().SetTokenURL(v) }
+func (p *Profile) SetTokenURL(v string) {
+	p.Set(tokenURL, v)
+}
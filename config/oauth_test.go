@@ -0,0 +1,51 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_OAuthEndpoints(t *testing.T) {
+	p := newTestProfile(t, "")
+	require.Equal(t, "https://cloud.mongodb.com/", p.AuthURL())
+	require.Equal(t, "https://cloud.mongodb.com/api/oauth/token", p.TokenURL())
+
+	p.SetService(CloudGovService)
+	require.Equal(t, "https://cloud.mongodbgov.com/api/oauth/token", p.TokenURL())
+
+	p.SetService(CloudService)
+	p.SetOpsManagerURL("https://cloud-dev.mongodb.com")
+	require.Equal(t, "https://cloud-dev.mongodb.com/", p.AuthURL(), "test environments use their base URL")
+
+	p.SetAuthURL("https://login.example.com/oauth")
+	require.Equal(t, "https://login.example.com/oauth/", p.AuthURL())
+	require.Equal(t, "https://login.example.com/oauth/api/oauth/token", p.TokenURL())
+	p.SetTokenURL("https://login.example.com/token")
+	require.Equal(t, "https://login.example.com/token", p.TokenURL())
+
+	p.SetClientID("white-label")
+	require.Equal(t, "white-label", p.ClientID())
+
+	p.SetService(OpsManagerService)
+	p.SetAuthURL("")
+	p.SetTokenURL("")
+	require.Empty(t, p.AuthURL(), "no OAuth")
+	require.Empty(t, p.TokenURL())
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var ErrOffline = errors.New("offline mode is enabled")
+
+// OfflineError is returned instead of using the network in offline mode.
+type OfflineError struct {
+	Operation string // Operation is what needed the network, like fetching a URL
+}
+
+func (e *OfflineError) Error() string {
+	return fmt.Sprintf("%v: %s requires network access", ErrOffline, e.Operation)
+}
+
+func (*OfflineError) Unwrap() error {
+	return ErrOffline
+}
+
+// Offline get the offline mode, for air-gapped sites. In offline mode the package makes no network calls:
+// update checks and telemetry are disabled, secret references are only resolved by local resolvers,
+// and HttpClient fails every request with an OfflineError.
+// A value set in the profile takes precedence over the global one.
+func Offline() bool { return Default().Offline() }
+func (p *Profile) Offline() bool {
+	return p.GetBool(offline)
+}
+
+// SetOffline sets the global offline mode.
+func SetOffline(v bool) { Default().SetOffline(v) }
+func (p *Profile) SetOffline(v bool) {
+	p.SetGlobal(offline, v)
+}
+
+// SetProfileOffline sets the offline mode for this profile only.
+func SetProfileOffline(v bool) { Default().SetProfileOffline(v) }
+func (p *Profile) SetProfileOffline(v bool) {
+	p.Set(offline, v)
+}
+
+// CheckOnline returns an OfflineError for the operation in offline mode, for callers about to use the network.
+func CheckOnline(operation string) error { return Default().CheckOnline(operation) }
+func (p *Profile) CheckOnline(operation string) error {
+	if p.Offline() {
+		return &OfflineError{Operation: operation}
+	}
+	return nil
+}
+
+// offlineTransport fails every request, it's the transport of HttpClient in offline mode.
+type offlineTransport struct{}
+
+func (offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, &OfflineError{Operation: req.Method + " " + req.URL.Redacted()}
+}
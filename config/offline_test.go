@@ -0,0 +1,59 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_Offline(t *testing.T) {
+	calls := fakeCommand(t, func(string, ...string) ([]byte, error) { return []byte("secret"), nil })
+	p := newTestProfile(t, `offline = true
+
+[default]
+  public_api_key = "public"
+  private_api_key = "op://vault/atlas/private"
+  access_token = "pass://atlas/token"
+`)
+	require.True(t, p.Offline())
+	require.True(t, p.SkipUpdateCheck())
+	require.False(t, p.TelemetryEnabled())
+	require.Equal(t, TelemetryStatus{Enabled: false, Source: SourceGlobal}, p.TelemetryPolicy())
+
+	require.Empty(t, p.PrivateAPIKey(), "remote references are not resolved")
+	require.Equal(t, "secret", p.AccessToken(), "local references are resolved")
+	require.Equal(t, 1, *calls)
+	for _, ref := range p.ResolveSecrets() {
+		if ref.Key == privateAPIKey {
+			require.ErrorIs(t, ref.Err, ErrOffline)
+		}
+	}
+
+	_, err := p.HttpClient().Get("https://cloud.mongodb.com/api/atlas/v2")
+	var offlineErr *OfflineError
+	require.ErrorAs(t, err, &offlineErr)
+	require.Equal(t, "GET https://cloud.mongodb.com/api/atlas/v2", offlineErr.Operation)
+
+	require.ErrorIs(t, p.BootstrapFromURL(context.Background(), "https://example.com/atlascli.toml"), ErrOffline)
+
+	p.SetProfileOffline(false)
+	require.False(t, p.Offline(), "the profile takes precedence")
+	require.NoError(t, p.CheckOnline("anything"))
+}
@@ -0,0 +1,149 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// isOrgOverridable returns true for the settings that can have a default per organization, see SetOrgDefault.
+// The organization itself, credentials and the settings describing the profile can't.
+func isOrgOverridable(key string) bool {
+	switch key {
+	case orgID, orgs, identities, columns, inherits, readOnly, service, OpsManagerURLField:
+		return false
+	}
+	return slices.Contains(Properties(), key) && !slices.Contains(credentialProperties(), key)
+}
+
+// orgDefaults returns the defaults of an organization, the [<profile>.orgs.<org ID>] table of the config file.
+func (p *Profile) orgDefaults(org string) map[string]any {
+	if org == "" {
+		return nil
+	}
+	all, _ := p.lookup(orgs)
+	m, _ := all.(map[string]any)
+	defaults, _ := m[strings.ToLower(org)].(map[string]any)
+	return defaults
+}
+
+// orgValue returns the default of a setting for the organization unless it's set in the environment.
+func (p *Profile) orgValue(org, name string) (any, bool) {
+	if !isOrgOverridable(name) || p.isEnvSet(name) {
+		return nil, false
+	}
+	v, ok := p.orgDefaults(org)[name]
+	return v, ok && v != nil && v != ""
+}
+
+// currentOrg returns the organization of the profile when the setting can have a default per organization.
+func (p *Profile) currentOrg(name string) string {
+	if !isOrgOverridable(name) {
+		return ""
+	}
+	return p.OrgID()
+}
+
+// OrgDefault returns the default of a setting for an organization, nil when there's none.
+func OrgDefault(org, key string) any { return Default().OrgDefault(org, key) }
+func (p *Profile) OrgDefault(org, key string) any {
+	return p.orgDefaults(org)[strings.ToLower(key)]
+}
+
+// SetOrgDefault sets the default of a setting used while the organization is the profile's org_id,
+// like the project or the output format to use for each customer organization.
+// Flags and environment variables still take precedence over it.
+func SetOrgDefault(org, key string, value any) error { return Default().SetOrgDefault(org, key, value) }
+func (p *Profile) SetOrgDefault(org, key string, value any) error {
+	key = strings.ToLower(key)
+	if !IsValidOrgID(org) {
+		return fmt.Errorf("%w: %q", ErrInvalidOrgID, org)
+	}
+	if !isOrgOverridable(key) {
+		return fmt.Errorf("%w: %q can't be set per organization", ErrInvalidValueType, key)
+	}
+	if key == projectID {
+		if s, ok := value.(string); ok && s != "" && !IsValidProjectID(s) {
+			return fmt.Errorf("%w: %q", ErrInvalidProjectID, s)
+		}
+	}
+	p.setOrgDefaults(org, func(defaults map[string]any) {
+		defaults[key] = value
+	})
+	return nil
+}
+
+// DeleteOrgDefault removes the default of a setting for an organization.
+func DeleteOrgDefault(org, key string) { Default().DeleteOrgDefault(org, key) }
+func (p *Profile) DeleteOrgDefault(org, key string) {
+	p.setOrgDefaults(org, func(defaults map[string]any) {
+		delete(defaults, strings.ToLower(key))
+	})
+}
+
+func (p *Profile) setOrgDefaults(org string, edit func(map[string]any)) {
+	org = strings.ToLower(org)
+	all := map[string]any{}
+	if v, _ := p.lookup(orgs); v != nil {
+		m, _ := v.(map[string]any)
+		for k, v := range m {
+			all[k] = v
+		}
+	}
+	defaults := map[string]any{}
+	for k, v := range p.orgDefaults(org) {
+		defaults[k] = v
+	}
+	edit(defaults)
+	if len(defaults) == 0 {
+		delete(all, org)
+	} else {
+		all[org] = defaults
+	}
+	p.Set(orgs, all)
+}
+
+// OrgsWithDefaults returns the organizations that have defaults in the profile.
+func OrgsWithDefaults() []string { return Default().OrgsWithDefaults() }
+func (p *Profile) OrgsWithDefaults() []string {
+	all, _ := p.lookup(orgs)
+	m, _ := all.(map[string]any)
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// GetForOrg returns a setting as Get would if the organization was the profile's org_id.
+func GetForOrg(org, key string) any { return Default().GetForOrg(org, key) }
+func (p *Profile) GetForOrg(org, key string) any {
+	key = strings.ToLower(key)
+	if !isOrgOverridable(key) {
+		return p.Get(key)
+	}
+	return p.resolveSecret(key, p.valueFor(org, key))
+}
+
+// ProjectIDForOrg returns the project to use for an organization, its default project or the profile's one.
+func ProjectIDForOrg(org string) string { return Default().ProjectIDForOrg(org) }
+func (p *Profile) ProjectIDForOrg(org string) string {
+	v, _ := p.GetForOrg(org, projectID).(string)
+	return v
+}
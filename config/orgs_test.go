@@ -0,0 +1,88 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testOrgA     = "5e2211c17a3e5a48f5497de3"
+	testOrgB     = "5e2211c17a3e5a48f5497de4"
+	testProjectA = "6e2211c17a3e5a48f5497de3"
+)
+
+func TestProfile_OrgDefaults(t *testing.T) {
+	p := newTestProfile(t, `[default]
+  org_id = "`+testOrgA+`"
+  project_id = "6e2211c17a3e5a48f5497dff"
+  output = "plaintext"
+
+  [default.orgs.`+testOrgA+`]
+    project_id = "`+testProjectA+`"
+    output = "json"
+`)
+
+	assert.Equal(t, testProjectA, p.ProjectID())
+	assert.Equal(t, "json", p.Output())
+	assert.Equal(t, SourceOrg, p.Source(projectID))
+	assert.Equal(t, []string{testOrgA}, p.OrgsWithDefaults())
+
+	p.SetOrgID(testOrgB)
+	assert.Equal(t, "6e2211c17a3e5a48f5497dff", p.ProjectID(), "other organizations use the profile's settings")
+	assert.Equal(t, SourceSet, p.Source(orgID))
+	assert.Equal(t, SourceProfile, p.Source(projectID))
+	assert.Equal(t, testProjectA, p.ProjectIDForOrg(testOrgA))
+	assert.Equal(t, "json", p.GetForOrg(testOrgA, output))
+
+	t.Setenv("MONGODB_ATLAS_OUTPUT", "yaml")
+	viper.Reset()
+	p = &Profile{name: DefaultProfile, configDir: p.configDir, fs: p.fs}
+	require.NoError(t, p.load(true, AtlasCLIEnvPrefix))
+	assert.Equal(t, "yaml", p.Output(), "the environment takes precedence")
+	assert.Equal(t, testProjectA, p.ProjectID())
+}
+
+func TestProfile_SetOrgDefault(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  org_id = \""+testOrgA+"\"\n")
+
+	require.ErrorIs(t, p.SetOrgDefault("a", projectID, testProjectA), ErrInvalidOrgID)
+	require.ErrorIs(t, p.SetOrgDefault(testOrgA, projectID, "p"), ErrInvalidProjectID)
+	require.ErrorIs(t, p.SetOrgDefault(testOrgA, orgID, testOrgB), ErrInvalidValueType)
+	require.ErrorIs(t, p.SetOrgDefault(testOrgA, privateAPIKey, "secret"), ErrInvalidValueType)
+
+	require.NoError(t, p.SetOrgDefault(testOrgA, projectID, testProjectA))
+	require.NoError(t, p.SetOrgDefault(testOrgB, output, "json"))
+	require.NoError(t, p.Save())
+	assert.Equal(t, testProjectA, p.ProjectID())
+	assert.NotContains(t, p.Map(), orgs)
+
+	b, err := afero.ReadFile(p.fs, p.Filename())
+	require.NoError(t, err)
+	p = newTestProfile(t, string(b))
+	assert.Equal(t, testProjectA, p.ProjectID())
+	assert.Equal(t, "json", p.OrgDefault(testOrgB, output))
+
+	p.DeleteOrgDefault(testOrgA, projectID)
+	assert.Empty(t, p.ProjectID())
+	assert.Equal(t, []string{testOrgB}, p.OrgsWithDefaults())
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// OutputFormat is one of the formats commands can print results in, see OutputFormats.
+type OutputFormat string
+
+const (
+	PlaintextOutput  OutputFormat = "plaintext" // PlaintextOutput is a human readable table, this is the default
+	JSONOutput       OutputFormat = "json"
+	JSONPathOutput   OutputFormat = "json-path"   // JSONPathOutput prints the values selected by a path, like json-path=$.results[*].name
+	YAMLOutput       OutputFormat = "yaml"        // YAMLOutput prints YAML
+	GoTemplateOutput OutputFormat = "go-template" // GoTemplateOutput renders a Go template, like go-template={{.Name}}
+	tableOutput                   = "table"       // tableOutput is an alias of PlaintextOutput
+	jsonPathAlias                 = "jsonpath"    // jsonPathAlias is an alias of JSONPathOutput
+)
+
+var (
+	ErrInvalidOutputFormat   = errors.New("invalid output format")
+	ErrMissingOutputArgument = errors.New("output format requires an argument")
+)
+
+// OutputFormats returns the supported output formats.
+func OutputFormats() []OutputFormat {
+	return []OutputFormat{
+		PlaintextOutput,
+		JSONOutput,
+		JSONPathOutput,
+		YAMLOutput,
+		GoTemplateOutput,
+	}
+}
+
+// RequiresArgument returns true for formats configured as format=argument.
+func (f OutputFormat) RequiresArgument() bool {
+	return f == JSONPathOutput || f == GoTemplateOutput
+}
+
+func outputFormatsUsage() string {
+	usage := make([]string, 0, len(OutputFormats()))
+	for _, f := range OutputFormats() {
+		if f.RequiresArgument() {
+			usage = append(usage, string(f)+"=...")
+		} else {
+			usage = append(usage, string(f))
+		}
+	}
+	return strings.Join(usage, ", ")
+}
+
+// ParseOutputFormat parses an output setting, either a format name or format=argument for json-path and go-template.
+// An empty value is the default PlaintextOutput.
+func ParseOutputFormat(s string) (OutputFormat, string, error) {
+	name, arg, hasArg := strings.Cut(s, "=")
+	f := OutputFormat(strings.ToLower(strings.TrimSpace(name)))
+	switch f {
+	case "", tableOutput:
+		f = PlaintextOutput
+	case jsonPathAlias:
+		f = JSONPathOutput
+	case PlaintextOutput, JSONOutput, JSONPathOutput, YAMLOutput, GoTemplateOutput:
+	default:
+		return "", "", fmt.Errorf("%w: %q, expected one of %s", ErrInvalidOutputFormat, s, outputFormatsUsage())
+	}
+
+	if f.RequiresArgument() && arg == "" {
+		return "", "", fmt.Errorf("%w: use %s=...", ErrMissingOutputArgument, f)
+	}
+	if !f.RequiresArgument() && hasArg {
+		return "", "", fmt.Errorf("%w: %s doesn't take an argument", ErrInvalidOutputFormat, f)
+	}
+	return f, arg, nil
+}
+
+// GetOutputFormat gets the configured output format and its argument, the template or path.
+func GetOutputFormat() (OutputFormat, string, error) { return Default().OutputFormat() }
+func (p *Profile) OutputFormat() (OutputFormat, string, error) {
+	return ParseOutputFormat(p.Output())
+}
+
+// SetValidOutput sets the output format after checking it's supported.
+func SetValidOutput(v string) error { return Default().SetValidOutput(v) }
+func (p *Profile) SetValidOutput(v string) error {
+	if _, _, err := ParseOutputFormat(v); err != nil {
+		return err
+	}
+	p.SetOutput(v)
+	return nil
+}
+
+// SetOutputFormat stores the format and its argument, for example a Go template, as format=argument.
+func SetOutputFormat(f OutputFormat, arg string) error { return Default().SetOutputFormat(f, arg) }
+func (p *Profile) SetOutputFormat(f OutputFormat, arg string) error {
+	v := string(f)
+	if arg != "" {
+		v += "=" + arg
+	}
+	return p.SetValidOutput(v)
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOutputFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		format  OutputFormat
+		arg     string
+		wantErr error
+	}{
+		{input: "", format: PlaintextOutput},
+		{input: "table", format: PlaintextOutput},
+		{input: "JSON", format: JSONOutput},
+		{input: "yaml", format: YAMLOutput},
+		{input: "json-path=$.name", format: JSONPathOutput, arg: "$.name"},
+		{input: "jsonpath=$.results[*].name", format: JSONPathOutput, arg: "$.results[*].name"},
+		{input: "go-template={{.Name}}={{.ID}}", format: GoTemplateOutput, arg: "{{.Name}}={{.ID}}"},
+		{input: "go-template", wantErr: ErrMissingOutputArgument},
+		{input: "json=x", wantErr: ErrInvalidOutputFormat},
+		{input: "xml", wantErr: ErrInvalidOutputFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			format, arg, err := ParseOutputFormat(tt.input)
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.format, format)
+			assert.Equal(t, tt.arg, arg)
+		})
+	}
+
+	_, _, err := ParseOutputFormat("xml")
+	assert.ErrorContains(t, err, "plaintext, json, json-path=..., yaml, go-template=...")
+}
+
+func TestProfile_SetOutputFormat(t *testing.T) {
+	p := newTestProfile(t, "")
+
+	require.NoError(t, p.SetOutputFormat(GoTemplateOutput, "{{.ID}}"))
+	assert.Equal(t, "go-template={{.ID}}", p.Output())
+	format, arg, err := p.OutputFormat()
+	require.NoError(t, err)
+	assert.Equal(t, GoTemplateOutput, format)
+	assert.Equal(t, "{{.ID}}", arg)
+
+	require.ErrorIs(t, p.SetValidOutput("xml"), ErrInvalidOutputFormat)
+	assert.Equal(t, "go-template={{.ID}}", p.Output())
+}
@@ -0,0 +1,143 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// PermissionsPolicy defines what happens when the config file or directory are accessible by other users.
+type PermissionsPolicy int
+
+const (
+	PermissionsWarn   PermissionsPolicy = iota // PermissionsWarn reports a PermissionWarning, this is the default
+	PermissionsFix                             // PermissionsFix restricts the permissions and reports a PermissionWarning
+	PermissionsIgnore                          // PermissionsIgnore skips the check, for configs shared by service accounts
+)
+
+var ErrInvalidPermissionsPolicy = errors.New("invalid permissions policy")
+
+// ParsePermissionsPolicy parses one of warn, fix or ignore.
+func ParsePermissionsPolicy(s string) (PermissionsPolicy, error) {
+	switch strings.ToLower(s) {
+	case "warn":
+		return PermissionsWarn, nil
+	case "fix":
+		return PermissionsFix, nil
+	case "ignore":
+		return PermissionsIgnore, nil
+	default:
+		return PermissionsWarn, fmt.Errorf("%w: %q", ErrInvalidPermissionsPolicy, s)
+	}
+}
+
+// PermissionWarning describes a config file or directory with permissions that are too open.
+type PermissionWarning struct {
+	Path     string
+	Mode     fs.FileMode // Mode the path had when loading the config
+	Expected fs.FileMode
+	Fixed    bool // Fixed is true when the permissions were changed to Expected
+}
+
+func (w PermissionWarning) String() string {
+	if w.Fixed {
+		return fmt.Sprintf("permissions %#o for %q were too open, changed to %#o", w.Mode, w.Path, w.Expected)
+	}
+	return fmt.Sprintf("permissions %#o for %q are too open, it is recommended that they are not accessible by others (%#o)", w.Mode, w.Path, w.Expected)
+}
+
+// SetPermissionsPolicy sets what happens when loading a config with permissions that are too open.
+// ConfigPermissionsEnv takes precedence over this value.
+func SetPermissionsPolicy(v PermissionsPolicy) { Default().SetPermissionsPolicy(v) }
+func (p *Profile) SetPermissionsPolicy(v PermissionsPolicy) {
+	p.permissionsPolicy = v
+}
+
+// PermissionWarnings returns the permission issues found when loading the config.
+func PermissionWarnings() []PermissionWarning { return Default().PermissionWarnings() }
+func (p *Profile) PermissionWarnings() []PermissionWarning {
+	return p.permissionWarnings
+}
+
+func (p *Profile) effectivePermissionsPolicy() (PermissionsPolicy, error) {
+	if value, ok := os.LookupEnv(ConfigPermissionsEnv); ok {
+		return ParsePermissionsPolicy(value)
+	}
+	return p.permissionsPolicy, nil
+}
+
+// checkPermissions verifies the config directory is 0700 and the config file is 0600, similar to what ssh does with private keys.
+func (p *Profile) checkPermissions() error {
+	err := p.scanPermissions()
+	p.warnPermissions()
+	return err
+}
+
+// scanPermissions is checkPermissions without reporting the warnings, see warnPermissions.
+func (p *Profile) scanPermissions() error {
+	p.permissionWarnings = nil
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	policy, err := p.effectivePermissionsPolicy()
+	if err != nil || policy == PermissionsIgnore {
+		return err
+	}
+
+	for _, c := range []struct {
+		path     string
+		expected fs.FileMode
+	}{
+		{p.configDir, defaultPermissions},
+		{p.Filename(), configPerm},
+	} {
+		info, err := p.fs.Stat(c.path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		mode := info.Mode().Perm()
+		if mode&^c.expected == 0 {
+			continue
+		}
+
+		w := PermissionWarning{Path: c.path, Mode: mode, Expected: c.expected}
+		if policy == PermissionsFix {
+			if err := p.fs.Chmod(c.path, c.expected); err != nil {
+				return err
+			}
+			w.Fixed = true
+		}
+		p.permissionWarnings = append(p.permissionWarnings, w)
+	}
+
+	return nil
+}
+
+// warnPermissions reports the warnings found by scanPermissions, see Warnings.
+func (p *Profile) warnPermissions() {
+	for _, w := range p.permissionWarnings {
+		p.warn(Warning{Kind: WarningPermissions, Message: w.String()})
+	}
+}
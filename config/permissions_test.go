@@ -0,0 +1,101 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_checkPermissions(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       PermissionsPolicy
+		env          string
+		fileMode     fs.FileMode
+		wantWarnings int
+		wantMode     fs.FileMode
+	}{
+		{
+			name:         "private file",
+			fileMode:     0600,
+			wantWarnings: 0,
+			wantMode:     0600,
+		},
+		{
+			name:         "open file warns",
+			fileMode:     0644,
+			wantWarnings: 1,
+			wantMode:     0644,
+		},
+		{
+			name:         "open file is fixed",
+			policy:       PermissionsFix,
+			fileMode:     0644,
+			wantWarnings: 1,
+			wantMode:     0600,
+		},
+		{
+			name:         "env var overrides policy",
+			policy:       PermissionsFix,
+			env:          "ignore",
+			fileMode:     0644,
+			wantWarnings: 0,
+			wantMode:     0644,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv(ConfigPermissionsEnv, tt.env)
+			}
+			p := &Profile{
+				configDir:         "/atlascli",
+				fs:                afero.NewMemMapFs(),
+				permissionsPolicy: tt.policy,
+			}
+			require.NoError(t, p.fs.MkdirAll(p.configDir, defaultPermissions))
+			require.NoError(t, afero.WriteFile(p.fs, p.Filename(), []byte(""), tt.fileMode))
+
+			require.NoError(t, p.checkPermissions())
+			warnings := p.PermissionWarnings()
+			require.Len(t, warnings, tt.wantWarnings)
+			if tt.wantWarnings > 0 {
+				assert.Equal(t, p.Filename(), warnings[0].Path)
+				assert.Equal(t, tt.fileMode, warnings[0].Mode)
+				assert.Equal(t, tt.policy == PermissionsFix, warnings[0].Fixed)
+			}
+
+			info, err := p.fs.Stat(p.Filename())
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMode, info.Mode().Perm())
+		})
+	}
+}
+
+func TestParsePermissionsPolicy(t *testing.T) {
+	got, err := ParsePermissionsPolicy("Fix")
+	require.NoError(t, err)
+	assert.Equal(t, PermissionsFix, got)
+
+	_, err = ParsePermissionsPolicy("chmod")
+	require.ErrorIs(t, err, ErrInvalidPermissionsPolicy)
+}
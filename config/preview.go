@@ -0,0 +1,80 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// previewAccept is the Accept header prefix of the preview versions of the Atlas Admin API resources.
+const previewAccept = "application/vnd.atlas.preview"
+
+var ErrPreviewDisabled = errors.New("preview features are disabled")
+
+// PreviewDisabledError is returned by HttpClient for requests to preview API resources when PreviewFeatures is false.
+type PreviewDisabledError struct {
+	URL string
+}
+
+func (e *PreviewDisabledError) Error() string {
+	return fmt.Sprintf("%v: %s requests a preview API version, set %s = true in the profile to use it", ErrPreviewDisabled, e.URL, previewFeatures)
+}
+
+func (*PreviewDisabledError) Unwrap() error {
+	return ErrPreviewDisabled
+}
+
+// PreviewFeatures get whether the profile opts in to preview API resource versions and experimental behaviors.
+// Applications should check it before enabling their experimental behaviors,
+// and HttpClient refuses requests for preview resource versions unless it's true.
+func PreviewFeatures() bool { return Default().PreviewFeatures() }
+func (p *Profile) PreviewFeatures() bool {
+	return p.GetBool(previewFeatures)
+}
+
+// SetPreviewFeatures sets whether the profile opts in to preview features.
+func SetPreviewFeatures(v bool) { Default().SetPreviewFeatures(v) }
+func (p *Profile) SetPreviewFeatures(v bool) {
+	p.Set(previewFeatures, v)
+}
+
+type previewTransport struct {
+	base http.RoundTripper
+}
+
+// previewTransport wraps base to refuse requests for preview resource versions, unless the profile opts in.
+func (p *Profile) previewTransport(base http.RoundTripper) http.RoundTripper {
+	if p.PreviewFeatures() {
+		return base
+	}
+	return previewTransport{base: base}
+}
+
+func (t previewTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.HasPrefix(req.Header.Get("Accept"), previewAccept) {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, &PreviewDisabledError{URL: req.URL.Redacted()}
+	}
+	return t.base.RoundTrip(req)
+}
+
+func (t previewTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
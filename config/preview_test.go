@@ -0,0 +1,55 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_PreviewFeatures(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer s.Close()
+
+	p := NewEphemeralProfile(map[string]any{"service": CloudService})
+	assert.False(t, p.PreviewFeatures())
+
+	do := func() error {
+		req, err := http.NewRequest(http.MethodGet, s.URL+"/api/atlas/v2/groups", nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "application/vnd.atlas.preview+json")
+		resp, err := p.HttpClient().Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	var previewErr *PreviewDisabledError
+	require.ErrorAs(t, do(), &previewErr)
+	require.ErrorIs(t, do(), ErrPreviewDisabled)
+
+	resp, err := p.HttpClient().Get(s.URL + "/api/atlas/v2/groups")
+	require.NoError(t, err, "stable versions are allowed")
+	resp.Body.Close()
+
+	p.SetPreviewFeatures(true)
+	require.NoError(t, do())
+}
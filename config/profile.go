@@ -15,18 +15,32 @@
 package config
 
 import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
 	"net/http"
+	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
-	"github.com/mongodb-forks/digest"
+	"github.com/mongodb/atlas-cli-core/environment"
 	"github.com/pelletier/go-toml"
 	"github.com/spf13/afero"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"go.mongodb.org/atlas/auth"
 )
@@ -37,13 +51,45 @@ var (
 
 var (
 	ErrProfileNameHasDots = errors.New("profile should not contain '.'")
+	ErrConfigFileModified = errors.New("config value was modified by another process since it was loaded")
+	ErrProfileReadOnly    = errors.New("profile is read only")
+	ErrInvalidValueType   = errors.New("invalid value type")
 )
 
 type Profile struct {
-	name      string
-	configDir string
-	fs        afero.Fs
-	err       error
+	name       string
+	configDir  string
+	fs         afero.Fs
+	err        error
+	loaded     bool
+	loadedHash string              // hash of the config file when it was last read or written, empty if it didn't exist
+	snapshot   *viper.Viper        // contents of the config file when it was last read or written
+	dirty      map[string]struct{} // keys changed by this process that still need to be saved
+
+	permissionsPolicy  PermissionsPolicy
+	permissionWarnings []PermissionWarning
+	integrityKey       []byte
+	bootstrapKeys      []ed25519.PublicKey
+	readOnlyErr        error  // set when a change was refused because the profile is read only, reported by Save
+	envPrefix          string // envPrefix of the environment variables read when loading, empty when they are not read
+	customEnvPrefix    string
+	envAliases         map[string][]string
+	credentialFiles    []string // credentialFiles are the settings read from the systemd credentials directory
+	envKeyReplacer     *strings.Replacer
+	lazy               bool        // lazy defers reading the config file until a setting is needed, see SetLazyLoad
+	lazyMu             sync.Mutex  // lazyMu guards the deferred read of the config file, see EnsureLoaded
+	loadPending        atomic.Bool // loadPending is true while a lazy load hasn't read the config file yet
+	lazyErr            error       // lazyErr is the error of the deferred read
+	cache              atomic.Pointer[settingsCache]
+	secrets            *secretCache   // secrets read from the secret store, see SetSecretStore
+	memory             map[string]any // memory holds the settings of ephemeral profiles, see NewEphemeralProfile
+	state              *cachedState   // state of the profile read from the state file, see StateFilename
+	hooks              []*registeredHook
+	log                *slog.Logger // log receives the warnings, see SetLogger
+	warnings           warningList
+	metrics            TransportMetrics
+	transportWrapper   TransportWrapper
+	flags              map[string]*pflag.Flag
 }
 
 func Default() *Profile {
@@ -61,6 +107,25 @@ func newProfile() *Profile {
 	return np
 }
 
+// sibling returns a new profile with the given name backed by the same config file and options as p.
+func (p *Profile) sibling(name string) *Profile {
+	return &Profile{
+		name:              name,
+		configDir:         p.configDir,
+		fs:                p.fs,
+		err:               p.err,
+		permissionsPolicy: p.permissionsPolicy,
+		integrityKey:      p.integrityKey,
+		bootstrapKeys:     p.bootstrapKeys,
+		customEnvPrefix:   p.customEnvPrefix,
+		envAliases:        maps.Clone(p.envAliases),
+		secrets:           p.secrets,
+		log:               p.log,
+		metrics:           p.metrics,
+		transportWrapper:  p.transportWrapper,
+	}
+}
+
 func Name() string { return Default().Name() }
 func (p *Profile) Name() string {
 	return p.name
@@ -87,32 +152,164 @@ func (p *Profile) SetName(name string) error {
 
 func Set(name string, value any) { Default().Set(name, value) }
 func (p *Profile) Set(name string, value any) {
+	if p.IsEphemeral() {
+		old := p.memory[name]
+		p.memory[name] = value
+		p.notifyChange(name, old, value)
+		return
+	}
+	if err := p.checkWritable(); err != nil {
+		p.readOnlyErr = err
+		return
+	}
 	settings := viper.GetStringMap(p.Name())
+	old := settings[name]
 	settings[name] = value
 	viper.Set(p.name, settings)
+	invalidateSettings()
+	indexProfile(p.name)
+	p.markDirty(p.name + "." + name)
+	p.notifyChange(name, old, value)
 }
 
-func SetGlobal(name string, value any) { viper.Set(name, value) }
-func (*Profile) SetGlobal(name string, value any) {
-	SetGlobal(name, value)
+func SetGlobal(name string, value any) { Default().SetGlobal(name, value) }
+func (p *Profile) SetGlobal(name string, value any) {
+	if p.IsEphemeral() {
+		old := p.memory[name]
+		p.memory[name] = value
+		p.notifyChange(name, old, value)
+		return
+	}
+	p.ensureLoaded()
+	if p.isFileReadOnly() {
+		p.readOnlyErr = fmt.Errorf("%w: %s is not writable", ErrProfileReadOnly, p.Filename())
+		return
+	}
+	old := viper.Get(name)
+	viper.Set(name, value)
+	invalidateSettings()
+	p.markDirty(name)
+	p.notifyChange(name, old, value)
+}
+
+func (p *Profile) markDirty(key string) {
+	if p.dirty == nil {
+		p.dirty = map[string]struct{}{}
+	}
+	p.dirty[strings.ToLower(key)] = struct{}{}
 }
 
+// Get returns the effective value of a setting, or its default value when it isn't set anywhere, see RegisterDefault.
 func Get(name string) any { return Default().Get(name) }
 func (p *Profile) Get(name string) any {
-	if viper.IsSet(name) && viper.Get(name) != "" {
-		return viper.Get(name)
+	return p.resolveSecret(name, p.value(name))
+}
+
+// value returns a setting as configured, before decrypting credentials or resolving their secret references.
+func (p *Profile) value(name string) any {
+	p.ensureLoaded()
+	return p.valueFor(p.currentOrg(name), name)
+}
+
+// valueFor returns a setting as configured when org is the organization of the profile,
+// or its default value, see RegisterDefault.
+func (p *Profile) valueFor(org, name string) any {
+	if v := p.configuredValue(org, name); v != nil {
+		return v
 	}
-	settings := viper.GetStringMap(p.Name())
-	return settings[name]
+	v, _ := DefaultValue(name)
+	return v
 }
 
+// configuredValue returns a setting as configured when org is the organization of the profile, nil when not set.
+func (p *Profile) configuredValue(org, name string) any {
+	if v, ok := p.flagValue(name); ok {
+		return v
+	}
+	if v, ok := p.pushedProject(name); ok {
+		return v
+	}
+	if v, ok := p.orgValue(org, name); ok {
+		return v
+	}
+	if p.IsEphemeral() {
+		return p.memoryValue(name)
+	}
+	if p.isProfileOverride(name) {
+		value, _ := p.lookup(name)
+		return value
+	}
+	if v := viper.Get(name); v != nil && v != "" {
+		return v
+	}
+	if value, _ := p.lookup(name); value != nil {
+		return value
+	}
+	if v, ok := p.storedSecret(name); ok {
+		return v
+	}
+	if v, ok := p.flagDefault(name); ok {
+		return v
+	}
+	return nil
+}
+
+// ConversionError is returned when a setting can't be converted to the requested type.
+type ConversionError struct {
+	Key   string
+	Value any
+	Type  string
+}
+
+func (e *ConversionError) Error() string {
+	return fmt.Sprintf("%v: %s is %T, expected %s", ErrInvalidValueType, e.Key, e.Value, e.Type)
+}
+
+func (*ConversionError) Unwrap() error {
+	return ErrInvalidValueType
+}
+
+// GetString returns a setting as a string, numbers and booleans are formatted
+// and values that can't be converted are returned as an empty string.
 func GetString(name string) string { return Default().GetString(name) }
 func (p *Profile) GetString(name string) string {
-	value := p.Get(name)
-	if value == nil {
-		return ""
+	value, _ := p.GetStringErr(name)
+	return value
+}
+
+// GetStringErr returns a setting as a string, numbers and booleans are formatted
+// and values that can't be converted, like tables or arrays, return a ConversionError.
+func GetStringErr(name string) (string, error) { return Default().GetStringErr(name) }
+func (p *Profile) GetStringErr(name string) (string, error) {
+	return formatSetting(name, p.Get(name))
+}
+
+// formatSetting formats a setting as a string, see GetStringErr.
+func formatSetting(name string, value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case time.Duration:
+		return v.String(), nil
+	default:
+		return "", &ConversionError{Key: name, Value: v, Type: "string"}
 	}
-	return value.(string)
 }
 
 func GetBool(name string) bool { return Default().GetBool(name) }
@@ -131,20 +328,49 @@ func (p *Profile) GetBoolWithDefault(name string, defaultValue bool) bool {
 	}
 }
 
+// GetIntWithDefault returns an integer setting, or the default value when it's not set or not valid.
+func (p *Profile) GetIntWithDefault(name string, defaultValue int) int {
+	switch v := p.Get(name).(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case string:
+		if i, err := strconv.Atoi(v); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
+// GetDurationWithDefault returns a duration setting, for example "30s" or "2m", numbers are seconds.
+// The default value is returned when the setting is not set or not valid.
+func (p *Profile) GetDurationWithDefault(name string, defaultValue time.Duration) time.Duration {
+	switch v := p.Get(name).(type) {
+	case time.Duration:
+		return v
+	case int64:
+		return time.Duration(v) * time.Second
+	case int:
+		return time.Duration(v) * time.Second
+	case string:
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
 // Service get configured service.
 func Service() string { return Default().Service() }
 func (p *Profile) Service() string {
-	if viper.IsSet(service) {
-		return viper.GetString(service)
-	}
-
-	settings := viper.GetStringMapString(p.Name())
-	return settings[service]
+	return p.GetString(service)
 }
 
-func IsCloud() bool {
-	profile := Default()
-	return profile.Service() == "" || profile.Service() == CloudService || profile.Service() == CloudGovService
+// IsCloud returns true when the profile targets Atlas, commercial or for Government.
+func IsCloud() bool { return Default().IsCloud() }
+func (p *Profile) IsCloud() bool {
+	return p.CurrentService().IsCloud()
 }
 
 // SetService set configured service.
@@ -178,8 +404,12 @@ func (p *Profile) SetPrivateAPIKey(v string) {
 }
 
 // AccessToken get configured access token.
+// An access token set in the environment isn't returned after the AccessTokenExpiryEnv time.
 func AccessToken() string { return Default().AccessToken() }
 func (p *Profile) AccessToken() string {
+	if p.isAccessTokenEnvExpired() {
+		return ""
+	}
 	return p.GetString(AccessTokenField)
 }
 
@@ -264,9 +494,14 @@ func (p *Profile) tokenClaims() (jwt.RegisteredClaims, error) {
 }
 
 // OpsManagerURL get configured ops manager base url.
+// Valid URLs are returned normalized, see NormalizeBaseURL.
 func OpsManagerURL() string { return Default().OpsManagerURL() }
 func (p *Profile) OpsManagerURL() string {
-	return p.GetString(OpsManagerURLField)
+	v := p.GetString(OpsManagerURLField)
+	if normalized, err := NormalizeBaseURL(v); err == nil {
+		return normalized
+	}
+	return v
 }
 
 // SetOpsManagerURL set configured ops manager base url.
@@ -299,38 +534,72 @@ func (p *Profile) SetOrgID(v string) {
 	p.Set(orgID, v)
 }
 
-// SkipUpdateCheck get the global skip update check.
+// isProfileOverride returns true when a profile overridable property is set in the profile, or one it inherits from,
+// and not in the environment.
+func (p *Profile) isProfileOverride(name string) bool {
+	if !slices.Contains(ProfileOverridableProperties(), name) || p.isEnvSet(name) {
+		return false
+	}
+	value, _ := p.lookup(name)
+	return value != nil
+}
+
+// SkipUpdateCheck get the skip update check, a value set in the profile takes precedence over the global one.
 func SkipUpdateCheck() bool { return Default().SkipUpdateCheck() }
 func (p *Profile) SkipUpdateCheck() bool {
-	return p.GetBool(skipUpdateCheck)
+	return p.Offline() || p.GetBool(skipUpdateCheck)
 }
 
 // SetSkipUpdateCheck sets the global skip update check.
 func SetSkipUpdateCheck(v bool) { Default().SetSkipUpdateCheck(v) }
-func (*Profile) SetSkipUpdateCheck(v bool) {
-	SetGlobal(skipUpdateCheck, v)
+func (p *Profile) SetSkipUpdateCheck(v bool) {
+	p.SetGlobal(skipUpdateCheck, v)
+}
+
+// SetProfileSkipUpdateCheck sets the skip update check for this profile only.
+func SetProfileSkipUpdateCheck(v bool) { Default().SetProfileSkipUpdateCheck(v) }
+func (p *Profile) SetProfileSkipUpdateCheck(v bool) {
+	p.Set(skipUpdateCheck, v)
 }
 
-// IsTelemetryEnabledSet return true if telemetry_enabled has been set.
+// IsTelemetryEnabledSet return true if telemetry_enabled has been set, globally or in the profile.
 func IsTelemetryEnabledSet() bool { return Default().IsTelemetryEnabledSet() }
-func (*Profile) IsTelemetryEnabledSet() bool {
-	return viper.IsSet(TelemetryEnabledProperty)
+func (p *Profile) IsTelemetryEnabledSet() bool {
+	p.ensureLoaded()
+	if !p.IsEphemeral() && viper.IsSet(TelemetryEnabledProperty) {
+		return true
+	}
+	value, _ := p.lookup(TelemetryEnabledProperty)
+	return value != nil
 }
 
 // TelemetryEnabled get the configured telemetry enabled value.
+// DO_NOT_TRACK takes precedence, then a value set in the profile over the global one, see ProfileOverridableProperties.
 func TelemetryEnabled() bool { return Default().TelemetryEnabled() }
 func (p *Profile) TelemetryEnabled() bool {
-	return isTelemetryFeatureAllowed() && p.GetBoolWithDefault(TelemetryEnabledProperty, true)
+	return isTelemetryFeatureAllowed() && !p.Offline() && p.GetBoolWithDefault(TelemetryEnabledProperty, true)
 }
 
 // SetTelemetryEnabled sets the telemetry enabled value.
-func SetTelemetryEnabled(v bool) { Default().SetTelemetryEnabled(v) }
+// Enabling telemetry while DO_NOT_TRACK is set is not saved and returns a TelemetryOverriddenError.
+func SetTelemetryEnabled(v bool) error { return Default().SetTelemetryEnabled(v) }
+func (p *Profile) SetTelemetryEnabled(v bool) error {
+	if v && !isTelemetryFeatureAllowed() {
+		return &TelemetryOverriddenError{Source: SourceDoNotTrack}
+	}
+	p.SetGlobal(TelemetryEnabledProperty, v)
+	return nil
+}
 
-func (*Profile) SetTelemetryEnabled(v bool) {
-	if !isTelemetryFeatureAllowed() {
-		return
+// SetProfileTelemetryEnabled sets the telemetry enabled value for this profile only.
+// Enabling telemetry while DO_NOT_TRACK is set is not saved and returns a TelemetryOverriddenError.
+func SetProfileTelemetryEnabled(v bool) error { return Default().SetProfileTelemetryEnabled(v) }
+func (p *Profile) SetProfileTelemetryEnabled(v bool) error {
+	if v && !isTelemetryFeatureAllowed() {
+		return &TelemetryOverriddenError{Source: SourceDoNotTrack}
 	}
-	SetGlobal(TelemetryEnabledProperty, v)
+	p.Set(TelemetryEnabledProperty, v)
+	return nil
 }
 
 // Output get configured output format.
@@ -345,12 +614,87 @@ func (p *Profile) SetOutput(v string) {
 	p.Set(output, v)
 }
 
-// ClientID get configured output format.
+// ColorMode defines when output is colorized.
+type ColorMode string
+
+const (
+	ColorAuto   ColorMode = "auto"   // ColorAuto colorizes terminals unless NO_COLOR is set, this is the default
+	ColorAlways ColorMode = "always" // ColorAlways colorizes any output, even when redirected
+	ColorNever  ColorMode = "never"
+)
+
+var ErrInvalidColorMode = errors.New("invalid color mode, expected one of auto, always or never")
+
+// ParseColorMode parses one of auto, always or never, empty means auto.
+func ParseColorMode(s string) (ColorMode, error) {
+	switch m := ColorMode(strings.ToLower(s)); m {
+	case "":
+		return ColorAuto, nil
+	case ColorAuto, ColorAlways, ColorNever:
+		return m, nil
+	default:
+		return ColorAuto, fmt.Errorf("%w: %q", ErrInvalidColorMode, s)
+	}
+}
+
+// Color gets the configured color mode, invalid values fall back to auto.
+func Color() ColorMode { return Default().Color() }
+func (p *Profile) Color() ColorMode {
+	m, _ := ParseColorMode(p.GetString(color))
+	return m
+}
+
+// SetColor sets the color mode.
+func SetColor(v ColorMode) { Default().SetColor(v) }
+func (p *Profile) SetColor(v ColorMode) {
+	p.Set(color, string(v))
+}
+
+// ShouldColorize returns true when output written to w should be colorized.
+// With the auto color mode that is when w is a terminal, NO_COLOR is not set and TERM is not dumb.
+func ShouldColorize(w io.Writer) bool { return Default().ShouldColorize(w) }
+func (p *Profile) ShouldColorize(w io.Writer) bool {
+	switch p.Color() {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if os.Getenv(NoColorEnv) != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && environment.IsTerminal(f)
+}
+
+// Pager gets the configured pager command, falling back to the PAGER env var.
+func Pager() string { return Default().Pager() }
+func (p *Profile) Pager() string {
+	if v := p.GetString(pager); v != "" {
+		return v
+	}
+	return os.Getenv(PagerEnv)
+}
+
+// SetPager sets the pager command.
+func SetPager(v string) { Default().SetPager(v) }
+func (p *Profile) SetPager(v string) {
+	p.Set(pager, v)
+}
+
+// ClientID get the configured OAuth client ID.
 func ClientID() string { return Default().ClientID() }
 func (p *Profile) ClientID() string {
 	return p.GetString(ClientIDField)
 }
 
+// SetClientID sets the OAuth client ID.
+func SetClientID(v string) { Default().SetClientID(v) }
+func (p *Profile) SetClientID(v string) {
+	p.Set(ClientIDField, v)
+}
+
 // IsAccessSet return true if API keys have been set up.
 // For Ops Manager we also check for the base URL.
 func IsAccessSet() bool { return Default().IsAccessSet() }
@@ -360,16 +704,25 @@ func (p *Profile) IsAccessSet() bool {
 	return isSet
 }
 
-// Map returns a map describing the configuration.
+// Map returns a map describing the configuration, including the settings inherited from other profiles.
+// Use InheritedFrom to know which profile an inherited setting comes from.
 func Map() map[string]string { return Default().Map() }
 func (p *Profile) Map() map[string]string {
-	settings := viper.GetStringMapString(p.Name())
-	profileSettings := make(map[string]string, len(settings)+1)
-	for k, v := range settings {
-		if k == privateAPIKey || k == AccessTokenField || k == RefreshTokenField {
-			profileSettings[k] = "redacted"
-		} else {
-			profileSettings[k] = v
+	profileSettings := map[string]string{}
+	for i, name := range p.chain() {
+		settings := viper.GetStringMapString(name)
+		if p.IsEphemeral() {
+			settings = memoryStringSettings(p.profileSettings(name))
+		}
+		for k, v := range settings {
+			if _, ok := profileSettings[k]; ok || (i > 0 && !isInheritable(k)) || k == orgs || k == identities || k == columns {
+				continue
+			}
+			if k == privateAPIKey || k == AccessTokenField || k == RefreshTokenField {
+				profileSettings[k] = redacted
+			} else {
+				profileSettings[k] = v
+			}
 		}
 	}
 
@@ -388,26 +741,77 @@ func (p *Profile) SortedKeys() []string {
 	return keys
 }
 
+// IsReadOnly returns true when the profile is provisioned by configuration management,
+// either by setting read_only = true or by making the config file not writable.
+// Set, Rename and Delete refuse to modify a read only profile and return ErrProfileReadOnly.
+func IsReadOnly() bool { return Default().IsReadOnly() }
+func (p *Profile) IsReadOnly() bool {
+	return p.GetBool(readOnly) || p.isFileReadOnly()
+}
+
+func (p *Profile) isFileReadOnly() bool {
+	info, err := p.fs.Stat(p.Filename())
+	return err == nil && info.Mode().Perm()&0200 == 0
+}
+
+func (p *Profile) checkWritable() error {
+	if p.IsReadOnly() {
+		return fmt.Errorf("%w: %q", ErrProfileReadOnly, p.Name())
+	}
+	return nil
+}
+
 // Delete deletes an existing configuration. The profiles are reloaded afterwards, as
 // this edits the file directly.
 func Delete() error { return Default().Delete() }
 func (p *Profile) Delete() error {
-	// Configuration needs to be deleted from toml, as viper doesn't support this yet.
-	// FIXME :: change when https://github.com/spf13/viper/pull/519 is merged.
-	settings := viper.AllSettings()
+	if p.IsEphemeral() {
+		return ErrEphemeralProfile
+	}
+	if err := p.checkWritable(); err != nil {
+		return err
+	}
 
-	t, err := toml.TreeFromMap(settings)
+	name := p.Name()
+	err := p.editFile(func(contents string) (string, bool) {
+		updated := removeTables(contents, name)
+		return updated, !hasProfile(updated, name)
+	}, func(t *toml.Tree) error {
+		return t.Delete(name)
+	})
 	if err != nil {
 		return err
 	}
+	return p.renameState("")
+}
 
-	// Delete from the toml manually
-	err = t.Delete(p.Name())
-	if err != nil {
+// editFile changes the config file with edit, which only touches the lines of the profile,
+// and falls back to rewriting the whole file with rewrite when edit can't handle it,
+// like for profiles declared with dotted keys or inline tables.
+func (p *Profile) editFile(edit func(string) (string, bool), rewrite func(*toml.Tree) error) error {
+	if err := p.verifyIntegrity(); err != nil {
 		return err
 	}
+	b, err := afero.ReadFile(p.fs, p.Filename())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	contents := string(b)
 
-	s := t.String()
+	updated, ok := edit(contents)
+	if !ok {
+		t, err := toml.Load(contents)
+		if err != nil {
+			return err
+		}
+		if err := rewrite(t); err != nil {
+			return err
+		}
+		updated = t.String()
+	}
+	if updated == contents {
+		return nil
+	}
 
 	f, err := p.fs.OpenFile(p.Filename(), fileFlags, configPerm)
 	if err != nil {
@@ -415,8 +819,11 @@ func (p *Profile) Delete() error {
 	}
 	defer f.Close()
 
-	_, err = f.WriteString(s)
-	return err
+	if _, err := f.WriteString(updated); err != nil {
+		return err
+	}
+
+	return p.fileWritten()
 }
 
 func (p *Profile) Filename() string {
@@ -430,51 +837,66 @@ func Filename() string {
 // Rename replaces the Profile to a new Profile name, overwriting any Profile that existed before.
 func Rename(newProfileName string) error { return Default().Rename(newProfileName) }
 func (p *Profile) Rename(newProfileName string) error {
+	if p.IsEphemeral() {
+		return ErrEphemeralProfile
+	}
 	if err := validateName(newProfileName); err != nil {
 		return err
 	}
-
-	// Configuration needs to be deleted from toml, as viper doesn't support this yet.
-	// FIXME :: change when https://github.com/spf13/viper/pull/519 is merged.
-	configurationAfterDelete := viper.AllSettings()
-
-	t, err := toml.TreeFromMap(configurationAfterDelete)
-	if err != nil {
+	if err := p.checkWritable(); err != nil {
 		return err
 	}
 
-	t.Set(newProfileName, t.Get(p.Name()))
-
-	err = t.Delete(p.Name())
-	if err != nil {
-		return err
+	name := p.Name()
+	newName := strings.ToLower(newProfileName)
+	if name == newName {
+		return nil
 	}
-
-	s := t.String()
-
-	f, err := p.fs.OpenFile(p.Filename(), fileFlags, configPerm)
-	if err != nil {
-		return err
+	// the profile being replaced must be writable too
+	if p.Exists(newName) {
+		if err := p.sibling(newName).checkWritable(); err != nil {
+			return err
+		}
 	}
-	defer f.Close()
-
-	if _, err := f.WriteString(s); err != nil {
+	err := p.editFile(func(contents string) (string, bool) {
+		updated := renameTables(contents, name, newName)
+		return updated, !hasProfile(updated, name) && (hasProfile(updated, newName) || !hasProfile(contents, name))
+	}, func(t *toml.Tree) error {
+		if v := t.Get(name); v != nil {
+			t.Set(newName, v)
+		}
+		return t.Delete(name)
+	})
+	if err != nil {
 		return err
 	}
-
-	return nil
+	return p.renameState(newName)
 }
 
 func LoadAtlasCLIConfig() error { return Default().LoadAtlasCLIConfig(true) }
 func (p *Profile) LoadAtlasCLIConfig(readEnvironmentVars bool) error {
+	if p.IsEphemeral() {
+		return ErrEphemeralProfile
+	}
 	if p.err != nil {
 		return p.err
 	}
 
 	viper.SetConfigName("config")
 
+	if p.customEnvPrefix != "" {
+		return p.load(readEnvironmentVars, p.customEnvPrefix)
+	}
+
 	if hasMongoCLIEnvVars() {
-		viper.SetEnvKeyReplacer(strings.NewReplacer(AtlasCLIEnvPrefix, MongoCLIEnvPrefix))
+		p.envKeyReplacer = strings.NewReplacer(AtlasCLIEnvPrefix, MongoCLIEnvPrefix)
+		viper.SetEnvKeyReplacer(p.envKeyReplacer)
+		if readEnvironmentVars {
+			p.warn(Warning{
+				Kind:    WarningDeprecation,
+				Message: "the " + MongoCLIEnvPrefix + "_ environment variables are deprecated, use the " + AtlasCLIEnvPrefix + "_ ones",
+			})
+		}
 	}
 
 	return p.load(readEnvironmentVars, AtlasCLIEnvPrefix)
@@ -489,28 +911,197 @@ func (p *Profile) load(readEnvironmentVars bool, envPrefix string) error {
 	if readEnvironmentVars {
 		viper.SetEnvPrefix(envPrefix)
 		viper.AutomaticEnv()
+		p.envPrefix = envPrefix
+		p.bindEnvAliases()
+		if err := p.loadEnvFiles(); err != nil {
+			return err
+		}
+		if err := p.loadCredentialsDirectory(); err != nil {
+			return err
+		}
 	}
 
 	// aliases only work for a config file, this won't work for env variables
 	viper.RegisterAlias(baseURL, OpsManagerURLField)
 
-	// If a config file is found, read it in.
-	err := viper.ReadInConfig()
-	if err == nil {
+	if p.lazy {
+		p.deferLoad()
 		return nil
 	}
+	return p.readConfigFile()
+}
+
+func (p *Profile) readConfig() error {
+	if err := p.readSettings(); err != nil {
+		return err
+	}
+	p.checkSettings()
+	return nil
+}
+
+// readSettings reads the config file into viper. It doesn't read settings back, so EnsureLoaded
+// can hold its lock while it runs, see checkSettings.
+func (p *Profile) readSettings() error {
+	p.loadPending.Store(false)
+	defer invalidateSettings()
+	if err := p.verifyIntegrity(); err != nil {
+		return err
+	}
+
+	// If a config file is found, read it in.
+	err := viper.ReadInConfig()
 
 	// ignore if it doesn't exists
 	var e viper.ConfigFileNotFoundError
+	if err != nil && !errors.As(err, &e) {
+		return parseError(err)
+	}
+
+	p.loaded = true
+	return p.trackFile()
+}
+
+// checkSettings warns about the settings of the config file that was just read.
+func (p *Profile) checkSettings() {
+	p.checkTelemetryOverride()
+	p.checkOpsManagerURL()
+}
+
+// readFile reads the config file currently on disk into a new viper instance.
+// A missing file results in an empty configuration.
+func (p *Profile) readFile() (*viper.Viper, error) {
+	v := viper.New()
+	v.SetFs(p.fs)
+	v.SetConfigType(configType)
+	v.SetConfigPermissions(configPerm)
+	v.SetConfigFile(p.Filename())
+	if err := v.ReadInConfig(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, parseError(err)
+	}
+	return v, nil
+}
+
+// parseError flags viper parse failures with ErrConfigCorrupted so callers know they can Recover.
+func parseError(err error) error {
+	var e viper.ConfigParseError
 	if errors.As(err, &e) {
-		return nil
+		return fmt.Errorf("%w: %w", ErrConfigCorrupted, err)
 	}
 	return err
 }
 
+// fileContents returns the config file contents, or nil if the file doesn't exist.
+func (p *Profile) fileContents() ([]byte, error) {
+	b, err := afero.ReadFile(p.fs, p.Filename())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return b, err
+}
+
+// fileHash returns the sha256 of the config file contents, or an empty string if the file doesn't exist.
+func (p *Profile) fileHash() (string, error) {
+	b, err := p.fileContents()
+	if err != nil {
+		return "", err
+	}
+	return contentsHash(b), nil
+}
+
+func contentsHash(b []byte) string {
+	if b == nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// trackFile records the current state of the config file, used to detect external edits on Save,
+// and indexes its profiles for List.
+func (p *Profile) trackFile() error {
+	b, err := p.fileContents()
+	if err != nil {
+		return err
+	}
+	snapshot, err := p.readFile()
+	if err != nil {
+		return err
+	}
+	p.loadedHash = contentsHash(b)
+	p.snapshot = snapshot
+	indexFile(string(b))
+	return nil
+}
+
+// fileWritten signs and tracks the config file after this process wrote it.
+func (p *Profile) fileWritten() error {
+	invalidateSettings()
+	if err := p.Sign(); err != nil {
+		return err
+	}
+	return p.trackFile()
+}
+
+// checkUnmodified returns ErrConfigFileModified if any of the keys changed by this process
+// was also changed on disk since the config file was loaded.
+func (p *Profile) checkUnmodified(onDisk *viper.Viper) error {
+	if !p.loaded {
+		return nil
+	}
+	h, err := p.fileHash()
+	if err != nil {
+		return err
+	}
+	if h == p.loadedHash {
+		return nil
+	}
+	for key := range p.dirty {
+		if !reflect.DeepEqual(onDisk.Get(key), p.snapshot.Get(key)) {
+			return fmt.Errorf("%w: %q in %s", ErrConfigFileModified, key, p.Filename())
+		}
+	}
+	return nil
+}
+
 // Save the configuration to disk.
+// Only the keys changed by this process are written, merged into the current contents of the file,
+// so changes made by other processes are preserved.
+// Returns ErrConfigFileModified if one of those keys was also edited by someone else after it was loaded,
+// use ReloadAndSave to overwrite them.
 func Save() error { return Default().Save() }
 func (p *Profile) Save() error {
+	if p.IsEphemeral() {
+		return nil
+	}
+	if err := p.EnsureLoaded(); err != nil {
+		return err
+	}
+	if err := p.readOnlyErr; err != nil {
+		p.readOnlyErr = nil
+		return err
+	}
+	// the file is signed again once saved, so changes made since it was loaded must be verified first
+	if err := p.verifyIntegrity(); err != nil {
+		return err
+	}
+
+	onDisk, err := p.readFile()
+	if err != nil {
+		return err
+	}
+	if err := p.checkUnmodified(onDisk); err != nil {
+		return err
+	}
+
+	for key := range p.dirty {
+		onDisk.Set(key, viper.Get(key))
+	}
+	if onDisk.GetBool(encryptSecrets) {
+		if err := encryptFileSecrets(onDisk); err != nil {
+			return err
+		}
+	}
+
 	exists, err := afero.DirExists(p.fs, p.configDir)
 	if err != nil {
 		return err
@@ -521,23 +1112,72 @@ func (p *Profile) Save() error {
 		}
 	}
 
-	return viper.WriteConfigAs(p.Filename())
+	if err := onDisk.WriteConfigAs(p.Filename()); err != nil {
+		return err
+	}
+	if err := p.Sign(); err != nil {
+		return err
+	}
+
+	p.dirty = nil
+	return p.readConfig()
 }
 
+// ReloadAndSave reads the config file again and saves the configuration on top of it.
+// Values changed in this process take precedence over the ones edited externally.
+func ReloadAndSave() error { return Default().ReloadAndSave() }
+func (p *Profile) ReloadAndSave() error {
+	if p.IsEphemeral() {
+		return nil
+	}
+	if err := p.readConfig(); err != nil {
+		return err
+	}
+
+	return p.Save()
+}
+
+// HttpClient returns a client with a new connection pool, long-running consumers should reuse it
+// and call its CloseIdleConnections method once done. Its requests fail when ValidateEndpoint does.
 func HttpClient() *http.Client {
 	return Default().HttpClient()
 }
 func (p *Profile) HttpClient() *http.Client {
+	if dir := p.DemoFixtures(); dir != "" {
+		return &http.Client{Transport: &demoTransport{dir: dir, fs: p.fs}}
+	}
+	if p.Offline() {
+		return &http.Client{Transport: offlineTransport{}}
+	}
+	if p.OpsManagerURL() == "" {
+		if err := p.ValidateEndpoint(); err != nil {
+			return &http.Client{Transport: invalidEndpointTransport{err: err}}
+		}
+	}
+	// each request to a host goes through the limiter and the circuit breaker of that host
+	host := p.circuitBreakerTransport(p.limitTransport(p.sizeLimitTransport(p.cloudGovTransport(p.networkTransport()))))
 	return &http.Client{
-		Transport: p.HttpTransport(http.DefaultTransport),
+		Transport: p.activityTransport(p.metricsTransport(p.previewTransport(p.apiVersionTransport(p.dryRunTransport(p.HttpTransport(p.failoverTransport(host))))))),
+		Timeout:   p.HTTPTimeout(),
 	}
 }
 
+// HttpBaseURL returns the base URL of the API, empty when the API region and endpoint type don't select one,
+// see ValidateEndpoint.
 func HttpBaseURL() string {
 	return Default().HttpBaseURL()
 }
 func (p *Profile) HttpBaseURL() string {
-	return p.OpsManagerURL()
+	if u := p.OpsManagerURL(); u != "" {
+		return u
+	}
+	if u, ok := p.endpointBaseURL(); ok {
+		if strings.Contains(u, regionPlaceholder) {
+			return ""
+		}
+		return u
+	}
+	return p.CurrentService().Capabilities().DefaultBaseURL
 }
 
 func HttpTransport(httpTransport http.RoundTripper) http.RoundTripper {
@@ -548,15 +1188,13 @@ func (p *Profile) HttpTransport(httpTransport http.RoundTripper) http.RoundTripp
 	password := p.PrivateAPIKey()
 
 	if username != "" && password != "" {
-		return &digest.Transport{
-			Username:  username,
-			Password:  password,
-			Transport: httpTransport,
-		}
+		t := newDigestTransport(username, password, httpTransport)
+		t.fips = p.FIPSMode()
+		return t
 	}
 
 	accessToken := p.AccessToken()
-	if accessToken != "" {
+	if accessToken != "" && p.CurrentService().Capabilities().OAuth {
 		return &Transport{
 			token: accessToken,
 			base:  httpTransport,
@@ -571,7 +1209,15 @@ type Transport struct {
 	base  http.RoundTripper
 }
 
+// RoundTrip sends the request with a bearer token.
+// RoundTrippers must not modify the request, so the header is set on a copy.
 func (tr *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
-	req.Header.Set("Authorization", "Bearer "+tr.token)
-	return tr.base.RoundTrip(req)
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+tr.token)
+	return tr.base.RoundTrip(r)
+}
+
+// CloseIdleConnections closes the idle connections of the base transport.
+func (tr *Transport) CloseIdleConnections() {
+	closeIdleConnections(tr.base)
 }
@@ -17,13 +17,34 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"os"
 	"testing"
 
 	"github.com/spf13/afero"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 )
 
+// newTestProfile returns the default profile backed by an in memory config file with the given contents.
+func newTestProfile(t testing.TB, contents string) *Profile {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	p := &Profile{
+		name:      DefaultProfile,
+		configDir: "/atlascli",
+		fs:        afero.NewMemMapFs(),
+	}
+	if contents != "" {
+		require.NoError(t, afero.WriteFile(p.fs, p.Filename(), []byte(contents), configPerm))
+	}
+	require.NoError(t, p.load(false, AtlasCLIEnvPrefix))
+	return p
+}
+
 func TestProfile_Rename(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -101,3 +122,163 @@ func TestProfile_SetName(t *testing.T) {
 		})
 	}
 }
+
+func TestProfile_Save_MergesExternalEdits(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  org_id = \"a\"\n")
+	p.SetProjectID("b")
+	p.SetSkipUpdateCheck(true)
+
+	require.NoError(t, afero.WriteFile(p.fs, p.Filename(), []byte("[default]\n  org_id = \"c\"\n[other]\n  org_id = \"d\"\n"), configPerm))
+	require.NoError(t, p.Save())
+
+	b, err := afero.ReadFile(p.fs, p.Filename())
+	require.NoError(t, err)
+	got := string(b)
+	require.Contains(t, got, "skip_update_check = true")
+	require.Contains(t, got, "[other]")
+	require.Contains(t, got, "org_id = 'c'")
+	require.Contains(t, got, "project_id = 'b'")
+}
+
+func TestProfile_Save_DetectsConflictingEdits(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  project_id = \"a\"\n")
+	p.SetProjectID("b")
+
+	require.NoError(t, afero.WriteFile(p.fs, p.Filename(), []byte("[default]\n  project_id = \"c\"\n"), configPerm))
+	require.ErrorIs(t, p.Save(), ErrConfigFileModified)
+
+	require.NoError(t, p.ReloadAndSave())
+	b, err := afero.ReadFile(p.fs, p.Filename())
+	require.NoError(t, err)
+	require.Contains(t, string(b), "project_id = 'b'")
+
+	// the file is tracked after writing, so saving again succeeds
+	p.SetProjectID("e")
+	require.NoError(t, p.Save())
+}
+
+func TestProfile_ReadOnly(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  org_id = \"a\"\n  read_only = true\n")
+	require.True(t, p.IsReadOnly())
+
+	p.SetOrgID("b")
+	require.Equal(t, "a", p.OrgID())
+	require.ErrorIs(t, p.Save(), ErrProfileReadOnly)
+	require.NoError(t, p.Save(), "the refused change is only reported once")
+	require.ErrorIs(t, p.Rename("other"), ErrProfileReadOnly)
+	require.ErrorIs(t, p.Delete(), ErrProfileReadOnly)
+}
+
+func TestProfile_Rename_readOnlyTarget(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  org_id = \"a\"\n\n[other]\n  org_id = \"b\"\n  read_only = true\n")
+	require.ErrorIs(t, p.Rename("other"), ErrProfileReadOnly, "a read only profile isn't overwritten")
+	require.Equal(t, "b", p.sibling("other").OrgID())
+	require.Equal(t, "a", p.OrgID())
+}
+
+func TestProfile_ReadOnlyFile(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  org_id = \"a\"\n")
+	require.False(t, p.IsReadOnly())
+
+	require.NoError(t, p.fs.Chmod(p.Filename(), 0400))
+	require.True(t, p.IsReadOnly())
+
+	p.SetSkipUpdateCheck(true)
+	require.ErrorIs(t, p.Save(), ErrProfileReadOnly)
+}
+
+func TestProfile_GetString(t *testing.T) {
+	p := newTestProfile(t, `
+[default]
+  project_id = 123
+  org_id = "abc"
+  telemetry_enabled = true
+  timeout = 1.5
+  [default.nested]
+    key = "value"
+`)
+
+	tests := map[string]string{
+		projectID:                "123",
+		orgID:                    "abc",
+		TelemetryEnabledProperty: "true",
+		"timeout":                "1.5",
+		"missing":                "",
+		"nested":                 "",
+	}
+	for key, want := range tests {
+		require.Equal(t, want, p.GetString(key), key)
+	}
+
+	_, err := p.GetStringErr("nested")
+	var conversionErr *ConversionError
+	require.ErrorAs(t, err, &conversionErr)
+	require.ErrorIs(t, err, ErrInvalidValueType)
+	require.Equal(t, "nested", conversionErr.Key)
+}
+
+func TestProfile_ShouldColorize(t *testing.T) {
+	t.Setenv(NoColorEnv, "")
+	buf := &bytes.Buffer{}
+
+	p := newTestProfile(t, "[default]\n  color = \"invalid\"\n")
+	require.Equal(t, ColorAuto, p.Color())
+	require.False(t, p.ShouldColorize(buf), "not a terminal")
+
+	p.SetColor(ColorAlways)
+	require.True(t, p.ShouldColorize(buf))
+	t.Setenv(NoColorEnv, "1")
+	require.True(t, p.ShouldColorize(buf), "always ignores NO_COLOR")
+
+	p.SetColor(ColorNever)
+	require.False(t, p.ShouldColorize(os.Stdout))
+
+	_, err := ParseColorMode("sometimes")
+	require.ErrorIs(t, err, ErrInvalidColorMode)
+}
+
+func TestProfile_Pager(t *testing.T) {
+	t.Setenv(PagerEnv, "less")
+	p := newTestProfile(t, "")
+	require.Equal(t, "less", p.Pager())
+
+	p.SetPager("more")
+	require.Equal(t, "more", p.Pager())
+}
+
+const benchmarkConfig = `skip_update_check = true
+
+[base]
+  org_id = "5e2211c17a3e5a48f5497de3"
+  output = "json"
+
+[default]
+  inherits = "base"
+  project_id = "5e2211c17a3e5a48f5497de4"
+  service = "cloud"
+`
+
+func BenchmarkProfile_GetString(b *testing.B) {
+	p := newTestProfile(b, benchmarkConfig)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = p.ProjectID()
+		_ = p.OrgID()
+		_ = p.Output()
+		_ = p.PublicAPIKey()
+	}
+}
+
+func BenchmarkProfile_GetBool(b *testing.B) {
+	p := newTestProfile(b, benchmarkConfig)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = p.SkipUpdateCheck()
+		_ = p.TelemetryEnabled()
+		_ = p.IsReadOnly()
+	}
+}
@@ -0,0 +1,335 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxProxyAuthLegs limits the round trips to negotiate with a proxy, NTLM needs two.
+const maxProxyAuthLegs = 4
+
+var (
+	ErrProxyAuthentication       = errors.New("proxy authentication failed")
+	ErrUnknownProxyAuthenticator = errors.New("unknown proxy authentication scheme")
+	ErrInvalidProxy              = errors.New("invalid proxy URL")
+	ErrProxyAuthNotFIPS          = errors.New("proxy authentication scheme not allowed in FIPS mode")
+)
+
+// nonFIPSProxySchemes are the proxy authentication schemes relying on MD4, MD5 or RC4, refused in FIPS mode.
+var nonFIPSProxySchemes = []string{"ntlm", "negotiate"}
+
+// Proxy get the configured proxy URL, like http://proxy:3128 or socks5://proxy:1080.
+// Empty means the proxy is configured by the HTTPS_PROXY and NO_PROXY environment variables.
+func Proxy() string { return Default().Proxy() }
+func (p *Profile) Proxy() string {
+	return p.GetString(proxyURL)
+}
+
+// SetProxy sets the proxy URL, the http, https, socks5 and socks5h schemes are supported.
+func SetProxy(v string) error { return Default().SetProxy(v) }
+func (p *Profile) SetProxy(v string) error {
+	if v != "" {
+		if _, err := parseProxy(v); err != nil {
+			return err
+		}
+	}
+	p.Set(proxyURL, v)
+	return nil
+}
+
+func parseProxy(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProxy, err)
+	}
+	switch {
+	case u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" && u.Scheme != "socks5h":
+		return nil, fmt.Errorf("%w: %q, the scheme must be http, https, socks5 or socks5h", ErrInvalidProxy, raw)
+	case u.Host == "":
+		return nil, fmt.Errorf("%w: %q, missing host", ErrInvalidProxy, raw)
+	}
+	return u, nil
+}
+
+// proxyFunc returns the http.Transport Proxy function for the configured proxy, nil when there's none.
+// An invalid proxy fails the requests instead of connecting directly.
+func (p *Profile) proxyFunc() func(*http.Request) (*url.URL, error) {
+	raw := p.Proxy()
+	if raw == "" {
+		return nil
+	}
+	u, err := parseProxy(raw)
+	if err != nil {
+		return func(*http.Request) (*url.URL, error) { return nil, err }
+	}
+	return http.ProxyURL(u)
+}
+
+// ProxyAuthenticator negotiates the credentials for a proxy, like Kerberos through SPNEGO, or NTLM.
+type ProxyAuthenticator interface {
+	Negotiate(ctx context.Context, proxy *url.URL) (ProxyNegotiation, error)
+}
+
+// ProxyNegotiation authenticates one connection to a proxy.
+// Step returns the token to send for a challenge of the proxy, the challenge is nil for the first request.
+type ProxyNegotiation interface {
+	Step(challenge []byte) ([]byte, error)
+	Close() error
+}
+
+// proxyAuthenticators are the registered authenticators by the lower case HTTP authentication scheme.
+var proxyAuthenticators = struct {
+	sync.RWMutex
+	byScheme map[string]proxyAuthenticator
+}{
+	byScheme: map[string]proxyAuthenticator{},
+}
+
+type proxyAuthenticator struct {
+	scheme string
+	ProxyAuthenticator
+}
+
+// RegisterProxyAuthenticator makes proxies be authenticated with a, for profiles with the proxy_auth setting set to scheme.
+// The scheme is the one of the Proxy-Authorization header, like Negotiate or NTLM, a nil authenticator unregisters it.
+// On Windows, Negotiate and NTLM are registered by default and use the credentials of the logged in user.
+func RegisterProxyAuthenticator(scheme string, a ProxyAuthenticator) {
+	proxyAuthenticators.Lock()
+	defer proxyAuthenticators.Unlock()
+	if a == nil {
+		delete(proxyAuthenticators.byScheme, strings.ToLower(scheme))
+		return
+	}
+	proxyAuthenticators.byScheme[strings.ToLower(scheme)] = proxyAuthenticator{scheme: scheme, ProxyAuthenticator: a}
+}
+
+func lookupProxyAuthenticator(scheme string) (proxyAuthenticator, error) {
+	proxyAuthenticators.RLock()
+	defer proxyAuthenticators.RUnlock()
+	a, ok := proxyAuthenticators.byScheme[strings.ToLower(scheme)]
+	if !ok {
+		return proxyAuthenticator{}, fmt.Errorf("%w: %q", ErrUnknownProxyAuthenticator, scheme)
+	}
+	return a, nil
+}
+
+// ProxyAuth get the configured authentication scheme for the proxy, like Negotiate or NTLM.
+// Empty means the proxy is used as configured by the HTTPS_PROXY environment variable.
+func ProxyAuth() string { return Default().ProxyAuth() }
+func (p *Profile) ProxyAuth() string {
+	return p.GetString(proxyAuth)
+}
+
+// SetProxyAuth sets the authentication scheme for the proxy.
+func SetProxyAuth(v string) { Default().SetProxyAuth(v) }
+func (p *Profile) SetProxyAuth(v string) {
+	p.Set(proxyAuth, v)
+}
+
+// proxyDialer opens the connections through the proxy itself, so the authentication can take more than one round trip
+// on the same connection, which http.Transport doesn't support.
+type proxyDialer struct {
+	dial   func(ctx context.Context, network, addr string) (net.Conn, error)
+	proxy  func(*http.Request) (*url.URL, error)
+	scheme string
+	fips   bool // fips refuses the nonFIPSProxySchemes, see FIPSMode
+
+	tlsConfig *tls.Config // tlsConfig is the configuration for https proxies, nil for the defaults, see proxyTLSConfig
+}
+
+// DialContext connects to addr through the proxy, or directly when no proxy is configured for it.
+func (d *proxyDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	proxyURL, err := d.proxyFor(addr)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL == nil {
+		return d.dial(ctx, network, addr)
+	}
+
+	conn, err := d.dialProxy(ctx, network, proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.connect(ctx, conn, proxyURL, addr); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// proxyFor returns the proxy for an address, the transport only dials plain connections for port 80.
+func (d *proxyDialer) proxyFor(addr string) (*url.URL, error) {
+	scheme := "https"
+	if _, port, _ := net.SplitHostPort(addr); port == "80" {
+		scheme = "http"
+	}
+	return d.proxy(&http.Request{URL: &url.URL{Scheme: scheme, Host: addr}})
+}
+
+func (d *proxyDialer) dialProxy(ctx context.Context, network string, proxyURL *url.URL) (net.Conn, error) {
+	port := proxyURL.Port()
+	if port == "" {
+		port = "80"
+		if proxyURL.Scheme == "https" {
+			port = "443"
+		}
+	}
+	conn, err := d.dial(ctx, network, net.JoinHostPort(proxyURL.Hostname(), port))
+	if err != nil {
+		return nil, err
+	}
+	switch proxyURL.Scheme {
+	case "http":
+		return conn, nil
+	case "https":
+		c := &tls.Config{MinVersion: tls.VersionTLS12}
+		if d.tlsConfig != nil {
+			c = d.tlsConfig.Clone()
+		}
+		c.ServerName = proxyURL.Hostname()
+		tlsConn := tls.Client(conn, c)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	default:
+		conn.Close()
+		return nil, fmt.Errorf("%w: unsupported proxy scheme %q", ErrProxyAuthentication, proxyURL.Scheme)
+	}
+}
+
+// connect opens a tunnel to addr with CONNECT, answering the challenges of the proxy until it accepts the connection.
+func (d *proxyDialer) connect(ctx context.Context, conn net.Conn, proxyURL *url.URL, addr string) error {
+	if d.fips && slices.Contains(nonFIPSProxySchemes, strings.ToLower(d.scheme)) {
+		return fmt.Errorf("%w: %q relies on MD4, MD5 or RC4", ErrProxyAuthNotFIPS, d.scheme)
+	}
+	a, err := lookupProxyAuthenticator(d.scheme)
+	if err != nil {
+		return err
+	}
+	n, err := a.Negotiate(ctx, proxyURL)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrProxyAuthentication, err)
+	}
+	defer n.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{}) //nolint:errcheck // the connection fails on first use if this fails
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Unix(1, 0)) })
+	defer stop()
+
+	br := bufio.NewReader(conn)
+	var challenge []byte
+	for leg := 0; leg < maxProxyAuthLegs; leg++ {
+		token, err := n.Step(challenge)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrProxyAuthentication, err)
+		}
+		req := &http.Request{
+			Method: http.MethodConnect,
+			URL:    &url.URL{Opaque: addr},
+			Host:   addr,
+			Header: http.Header{},
+		}
+		if len(token) > 0 {
+			req.Header.Set("Proxy-Authorization", a.scheme+" "+base64.StdEncoding.EncodeToString(token))
+		}
+		if err := req.Write(conn); err != nil {
+			return err
+		}
+		resp, err := http.ReadResponse(br, req)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode == http.StatusOK {
+			resp.Body.Close()
+			if br.Buffered() > 0 {
+				return fmt.Errorf("%w: unexpected data after CONNECT response", ErrProxyAuthentication)
+			}
+			return nil
+		}
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+
+		var ok bool
+		challenge, ok = proxyChallenge(resp.Header, a.scheme)
+		if resp.StatusCode != http.StatusProxyAuthRequired || !ok || resp.Close {
+			return fmt.Errorf("%w: %s", ErrProxyAuthentication, resp.Status)
+		}
+	}
+	return fmt.Errorf("%w: too many challenges", ErrProxyAuthentication)
+}
+
+// proxyChallenge returns the decoded data of the Proxy-Authenticate challenge for the scheme,
+// false if the proxy didn't send one or it's not valid base64.
+func proxyChallenge(h http.Header, scheme string) ([]byte, bool) {
+	for _, v := range h.Values("Proxy-Authenticate") {
+		name, data, _ := strings.Cut(strings.TrimSpace(v), " ")
+		if !strings.EqualFold(name, scheme) {
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+		return b, err == nil
+	}
+	return nil, false
+}
+
+// proxyDialer returns the dialer authenticating with the proxy, nil when the profile doesn't use proxy authentication.
+func (p *Profile) proxyDialer(t *http.Transport) *proxyDialer {
+	scheme := p.ProxyAuth()
+	if scheme == "" || t.Proxy == nil {
+		return nil
+	}
+	return &proxyDialer{
+		dial:      t.DialContext,
+		proxy:     t.Proxy,
+		scheme:    scheme,
+		fips:      p.FIPSMode(),
+		tlsConfig: proxyTLSConfig(t.TLSClientConfig),
+	}
+}
+
+// proxyTLSConfig returns the configuration for https proxies from the one of the API: the trusted CAs and the allowed
+// versions and algorithms, but not the client certificate, which is only for the API.
+func proxyTLSConfig(c *tls.Config) *tls.Config {
+	if c == nil {
+		return nil
+	}
+	return &tls.Config{
+		RootCAs:          c.RootCAs,
+		MinVersion:       c.MinVersion,
+		MaxVersion:       c.MaxVersion,
+		CipherSuites:     c.CipherSuites,
+		CurvePreferences: c.CurvePreferences,
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testNegotiation answers the challenge of the proxy with response:challenge, like the second leg of NTLM.
+type testNegotiation struct{ closed *bool }
+
+func (testNegotiation) Step(challenge []byte) ([]byte, error) {
+	if challenge == nil {
+		return []byte("hello"), nil
+	}
+	return append([]byte("response:"), challenge...), nil
+}
+
+func (n testNegotiation) Close() error {
+	*n.closed = true
+	return nil
+}
+
+type testAuthenticator struct{ closed bool }
+
+func (a *testAuthenticator) Negotiate(context.Context, *url.URL) (ProxyNegotiation, error) {
+	return testNegotiation{closed: &a.closed}, nil
+}
+
+// serveTestProxy accepts a CONNECT after a challenge on the same connection, and tunnels it to the target.
+func serveTestProxy(t *testing.T, l net.Listener) {
+	t.Helper()
+	conn, err := l.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	br := bufio.NewReader(conn)
+
+	req, err := http.ReadRequest(br)
+	require.NoError(t, err)
+	require.Equal(t, http.MethodConnect, req.Method)
+	require.Equal(t, "Test "+base64.StdEncoding.EncodeToString([]byte("hello")), req.Header.Get("Proxy-Authorization"))
+	_, _ = io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\nProxy-Authenticate: Test "+
+		base64.StdEncoding.EncodeToString([]byte("challenge"))+"\r\nContent-Length: 0\r\n\r\n")
+
+	req, err = http.ReadRequest(br)
+	require.NoError(t, err)
+	require.Equal(t, "Test "+base64.StdEncoding.EncodeToString([]byte("response:challenge")), req.Header.Get("Proxy-Authorization"))
+	target, err := net.Dial("tcp", req.Host)
+	require.NoError(t, err)
+	defer target.Close()
+	_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+
+	go func() { _, _ = io.Copy(target, br) }()
+	_, _ = io.Copy(conn, target)
+}
+
+func TestProxyDialer_Negotiate(t *testing.T) {
+	a := &testAuthenticator{}
+	RegisterProxyAuthenticator("Test", a)
+	t.Cleanup(func() { RegisterProxyAuthenticator("Test", nil) })
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer s.Close()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go serveTestProxy(t, l)
+
+	d := &proxyDialer{
+		dial:   (&net.Dialer{}).DialContext,
+		proxy:  http.ProxyURL(&url.URL{Scheme: "http", Host: l.Addr().String()}),
+		scheme: "test",
+	}
+	client := &http.Client{Transport: &http.Transport{DialContext: d.DialContext, DisableKeepAlives: true}}
+	resp, err := client.Get(s.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.True(t, a.closed)
+}
+
+func TestProxyDialer_UnknownScheme(t *testing.T) {
+	d := &proxyDialer{
+		dial: func(context.Context, string, string) (net.Conn, error) {
+			c, _ := net.Pipe()
+			return c, nil
+		},
+		proxy:  http.ProxyURL(&url.URL{Scheme: "http", Host: "proxy:8080"}),
+		scheme: "unknown",
+	}
+	_, err := d.DialContext(context.Background(), "tcp", "cloud.mongodb.com:443")
+	require.ErrorIs(t, err, ErrUnknownProxyAuthenticator)
+}
+
+func TestProxyDialer_FIPS(t *testing.T) {
+	a := &testAuthenticator{}
+	RegisterProxyAuthenticator("NTLM", a)
+	t.Cleanup(func() { RegisterProxyAuthenticator("NTLM", nil) })
+
+	p := newTestProfile(t, "")
+	p.SetProxyAuth("NTLM")
+	require.NoError(t, p.SetFIPSMode(true))
+	d := p.proxyDialer(&http.Transport{Proxy: http.ProxyURL(&url.URL{Scheme: "http", Host: "proxy:8080"})})
+	require.True(t, d.fips)
+	d.dial = func(context.Context, string, string) (net.Conn, error) {
+		c, _ := net.Pipe()
+		return c, nil
+	}
+	_, err := d.DialContext(context.Background(), "tcp", "cloud.mongodb.com:443")
+	require.ErrorIs(t, err, ErrProxyAuthNotFIPS)
+	require.False(t, a.closed, "the authenticator isn't used")
+
+	d.scheme = "Negotiate"
+	_, err = d.DialContext(context.Background(), "tcp", "cloud.mongodb.com:443")
+	require.ErrorIs(t, err, ErrProxyAuthNotFIPS)
+}
+
+func TestProfile_ProxyAuth(t *testing.T) {
+	p := newTestProfile(t, "")
+	require.NotNil(t, p.baseTransport().Proxy)
+
+	p.SetProxyAuth("Negotiate")
+	require.Equal(t, "Negotiate", p.ProxyAuth())
+	require.Nil(t, p.baseTransport().Proxy, "the dialer connects to the proxy")
+}
+
+func TestProfile_proxyDialer_tlsConfig(t *testing.T) {
+	p := newTestProfile(t, "")
+	p.SetProxyAuth("Negotiate")
+	roots := x509.NewCertPool()
+	base := &http.Transport{
+		Proxy: http.ProxyURL(&url.URL{Scheme: "https", Host: "proxy:8443"}),
+		TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			MinVersion:   tls.VersionTLS12,
+			CipherSuites: fipsCipherSuites,
+			Certificates: []tls.Certificate{{}},
+			GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				return &tls.Certificate{}, nil
+			},
+		},
+	}
+
+	d := p.proxyDialer(base)
+	require.NotNil(t, d)
+	require.Same(t, roots, d.tlsConfig.RootCAs)
+	require.Equal(t, uint16(tls.VersionTLS12), d.tlsConfig.MinVersion)
+	require.Equal(t, fipsCipherSuites, d.tlsConfig.CipherSuites)
+	require.Empty(t, d.tlsConfig.Certificates, "the client certificate is only presented to the API")
+	require.Nil(t, d.tlsConfig.GetClientCertificate)
+}
+
+func TestProxyChallenge(t *testing.T) {
+	h := http.Header{}
+	h.Add("Proxy-Authenticate", "Basic realm=\"proxy\"")
+	h.Add("Proxy-Authenticate", "NTLM "+base64.StdEncoding.EncodeToString([]byte("abc")))
+
+	got, ok := proxyChallenge(h, "ntlm")
+	require.True(t, ok)
+	require.Equal(t, []byte("abc"), got)
+
+	_, ok = proxyChallenge(h, "Negotiate")
+	require.False(t, ok)
+}
@@ -0,0 +1,175 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// SSPI constants, see sspi.h.
+const (
+	secpkgCredOutbound      = 2
+	securityNativeDrep      = 0x10
+	iscReqAllocateMemory    = 0x100
+	iscReqConnection        = 0x800
+	secbufferToken          = 2
+	secbufferVersion        = 0
+	secEOK                  = 0
+	secIContinueNeeded      = 0x00090312
+	secICompleteNeeded      = 0x00090313
+	secICompleteAndContinue = 0x00090314
+)
+
+var (
+	secur32                        = windows.NewLazySystemDLL("secur32.dll")
+	procAcquireCredentialsHandleW  = secur32.NewProc("AcquireCredentialsHandleW")
+	procInitializeSecurityContextW = secur32.NewProc("InitializeSecurityContextW")
+	procCompleteAuthToken          = secur32.NewProc("CompleteAuthToken")
+	procDeleteSecurityContext      = secur32.NewProc("DeleteSecurityContext")
+	procFreeCredentialsHandle      = secur32.NewProc("FreeCredentialsHandle")
+	procFreeContextBuffer          = secur32.NewProc("FreeContextBuffer")
+)
+
+type secHandle struct {
+	lower uintptr
+	upper uintptr
+}
+
+type secBuffer struct {
+	size       uint32
+	bufferType uint32
+	buffer     *byte
+}
+
+type secBufferDesc struct {
+	version uint32
+	count   uint32
+	buffers *secBuffer
+}
+
+func init() {
+	RegisterProxyAuthenticator("Negotiate", sspiAuthenticator("Negotiate"))
+	RegisterProxyAuthenticator("NTLM", sspiAuthenticator("NTLM"))
+}
+
+// sspiAuthenticator authenticates with the credentials of the logged in user through the SSPI package of the same name.
+type sspiAuthenticator string
+
+func (a sspiAuthenticator) Negotiate(_ context.Context, proxy *url.URL) (ProxyNegotiation, error) {
+	pkg, err := windows.UTF16PtrFromString(string(a))
+	if err != nil {
+		return nil, err
+	}
+	target, err := windows.UTF16PtrFromString("HTTP/" + proxy.Hostname())
+	if err != nil {
+		return nil, err
+	}
+
+	n := &sspiNegotiation{target: target}
+	var expiry int64
+	r, _, _ := procAcquireCredentialsHandleW.Call(
+		0,
+		uintptr(unsafe.Pointer(pkg)),
+		secpkgCredOutbound,
+		0, 0, 0, 0,
+		uintptr(unsafe.Pointer(&n.cred)),
+		uintptr(unsafe.Pointer(&expiry)),
+	)
+	if r != secEOK {
+		return nil, fmt.Errorf("AcquireCredentialsHandle: %w", windows.Errno(r))
+	}
+	return n, nil
+}
+
+type sspiNegotiation struct {
+	target     *uint16
+	cred       secHandle
+	ctx        secHandle
+	hasContext bool
+}
+
+func (n *sspiNegotiation) Step(challenge []byte) ([]byte, error) {
+	var in *secBufferDesc
+	if len(challenge) > 0 {
+		in = &secBufferDesc{
+			version: secbufferVersion,
+			count:   1,
+			buffers: &secBuffer{size: uint32(len(challenge)), bufferType: secbufferToken, buffer: &challenge[0]},
+		}
+	}
+	outBuf := &secBuffer{bufferType: secbufferToken}
+	out := &secBufferDesc{version: secbufferVersion, count: 1, buffers: outBuf}
+
+	var ctx *secHandle
+	if n.hasContext {
+		ctx = &n.ctx
+	}
+	var attrs uint32
+	var expiry int64
+	r, _, _ := procInitializeSecurityContextW.Call(
+		uintptr(unsafe.Pointer(&n.cred)),
+		uintptr(unsafe.Pointer(ctx)),
+		uintptr(unsafe.Pointer(n.target)),
+		iscReqAllocateMemory|iscReqConnection,
+		0,
+		securityNativeDrep,
+		uintptr(unsafe.Pointer(in)),
+		0,
+		uintptr(unsafe.Pointer(&n.ctx)),
+		uintptr(unsafe.Pointer(out)),
+		uintptr(unsafe.Pointer(&attrs)),
+		uintptr(unsafe.Pointer(&expiry)),
+	)
+	switch r {
+	case secEOK, secIContinueNeeded:
+	case secICompleteNeeded, secICompleteAndContinue:
+		n.hasContext = true
+		if c, _, _ := procCompleteAuthToken.Call(uintptr(unsafe.Pointer(&n.ctx)), uintptr(unsafe.Pointer(out))); c != secEOK {
+			n.freeBuffer(outBuf)
+			return nil, fmt.Errorf("CompleteAuthToken: %w", windows.Errno(c))
+		}
+	default:
+		return nil, fmt.Errorf("InitializeSecurityContext: %w", windows.Errno(r))
+	}
+	n.hasContext = true
+	defer n.freeBuffer(outBuf)
+
+	if outBuf.buffer == nil {
+		return nil, nil
+	}
+	return append([]byte(nil), unsafe.Slice(outBuf.buffer, outBuf.size)...), nil
+}
+
+func (*sspiNegotiation) freeBuffer(b *secBuffer) {
+	if b.buffer != nil {
+		procFreeContextBuffer.Call(uintptr(unsafe.Pointer(b.buffer))) //nolint:errcheck // nothing to do if freeing fails
+		b.buffer = nil
+	}
+}
+
+func (n *sspiNegotiation) Close() error {
+	if n.hasContext {
+		procDeleteSecurityContext.Call(uintptr(unsafe.Pointer(&n.ctx))) //nolint:errcheck // nothing to do if freeing fails
+	}
+	procFreeCredentialsHandle.Call(uintptr(unsafe.Pointer(&n.cred))) //nolint:errcheck // nothing to do if freeing fails
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	PruneExpired = "expired" // PruneExpired the access token expired, the refresh token is kept to get a new one
+	PruneRevoked = "revoked" // PruneRevoked the server reports the token inactive, like when its service account is deleted
+)
+
+// PrunedCredential is a credential removed by PruneExpiredCredentials.
+type PrunedCredential struct {
+	Profile string
+	Key     string
+	Reason  string // Reason is PruneExpired or PruneRevoked
+}
+
+// PruneExpiredCredentials removes the access tokens that can't be used anymore from every profile of the config file,
+// so it doesn't accumulate dead secrets, and returns what was removed.
+// Expired access tokens are removed and their refresh tokens kept. Tokens without a refresh token, like the ones of
+// service accounts, are checked with IntrospectToken when online and removed if the server reports them inactive.
+// Read only profiles and tokens set outside the profile, like in environment variables, are left untouched.
+func PruneExpiredCredentials(ctx context.Context) ([]PrunedCredential, error) {
+	return Default().PruneExpiredCredentials(ctx)
+}
+func (p *Profile) PruneExpiredCredentials(ctx context.Context) ([]PrunedCredential, error) {
+	if p.IsEphemeral() {
+		return nil, ErrEphemeralProfile
+	}
+	if err := p.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	var pruned []PrunedCredential
+	for _, name := range List() {
+		profile := p.sibling(name)
+		if profile.IsReadOnly() || profile.Source(AccessTokenField) != SourceProfile {
+			continue
+		}
+		reason := profile.deadTokenReason(ctx)
+		if reason == "" {
+			continue
+		}
+		profile.SetAccessToken("")
+		if err := profile.Save(); err != nil {
+			return pruned, err
+		}
+		pruned = append(pruned, PrunedCredential{Profile: name, Key: AccessTokenField, Reason: reason})
+	}
+	return pruned, nil
+}
+
+// deadTokenReason returns why the access token of the profile can't be used anymore, empty if it can or if it's unknown.
+func (p *Profile) deadTokenReason(ctx context.Context) string {
+	c, err := p.tokenClaims()
+	if err != nil {
+		return ""
+	}
+	if c.ExpiresAt != nil && c.ExpiresAt.Before(time.Now()) {
+		return PruneExpired
+	}
+	if p.RefreshToken() != "" || p.Offline() {
+		return ""
+	}
+	i, err := p.IntrospectToken(ctx)
+	if err != nil || i.Active {
+		return ""
+	}
+	return PruneRevoked
+}
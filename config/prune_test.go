@@ -0,0 +1,92 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testExpiringToken(t *testing.T, subject string, expiry time.Time) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   subject,
+		ExpiresAt: jwt.NewNumericDate(expiry),
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	return token
+}
+
+func TestProfile_PruneExpiredCredentials(t *testing.T) {
+	expired := testExpiringToken(t, "user", time.Now().Add(-time.Hour))
+	valid := testExpiringToken(t, "user", time.Now().Add(time.Hour))
+	deleted := testExpiringToken(t, "deleted-service-account", time.Now().Add(time.Hour))
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		_, _ = fmt.Fprintf(w, `{"active": %t}`, r.PostForm.Get("token") != deleted)
+	}))
+	defer s.Close()
+
+	p := newTestProfile(t, fmt.Sprintf(`[default]
+  access_token = %[1]q
+  refresh_token = "refresh"
+
+[live]
+  access_token = %[2]q
+  refresh_token = "refresh"
+
+[service-account]
+  access_token = %[2]q
+  auth_url = %[4]q
+
+[deleted]
+  access_token = %[3]q
+  auth_url = %[4]q
+
+[locked]
+  access_token = %[1]q
+  read_only = true
+`, expired, valid, deleted, s.URL))
+
+	pruned, err := p.PruneExpiredCredentials(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []PrunedCredential{
+		{Profile: DefaultProfile, Key: AccessTokenField, Reason: PruneExpired},
+		{Profile: "deleted", Key: AccessTokenField, Reason: PruneRevoked},
+	}, pruned)
+
+	assert.Empty(t, p.AccessToken())
+	assert.Equal(t, "refresh", p.RefreshToken(), "refresh tokens are kept")
+	assert.Equal(t, valid, p.sibling("live").AccessToken())
+	assert.Equal(t, valid, p.sibling("service-account").AccessToken())
+	assert.Empty(t, p.sibling("deleted").AccessToken())
+	assert.Equal(t, expired, p.sibling("locked").AccessToken())
+
+	b, err := p.fileContents()
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(b), expired), "only the read only profile keeps the expired token")
+	assert.NotContains(t, string(b), deleted)
+}
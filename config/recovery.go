@@ -0,0 +1,155 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml"
+	"github.com/spf13/afero"
+)
+
+var ErrConfigCorrupted = errors.New("config file can't be parsed, run recovery to salvage it")
+
+// DroppedLine is a line of a corrupted config file that couldn't be salvaged.
+type DroppedLine struct {
+	Line    int    // Line number in the original file, starting at 1
+	Section string // Section is the table the line belonged to, empty for global settings
+	Content string
+}
+
+// RecoveryReport describes the outcome of Recover.
+type RecoveryReport struct {
+	QuarantinePath string // QuarantinePath is where the original file was moved to
+	Dropped        []DroppedLine
+}
+
+// Recover salvages the parseable parts of a corrupted config file.
+// The original file is moved next to it with a .corrupted suffix and replaced with everything that could be parsed,
+// the returned report lists the lines that were dropped.
+// When an integrity key is set the corrupted file must still match its signature, otherwise an IntegrityError
+// is returned and nothing is changed; call Sign first to trust the file before recovering it.
+func Recover() (*RecoveryReport, error) { return Default().Recover() }
+func (p *Profile) Recover() (*RecoveryReport, error) {
+	if p.IsEphemeral() {
+		return nil, ErrEphemeralProfile
+	}
+	if err := p.verifyIntegrity(); err != nil {
+		return nil, err
+	}
+	b, err := afero.ReadFile(p.fs, p.Filename())
+	if err != nil {
+		return nil, err
+	}
+
+	salvaged, dropped := salvage(string(b))
+
+	report := &RecoveryReport{
+		QuarantinePath: p.Filename() + ".corrupted-" + time.Now().UTC().Format("20060102T150405"),
+		Dropped:        dropped,
+	}
+	if err := p.fs.Rename(p.Filename(), report.QuarantinePath); err != nil {
+		return nil, err
+	}
+	if err := afero.WriteFile(p.fs, p.Filename(), []byte(salvaged), configPerm); err != nil {
+		return nil, err
+	}
+	if err := p.Sign(); err != nil {
+		return nil, err
+	}
+
+	return report, p.readConfig()
+}
+
+type tomlSection struct {
+	name  string
+	start int // index of the first line, the header for named sections
+	lines []string
+}
+
+func isValidTOML(s string) bool {
+	_, err := toml.Load(s)
+	return err == nil
+}
+
+// splitSections groups lines by table, the first section holds the global settings.
+func splitSections(lines []string) []tomlSection {
+	sections := []tomlSection{{}}
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			sections = append(sections, tomlSection{
+				name:  strings.Trim(trimmed, "[] "),
+				start: i,
+			})
+		}
+		s := &sections[len(sections)-1]
+		s.lines = append(s.lines, line)
+	}
+	return sections
+}
+
+// salvage keeps every section and line that can be parsed, section by section, and reports the rest.
+func salvage(contents string) (string, []DroppedLine) {
+	var kept []string
+	var dropped []DroppedLine
+
+	drop := func(s tomlSection, offset int, line string) {
+		if strings.TrimSpace(line) == "" {
+			return
+		}
+		dropped = append(dropped, DroppedLine{Line: s.start + offset + 1, Section: s.name, Content: line})
+	}
+	try := func(lines ...string) bool {
+		candidate := append(append([]string{}, kept...), lines...)
+		if !isValidTOML(strings.Join(candidate, "\n")) {
+			return false
+		}
+		kept = candidate
+		return true
+	}
+
+	for _, s := range splitSections(strings.Split(contents, "\n")) {
+		if try(s.lines...) {
+			continue
+		}
+
+		body := s.lines
+		if s.name != "" {
+			if !try(s.lines[0]) {
+				// invalid or duplicated header, nothing in the section can be trusted
+				for i, line := range s.lines {
+					drop(s, i, line)
+				}
+				continue
+			}
+			body = s.lines[1:]
+		}
+
+		for i, line := range body {
+			if !try(line) {
+				offset := i
+				if s.name != "" {
+					offset++
+				}
+				drop(s, offset, line)
+			}
+		}
+	}
+
+	return strings.Join(kept, "\n"), dropped
+}
@@ -0,0 +1,75 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const corruptedConfig = `skip_update_check = true
+
+[default]
+  org_id = "a"
+  project_id = "b
+
+[broken
+  org_id = "c"
+
+[other]
+  org_id = "d"
+`
+
+func Test_salvage(t *testing.T) {
+	got, dropped := salvage(corruptedConfig)
+
+	assert.True(t, isValidTOML(got))
+	assert.Contains(t, got, "skip_update_check = true")
+	assert.Contains(t, got, `org_id = "a"`)
+	assert.Contains(t, got, `org_id = "d"`)
+	assert.NotContains(t, got, "broken")
+	assert.Equal(t, []DroppedLine{
+		{Line: 5, Section: "default", Content: `  project_id = "b`},
+		{Line: 7, Section: "broken", Content: "[broken"},
+		{Line: 8, Section: "broken", Content: `  org_id = "c"`},
+	}, dropped)
+}
+
+func TestProfile_Recover(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	p := &Profile{
+		name:      DefaultProfile,
+		configDir: "/atlascli",
+		fs:        afero.NewMemMapFs(),
+	}
+	require.NoError(t, afero.WriteFile(p.fs, p.Filename(), []byte(corruptedConfig), configPerm))
+	require.ErrorIs(t, p.load(false, AtlasCLIEnvPrefix), ErrConfigCorrupted)
+
+	report, err := p.Recover()
+	require.NoError(t, err)
+	assert.Len(t, report.Dropped, 3)
+	assert.Equal(t, "a", p.OrgID())
+
+	quarantined, err := afero.ReadFile(p.fs, report.QuarantinePath)
+	require.NoError(t, err)
+	assert.Equal(t, corruptedConfig, string(quarantined))
+}
@@ -0,0 +1,378 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSecretReferenceTimeout limits how long resolving a secret reference can take, see SetSecretReferenceTimeout.
+const DefaultSecretReferenceTimeout = 30 * time.Second
+
+var (
+	ErrSecretReference        = errors.New("can't resolve secret reference")
+	ErrInvalidSecretReference = errors.New("invalid secret reference")
+)
+
+// SecretResolver returns the secret a reference like op://vault/item/field points to.
+// Resolvers must not read credentials from the config, the references are resolved one at a time.
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// LocalSecretResolver is implemented by resolvers that don't use the network, like pass,
+// which are still used in offline mode, see Offline.
+type LocalSecretResolver interface {
+	SecretResolver
+	Local() bool
+}
+
+func isLocalResolver(r SecretResolver) bool {
+	l, ok := r.(LocalSecretResolver)
+	return ok && l.Local()
+}
+
+// localResolver is a SecretResolverFunc that doesn't use the network.
+type localResolver struct {
+	SecretResolverFunc
+}
+
+func (localResolver) Local() bool { return true }
+
+// SecretResolverFunc is a function used as a SecretResolver.
+type SecretResolverFunc func(ctx context.Context, ref string) (string, error)
+
+func (f SecretResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+// secretResolvers are the resolvers of the secret references by URI scheme.
+var secretResolvers = struct {
+	sync.RWMutex
+	byScheme map[string]SecretResolver
+	timeout  time.Duration
+}{
+	byScheme: map[string]SecretResolver{
+		"op":      SecretResolverFunc(resolveOnePassword),
+		"azurekv": SecretResolverFunc(resolveAzureKeyVault),
+		"gcpsm":   SecretResolverFunc(resolveGCPSecretManager),
+		"pass":    localResolver{SecretResolverFunc(resolvePasswordStore)},
+	},
+	timeout: DefaultSecretReferenceTimeout,
+}
+
+// RegisterSecretResolver makes credentials like scheme://... resolve with r, replacing the resolver of the scheme if any.
+// The op, azurekv, gcpsm and pass schemes are registered by default, a nil resolver unregisters a scheme.
+func RegisterSecretResolver(scheme string, r SecretResolver) {
+	secretResolvers.Lock()
+	if r == nil {
+		delete(secretResolvers.byScheme, scheme)
+	} else {
+		secretResolvers.byScheme[scheme] = r
+	}
+	secretResolvers.Unlock()
+	invalidateReferences()
+}
+
+// SecretResolverSchemes returns the registered secret reference schemes.
+func SecretResolverSchemes() []string {
+	secretResolvers.RLock()
+	defer secretResolvers.RUnlock()
+	schemes := make([]string, 0, len(secretResolvers.byScheme))
+	for scheme := range secretResolvers.byScheme {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// SetSecretReferenceTimeout sets how long resolving a secret reference can take.
+func SetSecretReferenceTimeout(d time.Duration) {
+	secretResolvers.Lock()
+	defer secretResolvers.Unlock()
+	secretResolvers.timeout = d
+}
+
+func secretResolver(scheme string) (SecretResolver, time.Duration, bool) {
+	secretResolvers.RLock()
+	defer secretResolvers.RUnlock()
+	r, ok := secretResolvers.byScheme[scheme]
+	return r, secretResolvers.timeout, ok
+}
+
+// runCommand runs a command and returns its standard output, it's replaced in tests.
+var runCommand = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return out, nil
+}
+
+// resolveOnePassword reads a secret with the 1Password CLI, which must be signed in.
+func resolveOnePassword(ctx context.Context, ref string) (string, error) {
+	out, err := runCommand(ctx, "op", "read", "--no-newline", ref)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// referencePath splits the path of a reference like scheme://a/b/c, the optional last part is empty when missing.
+// Parts starting with "-" are rejected as they'd be read as flags by the commands resolving the reference.
+func referencePath(ref string, parts int) ([]string, error) {
+	_, p, _ := strings.Cut(ref, "://")
+	s := strings.Split(p, "/")
+	if len(s) == parts-1 {
+		s = append(s, "")
+	}
+	isFlag := func(part string) bool { return strings.HasPrefix(part, "-") }
+	if len(s) != parts || slices.Contains(s[:parts-1], "") || slices.ContainsFunc(s, isFlag) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSecretReference, ref)
+	}
+	return s, nil
+}
+
+// resolveAzureKeyVault reads a secret like azurekv://vault/secret[/version] with the Azure CLI,
+// using the credentials of az login, a managed identity or a service principal.
+func resolveAzureKeyVault(ctx context.Context, ref string) (string, error) {
+	p, err := referencePath(ref, 3)
+	if err != nil {
+		return "", err
+	}
+	id := fmt.Sprintf("https://%s.vault.azure.net/secrets/%s", p[0], p[1])
+	if p[2] != "" {
+		id += "/" + p[2]
+	}
+	out, err := runCommand(ctx, "az", "keyvault", "secret", "show", "--id", id, "--query", "value", "--output", "tsv")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// resolveGCPSecretManager reads a secret like gcpsm://project/secret[/version] with the Google Cloud CLI,
+// using the credentials of gcloud auth or the attached service account. The version defaults to latest.
+func resolveGCPSecretManager(ctx context.Context, ref string) (string, error) {
+	p, err := referencePath(ref, 3)
+	if err != nil {
+		return "", err
+	}
+	version := p[2]
+	if version == "" {
+		version = "latest"
+	}
+	out, err := runCommand(ctx, "gcloud", "secrets", "versions", "access", version, "--secret", p[1], "--project", p[0])
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// resolvePasswordStore reads a secret like pass://atlas/prod/private_key with pass, the standard Unix password store,
+// which decrypts it with gpg. Only the first line is used, like pass show --clip does.
+func resolvePasswordStore(ctx context.Context, ref string) (string, error) {
+	_, name, _ := strings.Cut(ref, "://")
+	if name == "" || strings.HasPrefix(name, "-") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidSecretReference, ref)
+	}
+	out, err := runCommand(ctx, "pass", "show", name)
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(string(out), "\n")
+	return strings.TrimRight(line, "\r"), nil
+}
+
+// referenceScheme returns the scheme of a secret reference, false if the value isn't a reference.
+func referenceScheme(v any) (string, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return "", false
+	}
+	scheme, _, ok := strings.Cut(s, "://")
+	if !ok {
+		return "", false
+	}
+	_, _, ok = secretResolver(scheme)
+	return scheme, ok
+}
+
+// referenceFailureTTL is how long a reference that failed to resolve isn't tried again, so a CLI that
+// isn't logged in isn't run, and waited for, on every credential lookup.
+const referenceFailureTTL = 30 * time.Second
+
+// referenceCache keeps the resolved references for the life of the process, see InvalidateSecrets.
+// Failures are kept for referenceFailureTTL only, so a reference failing because of an expired login
+// resolves again shortly after logging in, or right away after InvalidateSecrets.
+var referenceCache = struct {
+	sync.Mutex
+	values     map[string]cachedSecret
+	failures   map[string]referenceFailure
+	calls      map[string]*referenceCall
+	generation int // generation counts the invalidations, resolutions started before one aren't kept
+	now        func() time.Time
+}{values: map[string]cachedSecret{}, failures: map[string]referenceFailure{}, calls: map[string]*referenceCall{}, now: time.Now}
+
+// referenceFailure is the error of a reference that failed to resolve, returned until it expires.
+type referenceFailure struct {
+	err     error
+	expires time.Time
+}
+
+// referenceCall is a resolution in progress, shared by the concurrent lookups of the same reference.
+type referenceCall struct {
+	done  chan struct{}
+	value string
+	err   error
+}
+
+func invalidateReferences() {
+	referenceCache.Lock()
+	defer referenceCache.Unlock()
+	zeroSecrets(referenceCache.values)
+	referenceCache.values = map[string]cachedSecret{}
+	referenceCache.failures = map[string]referenceFailure{}
+	referenceCache.generation++
+}
+
+// resolveReference returns the secret a reference points to, in offline mode only local resolvers are used.
+func (p *Profile) resolveReference(ref string) (string, error) {
+	scheme, _, _ := strings.Cut(ref, "://")
+	if r, _, ok := secretResolver(scheme); ok && !isLocalResolver(r) {
+		if err := p.CheckOnline("resolving " + scheme + " secret references"); err != nil {
+			return "", err
+		}
+	}
+	return resolveReference(ref)
+}
+
+// resolveReference returns the secret a reference points to, resolving it once per process.
+// The resolver runs without holding the cache lock, so slow references don't delay the others,
+// and concurrent lookups of the same reference wait for the same resolution.
+func resolveReference(ref string) (string, error) {
+	referenceCache.Lock()
+	if s, ok := referenceCache.values[ref]; ok {
+		referenceCache.Unlock()
+		return s.value.Reveal(), nil
+	}
+	if f, ok := referenceCache.failures[ref]; ok && referenceCache.now().Before(f.expires) {
+		referenceCache.Unlock()
+		return "", f.err
+	}
+	if c, ok := referenceCache.calls[ref]; ok {
+		referenceCache.Unlock()
+		<-c.done
+		return c.value, c.err
+	}
+	// err is replaced by the result of the resolver, it's only returned if the resolver panics
+	c := &referenceCall{done: make(chan struct{}), err: fmt.Errorf("%w %q: resolver panicked", ErrSecretReference, ref)}
+	referenceCache.calls[ref] = c
+	generation := referenceCache.generation
+	referenceCache.Unlock()
+
+	defer func() {
+		referenceCache.Lock()
+		defer referenceCache.Unlock()
+		delete(referenceCache.calls, ref)
+		switch {
+		case generation != referenceCache.generation:
+		case c.err != nil:
+			referenceCache.failures[ref] = referenceFailure{err: c.err, expires: referenceCache.now().Add(referenceFailureTTL)}
+		default:
+			delete(referenceCache.failures, ref)
+			referenceCache.values[ref] = cachedSecret{value: NewSecret(c.value)}
+		}
+		close(c.done)
+	}()
+	c.value, c.err = lookupReference(ref)
+	return c.value, c.err
+}
+
+// lookupReference resolves a reference with the resolver of its scheme.
+func lookupReference(ref string) (string, error) {
+	scheme, _, _ := strings.Cut(ref, "://")
+	r, timeout, ok := secretResolver(scheme)
+	if !ok {
+		return "", fmt.Errorf("%w: no resolver for %q", ErrInvalidSecretReference, ref)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	value, err := r.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("%w %q: %w", ErrSecretReference, ref, err)
+	}
+	return value, nil
+}
+
+// resolveSecret returns the plain value of a credential, decrypting it or resolving the secret reference it holds.
+// Credentials that can't be resolved are treated as unset.
+func (p *Profile) resolveSecret(key string, v any) any {
+	if !slices.Contains(credentialProperties(), key) {
+		return v
+	}
+	revealed := revealSecret(key, v)
+	if revealed == nil && v != nil {
+		p.warn(Warning{Kind: WarningCredentials, Key: key, Message: "credential can't be decrypted, it was encrypted by another user or on another machine"})
+	}
+	if _, ok := referenceScheme(revealed); !ok {
+		return revealed
+	}
+	value, err := p.resolveReference(revealed.(string))
+	if err != nil {
+		p.warn(Warning{Kind: WarningCredentials, Key: key, Message: err.Error()})
+		return nil
+	}
+	return value
+}
+
+// SecretReference is a credential holding a secret reference, see ResolveSecrets.
+type SecretReference struct {
+	Key       string
+	Reference string
+	Err       error // Err is nil when the reference resolves
+}
+
+// ResolveSecrets resolves the secret references of the credentials of the profile to report which ones resolve,
+// without returning the secrets. The resolved secrets are cached for later use.
+func ResolveSecrets() []SecretReference { return Default().ResolveSecrets() }
+func (p *Profile) ResolveSecrets() []SecretReference {
+	var refs []SecretReference
+	for _, key := range credentialProperties() {
+		v := revealSecret(key, p.value(key))
+		if _, ok := referenceScheme(v); !ok {
+			continue
+		}
+		ref := v.(string)
+		_, err := p.resolveReference(ref)
+		refs = append(refs, SecretReference{Key: key, Reference: ref, Err: err})
+	}
+	return refs
+}
@@ -0,0 +1,252 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCommand replaces the commands resolving secret references with a function, and counts the calls.
+func fakeCommand(t *testing.T, run func(name string, args ...string) ([]byte, error)) *int {
+	t.Helper()
+	previous := runCommand
+	t.Cleanup(func() {
+		runCommand = previous
+		invalidateReferences()
+	})
+	invalidateReferences()
+
+	calls := 0
+	runCommand = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		calls++
+		return run(name, args...)
+	}
+	return &calls
+}
+
+func TestProfile_OnePasswordReference(t *testing.T) {
+	calls := fakeCommand(t, func(name string, args ...string) ([]byte, error) {
+		require.Equal(t, "op", name)
+		switch args[len(args)-1] {
+		case "op://atlas/prod/private":
+			return []byte("private"), nil
+		default:
+			return nil, errors.New("item not found")
+		}
+	})
+	p := newTestProfile(t, `[default]
+  private_api_key = "op://atlas/prod/private"
+  public_api_key = "op://atlas/prod/missing"
+  org_id = "op://not/a/credential"
+`)
+
+	require.Equal(t, "private", p.PrivateAPIKey())
+	require.Equal(t, "private", p.PrivateAPIKey())
+	require.Equal(t, 1, *calls, "references are resolved once")
+	require.Empty(t, p.PublicAPIKey())
+	require.Equal(t, "op://not/a/credential", p.OrgID())
+
+	p.InvalidateSecrets()
+	require.Equal(t, "private", p.PrivateAPIKey())
+	require.Equal(t, 3, *calls)
+}
+
+func TestCloudSecretReferences(t *testing.T) {
+	var commands [][]string
+	fakeCommand(t, func(name string, args ...string) ([]byte, error) {
+		commands = append(commands, append([]string{name}, args...))
+		return []byte("secret\n"), nil
+	})
+
+	tests := []struct {
+		ref  string
+		want []string
+	}{
+		{
+			ref:  "azurekv://atlas-vault/private-key",
+			want: []string{"az", "keyvault", "secret", "show", "--id", "https://atlas-vault.vault.azure.net/secrets/private-key", "--query", "value", "--output", "tsv"},
+		},
+		{
+			ref:  "azurekv://atlas-vault/private-key/0123abcd",
+			want: []string{"az", "keyvault", "secret", "show", "--id", "https://atlas-vault.vault.azure.net/secrets/private-key/0123abcd", "--query", "value", "--output", "tsv"},
+		},
+		{
+			ref:  "gcpsm://my-project/private-key",
+			want: []string{"gcloud", "secrets", "versions", "access", "latest", "--secret", "private-key", "--project", "my-project"},
+		},
+		{
+			ref:  "gcpsm://my-project/private-key/3",
+			want: []string{"gcloud", "secrets", "versions", "access", "3", "--secret", "private-key", "--project", "my-project"},
+		},
+	}
+	for _, tt := range tests {
+		commands = nil
+		value, err := resolveReference(tt.ref)
+		require.NoError(t, err, tt.ref)
+		require.Equal(t, "secret", value)
+		require.Equal(t, [][]string{tt.want}, commands)
+	}
+
+	for _, ref := range []string{
+		"azurekv://atlas-vault",
+		"gcpsm://my-project//3",
+		"gcpsm://a/b/c/d",
+		"gcpsm://my-project/private-key/--impersonate-service-account=x",
+		"azurekv://--help/private-key",
+	} {
+		_, err := resolveReference(ref)
+		require.ErrorIs(t, err, ErrInvalidSecretReference, ref)
+	}
+}
+
+func TestPasswordStoreReference(t *testing.T) {
+	fakeCommand(t, func(name string, args ...string) ([]byte, error) {
+		require.Equal(t, "pass", name)
+		require.Equal(t, []string{"show", "atlas/prod/private_key"}, args)
+		return []byte("private\nusername: atlas\n"), nil
+	})
+
+	value, err := resolveReference("pass://atlas/prod/private_key")
+	require.NoError(t, err)
+	require.Equal(t, "private", value)
+
+	_, err = resolveReference("pass://--help")
+	require.ErrorIs(t, err, ErrInvalidSecretReference)
+}
+
+func TestRegisterSecretResolver(t *testing.T) {
+	t.Cleanup(func() {
+		RegisterSecretResolver("vault", nil)
+		SetSecretReferenceTimeout(DefaultSecretReferenceTimeout)
+	})
+	require.NotContains(t, SecretResolverSchemes(), "vault")
+
+	RegisterSecretResolver("vault", SecretResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		if ref == "vault://slow" {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "from-vault", nil
+	}))
+	SetSecretReferenceTimeout(time.Millisecond)
+	require.Equal(t, []string{"azurekv", "gcpsm", "op", "pass", "vault"}, SecretResolverSchemes())
+
+	p := newTestProfile(t, `[default]
+  private_api_key = "vault://atlas/private"
+  public_api_key = "vault://slow"
+  access_token = "not a reference"
+`)
+	require.Equal(t, "from-vault", p.PrivateAPIKey())
+
+	refs := p.ResolveSecrets()
+	require.Len(t, refs, 2)
+	require.Equal(t, publicAPIKey, refs[0].Key)
+	require.Equal(t, "vault://slow", refs[0].Reference)
+	require.ErrorIs(t, refs[0].Err, ErrSecretReference)
+	require.ErrorIs(t, refs[0].Err, context.DeadlineExceeded)
+	require.Equal(t, SecretReference{Key: privateAPIKey, Reference: "vault://atlas/private"}, refs[1])
+}
+
+func TestResolveReference_failuresExpire(t *testing.T) {
+	now := time.Now()
+	referenceCache.now = func() time.Time { return now }
+	t.Cleanup(func() { referenceCache.now = time.Now })
+	loggedIn := false
+	calls := fakeCommand(t, func(string, ...string) ([]byte, error) {
+		if !loggedIn {
+			return nil, errors.New("AADSTS700082: the refresh token has expired, run az login")
+		}
+		return []byte("secret\n"), nil
+	})
+
+	_, err := resolveReference("azurekv://atlas-vault/private-key")
+	require.ErrorIs(t, err, ErrSecretReference)
+	_, err = resolveReference("azurekv://atlas-vault/private-key")
+	require.ErrorIs(t, err, ErrSecretReference)
+	require.Equal(t, 1, *calls, "failures are cached")
+
+	loggedIn = true
+	now = now.Add(referenceFailureTTL)
+	value, err := resolveReference("azurekv://atlas-vault/private-key")
+	require.NoError(t, err, "the reference resolves again once the failure expires")
+	require.Equal(t, "secret", value)
+	_, err = resolveReference("azurekv://atlas-vault/private-key")
+	require.NoError(t, err)
+	require.Equal(t, 2, *calls, "successes are cached")
+}
+
+func TestResolveReference_invalidateForgetsFailures(t *testing.T) {
+	loggedIn := false
+	calls := fakeCommand(t, func(string, ...string) ([]byte, error) {
+		if !loggedIn {
+			return nil, errors.New("AADSTS700082: the refresh token has expired, run az login")
+		}
+		return []byte("secret\n"), nil
+	})
+
+	_, err := resolveReference("azurekv://atlas-vault/private-key")
+	require.ErrorIs(t, err, ErrSecretReference)
+
+	loggedIn = true
+	p := NewEphemeralProfile(nil)
+	p.InvalidateSecrets()
+	value, err := resolveReference("azurekv://atlas-vault/private-key")
+	require.NoError(t, err, "the reference resolves right away after InvalidateSecrets")
+	require.Equal(t, "secret", value)
+	require.Equal(t, 2, *calls)
+}
+
+func TestResolveReference_concurrent(t *testing.T) {
+	var slowCalls atomic.Int32
+	release := make(chan struct{})
+	RegisterSecretResolver("test", SecretResolverFunc(func(_ context.Context, ref string) (string, error) {
+		if ref == "test://slow" {
+			slowCalls.Add(1)
+			<-release
+		}
+		return ref, nil
+	}))
+	t.Cleanup(func() { RegisterSecretResolver("test", nil) })
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := resolveReference("test://slow")
+			assert.NoError(t, err)
+			assert.Equal(t, "test://slow", value)
+		}()
+	}
+	require.Eventually(t, func() bool { return slowCalls.Load() == 1 }, time.Second, time.Millisecond)
+
+	value, err := resolveReference("test://fast")
+	require.NoError(t, err, "other references don't wait for the slow one")
+	require.Equal(t, "test://fast", value)
+
+	close(release)
+	wg.Wait()
+	require.Equal(t, int32(1), slowCalls.Load(), "concurrent lookups share the resolution")
+}
@@ -0,0 +1,142 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidResolve = errors.New("invalid resolve override, expected host:port:address")
+
+// ResolveOverride makes connections to a host and port go to another address, like curl's --resolve.
+type ResolveOverride struct {
+	Host    string
+	Port    string // Port is "*" to match any port
+	Address string // Address is the IP address or host name connected to instead
+}
+
+func (o ResolveOverride) String() string {
+	address := o.Address
+	if strings.Contains(address, ":") {
+		address = "[" + address + "]"
+	}
+	return o.Host + ":" + o.Port + ":" + address
+}
+
+// ParseResolveOverride parses an override in the host:port:address format of curl's --resolve,
+// port can be * to match any port and IPv6 addresses can be in brackets.
+func ParseResolveOverride(s string) (ResolveOverride, error) {
+	parts := strings.SplitN(strings.TrimSpace(s), ":", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+		return ResolveOverride{}, fmt.Errorf("%w: %q", ErrInvalidResolve, s)
+	}
+	o := ResolveOverride{
+		Host:    strings.ToLower(parts[0]),
+		Port:    parts[1],
+		Address: strings.TrimSuffix(strings.TrimPrefix(parts[2], "["), "]"),
+	}
+	if o.Port != "*" {
+		if port, err := strconv.Atoi(o.Port); err != nil || port <= 0 || port > 65535 {
+			return ResolveOverride{}, fmt.Errorf("%w: %q, invalid port", ErrInvalidResolve, s)
+		}
+	}
+	if strings.ContainsAny(o.Address, " /") {
+		return ResolveOverride{}, fmt.Errorf("%w: %q, invalid address", ErrInvalidResolve, s)
+	}
+	return o, nil
+}
+
+// ResolveOverrides gets the configured overrides of the addresses HttpClient connects to,
+// to reach split-horizon hosts or staging servers without editing the hosts file.
+// It can be set as an array or as a comma separated string, invalid overrides are ignored.
+func ResolveOverrides() []ResolveOverride { return Default().ResolveOverrides() }
+func (p *Profile) ResolveOverrides() []ResolveOverride {
+	var values []string
+	switch v := p.Get(resolve).(type) {
+	case string:
+		values = strings.Split(v, ",")
+	case []any:
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				values = append(values, s)
+			}
+		}
+	case []string:
+		values = v
+	}
+
+	overrides := make([]ResolveOverride, 0, len(values))
+	for _, v := range values {
+		if o, err := ParseResolveOverride(v); err == nil {
+			overrides = append(overrides, o)
+		}
+	}
+	return overrides
+}
+
+// SetResolveOverrides sets the overrides of the addresses HttpClient connects to, after checking their format.
+func SetResolveOverrides(v []string) error { return Default().SetResolveOverrides(v) }
+func (p *Profile) SetResolveOverrides(v []string) error {
+	values := make([]string, 0, len(v))
+	for _, s := range v {
+		o, err := ParseResolveOverride(s)
+		if err != nil {
+			return err
+		}
+		values = append(values, o.String())
+	}
+	p.Set(resolve, values)
+	return nil
+}
+
+// resolveDialer wraps dial to connect to the override of an address, if any.
+// An override for a specific port takes precedence over one for any port.
+func resolveDialer(
+	overrides []ResolveOverride,
+	dial func(ctx context.Context, network, addr string) (net.Conn, error),
+) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if len(overrides) == 0 {
+		return dial
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return dial(ctx, network, addr)
+		}
+		host = strings.ToLower(host)
+		var match *ResolveOverride
+		for i, o := range overrides {
+			if o.Host != host {
+				continue
+			}
+			if o.Port == port {
+				match = &overrides[i]
+				break
+			}
+			if o.Port == "*" && match == nil {
+				match = &overrides[i]
+			}
+		}
+		if match == nil {
+			return dial(ctx, network, addr)
+		}
+		return dial(ctx, network, net.JoinHostPort(match.Address, port))
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResolveOverride(t *testing.T) {
+	o, err := ParseResolveOverride("OM.example.com:8080:[::1]")
+	require.NoError(t, err)
+	assert.Equal(t, ResolveOverride{Host: "om.example.com", Port: "8080", Address: "::1"}, o)
+	assert.Equal(t, "om.example.com:8080:[::1]", o.String())
+
+	for _, s := range []string{"om.example.com", "om.example.com:http:10.0.0.1", ":443:10.0.0.1", "om.example.com:443:"} {
+		_, err := ParseResolveOverride(s)
+		require.ErrorIs(t, err, ErrInvalidResolve, s)
+	}
+}
+
+func TestProfile_ResolveOverrides(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Host))
+	}))
+	defer s.Close()
+	_, port, err := net.SplitHostPort(s.Listener.Addr().String())
+	require.NoError(t, err)
+
+	p := newTestProfile(t, "[default]\n  resolve = \"om.internal:*:127.0.0.1, invalid\"\n")
+	assert.Equal(t, []ResolveOverride{{Host: "om.internal", Port: "*", Address: "127.0.0.1"}}, p.ResolveOverrides())
+
+	resp, err := p.HttpClient().Get("http://om.internal:" + port + "/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.ErrorIs(t, p.SetResolveOverrides([]string{"invalid"}), ErrInvalidResolve)
+	require.NoError(t, p.SetResolveOverrides([]string{"om.internal:1:192.0.2.1", "om.internal:*:127.0.0.1"}))
+	var dialed []string
+	dial := resolveDialer(p.ResolveOverrides(), func(_ context.Context, _, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		return nil, net.ErrClosed
+	})
+	_, _ = dial(context.Background(), "tcp", "om.internal:1")
+	_, _ = dial(context.Background(), "tcp", "OM.internal:2")
+	_, _ = dial(context.Background(), "tcp", "other:1")
+	assert.Equal(t, []string{"192.0.2.1:1", "127.0.0.1:2", "other:1"}, dialed, "a specific port takes precedence")
+}
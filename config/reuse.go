@@ -0,0 +1,94 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CredentialReuseWarning describes credentials set in several profiles pointing at different services or organizations,
+// usually a copy-paste mistake that makes commands run against the wrong environment.
+type CredentialReuseWarning struct {
+	Key      string   // Key is public_api_key or access_token
+	Identity string   // Identity is the public API key or the subject of the access token, never a secret
+	Profiles []string // Profiles are sorted by name
+}
+
+func (w CredentialReuseWarning) String() string {
+	return fmt.Sprintf("the same %s %q is used by profiles %s pointing at different services or organizations",
+		w.Key, w.Identity, strings.Join(w.Profiles, ", "))
+}
+
+// CredentialReuseWarnings returns the API keys and access token subjects set in several profiles of the config file
+// with different services or organization IDs. Profiles without an organization ID only conflict on the service,
+// and credentials inherited from another profile are not reported.
+func CredentialReuseWarnings() []CredentialReuseWarning { return Default().CredentialReuseWarnings() }
+func (p *Profile) CredentialReuseWarnings() []CredentialReuseWarning {
+	type usage struct {
+		profiles []string
+		services map[ServiceType]bool
+		orgs     map[string]bool
+	}
+	type credential struct{ key, identity string }
+	usages := map[credential]*usage{}
+	var order []credential
+
+	for _, name := range List() {
+		profile := p.sibling(name)
+		for key, identity := range profile.credentialIdentities() {
+			id := credential{key, identity}
+			u, ok := usages[id]
+			if !ok {
+				u = &usage{services: map[ServiceType]bool{}, orgs: map[string]bool{}}
+				usages[id] = u
+				order = append(order, id)
+			}
+			u.profiles = append(u.profiles, name)
+			u.services[profile.CurrentService()] = true
+			if org := profile.OrgID(); org != "" {
+				u.orgs[org] = true
+			}
+		}
+	}
+
+	var warnings []CredentialReuseWarning
+	for _, id := range order {
+		u := usages[id]
+		if len(u.services) < 2 && len(u.orgs) < 2 {
+			continue
+		}
+		sort.Strings(u.profiles)
+		warnings = append(warnings, CredentialReuseWarning{Key: id.key, Identity: id.identity, Profiles: u.profiles})
+	}
+	return warnings
+}
+
+// credentialIdentities returns what identifies the credentials set in the profile itself, without the secrets.
+func (p *Profile) credentialIdentities() map[string]string {
+	ids := map[string]string{}
+	if _, owner := p.lookup(publicAPIKey); owner == p.Name() {
+		if v := p.PublicAPIKey(); v != "" {
+			ids[publicAPIKey] = v
+		}
+	}
+	if _, owner := p.lookup(AccessTokenField); owner == p.Name() {
+		if c, err := p.tokenClaims(); err == nil && c.Subject != "" {
+			ids[AccessTokenField] = c.Subject
+		}
+	}
+	return ids
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile_CredentialReuseWarnings(t *testing.T) {
+	token := testSubjectToken(t, "ci@example.com")
+	p := newTestProfile(t, fmt.Sprintf(`[default]
+  public_api_key = "abcdefgh"
+  org_id = %[1]q
+
+[prod]
+  public_api_key = "abcdefgh"
+  org_id = %[2]q
+
+[same-org]
+  public_api_key = "abcdefgh"
+
+[child]
+  inherits = "prod"
+  org_id = %[1]q
+
+[gov]
+  service = "cloudgov"
+  access_token = %[3]q
+
+[commercial]
+  access_token = %[3]q
+
+[other]
+  public_api_key = "zyxwvuts"
+  org_id = %[2]q
+`, testOrgA, testOrgB, token))
+
+	warnings := p.CredentialReuseWarnings()
+	assert.ElementsMatch(t, []CredentialReuseWarning{
+		{Key: publicAPIKey, Identity: "abcdefgh", Profiles: []string{"default", "prod", "same-org"}},
+		{Key: AccessTokenField, Identity: "ci@example.com", Profiles: []string{"commercial", "gov"}},
+	}, warnings, "inherited credentials are not reported")
+	for _, w := range warnings {
+		assert.NotContains(t, w.String(), token)
+	}
+}
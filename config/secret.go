@@ -0,0 +1,85 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// Secret holds a credential in memory, it's printed as "redacted" by fmt, slog and the JSON and text encoders,
+// and can be zeroed once it's not needed anymore to reduce its exposure in core dumps.
+// Zeroing is best effort, strings returned by Reveal can't be zeroed. The zero value is an empty secret.
+type Secret struct {
+	v *secretValue
+}
+
+type secretValue struct {
+	mu sync.Mutex
+	b  []byte
+}
+
+// NewSecret returns a Secret holding a copy of s.
+func NewSecret(s string) Secret {
+	return Secret{v: &secretValue{b: []byte(s)}}
+}
+
+// Reveal returns the secret, or an empty string once it's zeroed.
+func (s Secret) Reveal() string {
+	if s.v == nil {
+		return ""
+	}
+	s.v.mu.Lock()
+	defer s.v.mu.Unlock()
+	return string(s.v.b)
+}
+
+// IsEmpty returns true for empty or zeroed secrets.
+func (s Secret) IsEmpty() bool {
+	return s.Reveal() == ""
+}
+
+// Zero overwrites the secret, every copy of s is zeroed.
+func (s Secret) Zero() {
+	if s.v == nil {
+		return
+	}
+	s.v.mu.Lock()
+	defer s.v.mu.Unlock()
+	clear(s.v.b)
+	s.v.b = nil
+}
+
+func (Secret) String() string {
+	return redacted
+}
+
+func (Secret) GoString() string {
+	return redacted
+}
+
+// Format prints "redacted" for every verb, so %x or %v of a struct holding a Secret don't print it.
+func (Secret) Format(f fmt.State, _ rune) {
+	_, _ = f.Write([]byte(redacted))
+}
+
+func (Secret) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+func (Secret) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
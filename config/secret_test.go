@@ -0,0 +1,62 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecret(t *testing.T) {
+	s := NewSecret("password")
+	holder := struct{ Key Secret }{s}
+
+	assert.Equal(t, "password", s.Reveal())
+	for _, format := range []string{"%v", "%s", "%+v", "%#v", "%x", "%q"} {
+		assert.NotContains(t, fmt.Sprintf(format, holder), "password", format)
+	}
+	b, err := json.Marshal(holder)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Key": "redacted"}`, string(b))
+	var logs bytes.Buffer
+	slog.New(slog.NewTextHandler(&logs, nil)).Info("login", "key", s)
+	assert.Contains(t, logs.String(), "key=redacted")
+
+	holder.Key.Zero()
+	assert.True(t, s.IsEmpty(), "copies share the secret")
+	assert.Empty(t, s.Reveal())
+	assert.True(t, Secret{}.IsEmpty())
+	Secret{}.Zero()
+}
+
+func TestProfile_InvalidateSecrets_zeroes(t *testing.T) {
+	p := newTestProfile(t, "")
+	p.SetSecretStore(&memorySecretStore{secrets: map[string]string{}})
+	require.NoError(t, p.StoreSecret(privateAPIKey, "private"))
+	cached := p.secrets.values[secretCacheKey(p.Name(), privateAPIKey)].value
+	assert.Equal(t, "private", cached.Reveal())
+
+	p.InvalidateSecrets()
+	assert.True(t, cached.IsEmpty())
+	assert.Equal(t, "private", p.PrivateAPIKey(), "the secret is read again")
+}
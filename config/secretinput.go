@@ -0,0 +1,109 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// maxSecretSize limits what ReadSecretFrom reads, credentials are much smaller.
+const maxSecretSize = 16 << 10
+
+var (
+	ErrInvalidSecret  = errors.New("invalid secret")
+	ErrNotACredential = errors.New("setting is not a credential")
+)
+
+var (
+	publicAPIKeyRegex  = regexp.MustCompile(`^[a-zA-Z0-9]{8}$`)
+	privateAPIKeyRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	jwtRegex           = regexp.MustCompile(`^[\w-]+\.[\w-]+\.[\w-]*$`)
+)
+
+// ReadSecretFrom reads a secret from r, like standard input piped from a password manager or the clipboard,
+// so it doesn't land in the shell history as a command line argument would.
+// Only the first line is read and surrounding whitespace is trimmed, the buffers read into are zeroed.
+func ReadSecretFrom(r io.Reader) (string, error) {
+	buf := make([]byte, maxSecretSize+1)
+	defer clear(buf)
+
+	n := 0
+	for n < len(buf) && !bytes.ContainsRune(buf[:n], '\n') {
+		read, err := r.Read(buf[n:])
+		n += read
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	line, _, _ := bytes.Cut(buf[:n], []byte("\n"))
+	if len(line) > maxSecretSize {
+		return "", fmt.Errorf("%w: longer than %d bytes", ErrInvalidSecret, maxSecretSize)
+	}
+	secret := bytes.TrimSpace(line)
+	if len(secret) == 0 {
+		return "", fmt.Errorf("%w: empty", ErrInvalidSecret)
+	}
+	return string(secret), nil
+}
+
+// ValidateSecret checks the format of a credential, API keys of Atlas have a stricter format than the ones of Ops Manager.
+func ValidateSecret(key, value string) error { return Default().ValidateSecret(key, value) }
+func (p *Profile) ValidateSecret(key, value string) error {
+	var valid bool
+	switch key {
+	case publicAPIKey:
+		valid = !p.IsCloud() || publicAPIKeyRegex.MatchString(value)
+	case privateAPIKey:
+		valid = !p.IsCloud() || privateAPIKeyRegex.MatchString(value)
+	case AccessTokenField:
+		valid = jwtRegex.MatchString(value)
+	case RefreshTokenField:
+		valid = true
+	default:
+		return fmt.Errorf("%w: %q", ErrNotACredential, key)
+	}
+	// the value isn't part of the error, it's a secret
+	if !valid || value == "" || strings.ContainsAny(value, " \t\r\n") {
+		return fmt.Errorf("%w: unexpected format for %s", ErrInvalidSecret, key)
+	}
+	return nil
+}
+
+// SetSecretFrom reads a credential with ReadSecretFrom, checks its format and sets it,
+// to back commands like atlas config set private_api_key - reading the key from standard input.
+func SetSecretFrom(key string, r io.Reader) error { return Default().SetSecretFrom(key, r) }
+func (p *Profile) SetSecretFrom(key string, r io.Reader) error {
+	if !slices.Contains(credentialProperties(), key) {
+		return fmt.Errorf("%w: %q", ErrNotACredential, key)
+	}
+	secret, err := ReadSecretFrom(r)
+	if err != nil {
+		return err
+	}
+	if err := p.ValidateSecret(key, secret); err != nil {
+		return err
+	}
+	p.Set(key, secret)
+	return nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPrivateAPIKey = "8b7e5b1a-4b3c-4f0e-9d6a-2c1f0e9d8c7b"
+
+func TestReadSecretFrom(t *testing.T) {
+	s, err := ReadSecretFrom(strings.NewReader("  secret \r\nignored\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "secret", s)
+
+	s, err = ReadSecretFrom(iotest.OneByteReader(strings.NewReader("secret")))
+	require.NoError(t, err)
+	assert.Equal(t, "secret", s)
+
+	_, err = ReadSecretFrom(strings.NewReader("\n"))
+	require.ErrorIs(t, err, ErrInvalidSecret)
+	_, err = ReadSecretFrom(strings.NewReader(strings.Repeat("a", maxSecretSize+1)))
+	require.ErrorIs(t, err, ErrInvalidSecret)
+	_, err = ReadSecretFrom(iotest.ErrReader(iotest.ErrTimeout))
+	require.ErrorIs(t, err, iotest.ErrTimeout)
+}
+
+func TestProfile_SetSecretFrom(t *testing.T) {
+	p := newTestProfile(t, "")
+
+	require.NoError(t, p.SetSecretFrom(privateAPIKey, strings.NewReader(testPrivateAPIKey+"\n")))
+	assert.Equal(t, testPrivateAPIKey, p.PrivateAPIKey())
+
+	err := p.SetSecretFrom(privateAPIKey, strings.NewReader("not-a-key"))
+	require.ErrorIs(t, err, ErrInvalidSecret)
+	assert.NotContains(t, err.Error(), "not-a-key", "secrets are not part of errors")
+	require.ErrorIs(t, p.SetSecretFrom(publicAPIKey, strings.NewReader("user@example.com")), ErrInvalidSecret)
+	require.ErrorIs(t, p.SetSecretFrom(AccessTokenField, strings.NewReader("token")), ErrInvalidSecret)
+	require.ErrorIs(t, p.SetSecretFrom(projectID, strings.NewReader("id")), ErrNotACredential)
+
+	p.SetService(OpsManagerService)
+	require.NoError(t, p.SetSecretFrom(publicAPIKey, strings.NewReader("user@example.com")), "Ops Manager keys can be user names")
+	assert.Equal(t, "user@example.com", p.PublicAPIKey())
+}
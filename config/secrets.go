@@ -0,0 +1,167 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+var (
+	ErrSecretNotFound = errors.New("secret not found")
+	ErrNoSecretStore  = errors.New("no secret store configured")
+)
+
+// SecretStore keeps credentials outside the config file, like a system keychain.
+// Secret returns ErrSecretNotFound when there's no secret for the profile and key.
+type SecretStore interface {
+	Secret(profile, key string) (string, error)
+	SetSecret(profile, key, value string) error
+	DeleteSecret(profile, key string) error
+}
+
+type cachedSecret struct {
+	value Secret
+	err   error
+}
+
+// zeroSecrets zeroes the cached secrets before they are forgotten.
+func zeroSecrets(values map[string]cachedSecret) {
+	for _, s := range values {
+		s.value.Zero()
+	}
+}
+
+// secretCache reads each secret from the store at most once per process, stores like the macOS keychain
+// can prompt the user on every access. Profiles created from the same profile share the cache.
+type secretCache struct {
+	store  SecretStore
+	mu     sync.Mutex
+	values map[string]cachedSecret
+}
+
+func secretCacheKey(profile, key string) string {
+	return profile + "." + key
+}
+
+func (c *secretCache) get(profile, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.values[secretCacheKey(profile, key)]; ok {
+		return s.value.Reveal(), s.err
+	}
+
+	value, err := c.store.Secret(profile, key)
+	c.values[secretCacheKey(profile, key)] = cachedSecret{value: NewSecret(value), err: err}
+	return value, err
+}
+
+func (c *secretCache) set(profile, key, value string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.store.SetSecret(profile, key, value); err != nil {
+		return err
+	}
+	c.values[secretCacheKey(profile, key)].value.Zero()
+	c.values[secretCacheKey(profile, key)] = cachedSecret{value: NewSecret(value)}
+	return nil
+}
+
+func (c *secretCache) delete(profile, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.store.DeleteSecret(profile, key); err != nil && !errors.Is(err, ErrSecretNotFound) {
+		return err
+	}
+	c.values[secretCacheKey(profile, key)].value.Zero()
+	c.values[secretCacheKey(profile, key)] = cachedSecret{err: ErrSecretNotFound}
+	return nil
+}
+
+func (c *secretCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	zeroSecrets(c.values)
+	c.values = map[string]cachedSecret{}
+}
+
+// SetSecretStore sets the store credentials are read from when they are not set in the config file,
+// an environment variable or a flag. Secrets are only read when a credential is needed.
+func SetSecretStore(s SecretStore) { Default().SetSecretStore(s) }
+func (p *Profile) SetSecretStore(s SecretStore) {
+	if s == nil {
+		p.secrets = nil
+		return
+	}
+	p.secrets = &secretCache{store: s, values: map[string]cachedSecret{}}
+}
+
+// InvalidateSecrets forgets the secrets read from the secret store and the resolved secret references,
+// they are zeroed in memory and read again when needed.
+func InvalidateSecrets() { Default().InvalidateSecrets() }
+func (p *Profile) InvalidateSecrets() {
+	invalidateReferences()
+	if p.secrets != nil {
+		p.secrets.invalidate()
+	}
+}
+
+// StoreSecret saves a credential of the profile in the secret store instead of the config file.
+func StoreSecret(key, value string) error { return Default().StoreSecret(key, value) }
+func (p *Profile) StoreSecret(key, value string) error {
+	if err := p.checkSecretKey(key); err != nil {
+		return err
+	}
+	return p.secrets.set(p.Name(), key, value)
+}
+
+// DeleteStoredSecret removes a credential of the profile from the secret store.
+func DeleteStoredSecret(key string) error { return Default().DeleteStoredSecret(key) }
+func (p *Profile) DeleteStoredSecret(key string) error {
+	if err := p.checkSecretKey(key); err != nil {
+		return err
+	}
+	return p.secrets.delete(p.Name(), key)
+}
+
+func (p *Profile) checkSecretKey(key string) error {
+	if p.secrets == nil {
+		return ErrNoSecretStore
+	}
+	if !slices.Contains(credentialProperties(), key) {
+		return fmt.Errorf("%w: %q is not a credential", ErrInvalidValueType, key)
+	}
+	return nil
+}
+
+// storedSecret returns a credential from the secret store, following the profiles p inherits from.
+// Errors reading the store are treated as the secret not being there.
+func (p *Profile) storedSecret(key string) (string, bool) {
+	if p.secrets == nil || !slices.Contains(credentialProperties(), key) {
+		return "", false
+	}
+	for _, name := range p.chain() {
+		value, err := p.secrets.get(name, key)
+		if err == nil && value != "" {
+			return value, true
+		}
+		if err != nil && !errors.Is(err, ErrSecretNotFound) {
+			p.Logger().Debug("secret store can't be read", "profile", name, "key", key, "error", err)
+		}
+	}
+	return "", false
+}
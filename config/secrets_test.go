@@ -0,0 +1,83 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type memorySecretStore struct {
+	secrets map[string]string
+	reads   int
+}
+
+func (s *memorySecretStore) Secret(profile, key string) (string, error) {
+	s.reads++
+	v, ok := s.secrets[profile+"/"+key]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return v, nil
+}
+
+func (s *memorySecretStore) SetSecret(profile, key, value string) error {
+	s.secrets[profile+"/"+key] = value
+	return nil
+}
+
+func (s *memorySecretStore) DeleteSecret(profile, key string) error {
+	delete(s.secrets, profile+"/"+key)
+	return nil
+}
+
+func TestProfile_SecretStore(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  public_api_key = \"public\"\n")
+	store := &memorySecretStore{secrets: map[string]string{"default/private_api_key": "private"}}
+	p.SetSecretStore(store)
+
+	require.Equal(t, "public", p.PublicAPIKey())
+	require.Zero(t, store.reads, "secrets are only read when needed")
+
+	require.Equal(t, "private", p.PrivateAPIKey())
+	require.Equal(t, "private", p.PrivateAPIKey())
+	require.Equal(t, SourceSecret, p.Source(privateAPIKey))
+	require.Equal(t, 1, store.reads, "secrets are read once")
+
+	require.Empty(t, p.AccessToken())
+	require.Empty(t, p.AccessToken())
+	require.Equal(t, 2, store.reads, "missing secrets are cached too")
+
+	store.secrets["default/access_token"] = "token"
+	require.Empty(t, p.AccessToken())
+	p.InvalidateSecrets()
+	require.Equal(t, "token", p.AccessToken())
+
+	require.NoError(t, p.StoreSecret(RefreshTokenField, "refresh"))
+	require.Equal(t, "refresh", p.RefreshToken())
+	require.NoError(t, p.DeleteStoredSecret(RefreshTokenField))
+	require.Empty(t, p.RefreshToken())
+
+	require.ErrorIs(t, p.StoreSecret(orgID, "a"), ErrInvalidValueType)
+}
+
+func TestProfile_SecretStoreNotSet(t *testing.T) {
+	p := newTestProfile(t, "")
+	require.ErrorIs(t, p.StoreSecret(privateAPIKey, "a"), ErrNoSecretStore)
+	require.Empty(t, p.PrivateAPIKey())
+}
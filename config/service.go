@@ -0,0 +1,123 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+var ErrUnknownService = errors.New("unknown service")
+
+// ServiceType is one of the services a profile can target, see Services.
+type ServiceType string
+
+// ServiceCapabilities describes what a service supports.
+type ServiceCapabilities struct {
+	OAuth            bool   // OAuth the service supports logging in with OAuth tokens
+	ServiceAccounts  bool   // ServiceAccounts the service supports service account credentials
+	DefaultBaseURL   string // DefaultBaseURL is empty for self-hosted services, which require a base URL to be configured
+	UserAgentProduct string // UserAgentProduct is the product name sent in the User-Agent, AtlasCLI when empty
+	VersionedAPI     bool   // VersionedAPI the service selects the API version with the Accept header, see APIVersion
+}
+
+var serviceCapabilities = map[ServiceType]ServiceCapabilities{
+	CloudService: {
+		OAuth:           true,
+		ServiceAccounts: true,
+		DefaultBaseURL:  "https://cloud.mongodb.com/",
+		VersionedAPI:    true,
+	},
+	CloudGovService: {
+		OAuth:          true,
+		DefaultBaseURL: "https://cloud.mongodbgov.com/",
+		VersionedAPI:   true,
+	},
+	OpsManagerService: {},
+	CloudManagerService: {
+		DefaultBaseURL:   "https://cloud.mongodb.com/",
+		UserAgentProduct: MongoCLI,
+	},
+}
+
+// Services returns the supported services.
+func Services() []ServiceType {
+	return []ServiceType{
+		CloudService,
+		CloudGovService,
+		OpsManagerService,
+		CloudManagerService,
+	}
+}
+
+// ParseService validates a service name, an empty name is the default CloudService.
+func ParseService(s string) (ServiceType, error) {
+	if s == "" {
+		return CloudService, nil
+	}
+	service := ServiceType(strings.ToLower(s))
+	if _, ok := serviceCapabilities[service]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownService, s)
+	}
+	return service, nil
+}
+
+// Capabilities returns what the service supports, unknown services support nothing.
+func (s ServiceType) Capabilities() ServiceCapabilities {
+	return serviceCapabilities[s]
+}
+
+// IsCloud returns true for the services backed by Atlas.
+func (s ServiceType) IsCloud() bool {
+	return s == CloudService || s == CloudGovService
+}
+
+// CurrentService returns the configured service, unknown values are returned as is.
+func CurrentService() ServiceType { return Default().CurrentService() }
+func (p *Profile) CurrentService() ServiceType {
+	s, err := ParseService(p.Service())
+	if err != nil {
+		return ServiceType(p.Service())
+	}
+	return s
+}
+
+// SetValidService sets the service after checking it's supported.
+func SetValidService(v string) error { return Default().SetValidService(v) }
+func (p *Profile) SetValidService(v string) error {
+	s, err := ParseService(v)
+	if err != nil {
+		return err
+	}
+	if s == CloudGovService {
+		if err := p.checkCloudGov(); err != nil {
+			return err
+		}
+	}
+	p.SetService(string(s))
+	return nil
+}
+
+// UserAgent returns the User-Agent for requests made with the profile's service.
+func UserAgent(version string) string { return Default().UserAgent(version) }
+func (p *Profile) UserAgent(version string) string {
+	product := p.CurrentService().Capabilities().UserAgentProduct
+	if product == "" {
+		product = AtlasCLI
+	}
+	return fmt.Sprintf("%s/%s (%s;%s;%s)", product, version, runtime.GOOS, runtime.GOARCH, HostName)
+}
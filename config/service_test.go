@@ -0,0 +1,84 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseService(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    ServiceType
+		wantErr bool
+	}{
+		{input: "", want: CloudService},
+		{input: "cloud", want: CloudService},
+		{input: "CloudGov", want: CloudGovService},
+		{input: "ops-manager", want: OpsManagerService},
+		{input: "atlas", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseService(tt.input)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrUnknownService)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestServiceType_Capabilities(t *testing.T) {
+	assert.True(t, ServiceType(CloudService).Capabilities().ServiceAccounts)
+	assert.Equal(t, "https://cloud.mongodbgov.com/", ServiceType(CloudGovService).Capabilities().DefaultBaseURL)
+	assert.False(t, ServiceType(OpsManagerService).Capabilities().OAuth)
+	assert.False(t, ServiceType(OpsManagerService).IsCloud())
+	assert.Equal(t, ServiceCapabilities{}, ServiceType("unknown").Capabilities())
+}
+
+func TestProfile_SetValidService(t *testing.T) {
+	p := newTestProfile(t, "")
+	assert.Equal(t, ServiceType(CloudService), p.CurrentService())
+	assert.True(t, p.IsCloud())
+
+	require.ErrorIs(t, p.SetValidService("atlas"), ErrUnknownService)
+	require.NoError(t, p.SetValidService("Ops-Manager"))
+	assert.Equal(t, OpsManagerService, p.Service())
+	assert.False(t, p.IsCloud())
+}
+
+func TestProfile_CloudManager(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  service = \"cloud-manager\"\n  access_token = \"token\"\n")
+
+	assert.False(t, p.IsCloud())
+	assert.Equal(t, "https://cloud.mongodb.com/", p.HttpBaseURL())
+	assert.Regexp(t, `^mongocli/1\.0\.0 \(`, p.UserAgent("1.0.0"))
+	assert.Nil(t, p.HttpTransport(nil), "cloud manager doesn't support OAuth")
+
+	p.SetOpsManagerURL("https://cm.example.com")
+	assert.Equal(t, "https://cm.example.com/", p.HttpBaseURL())
+
+	p.SetService(CloudService)
+	assert.Regexp(t, `^atlascli/1\.0\.0 \(`, p.UserAgent("1.0.0"))
+}
@@ -0,0 +1,255 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Settings are the settings stored in a profile, see Unmarshal and Apply.
+// Unlike ProfileView, fields are empty when not set instead of holding the default values.
+type Settings struct {
+	Service         string `config:"service"`
+	OrgID           string `config:"org_id"`
+	ProjectID       string `config:"project_id"`
+	Output          string `config:"output"`
+	OpsManagerURL   string `config:"ops_manager_url"`
+	MongoShellPath  string `config:"mongosh_path"`
+	Inherits        string `config:"inherits"`
+	ReadOnly        bool   `config:"read_only"`
+	Environment     string `config:"environment"`
+	SkipUpdateCheck bool   `config:"skip_update_check"`
+
+	PublicAPIKey  string `config:"public_api_key"`
+	PrivateAPIKey string `config:"private_api_key"`
+	AccessToken   string `config:"access_token"`
+	RefreshToken  string `config:"refresh_token"`
+	ClientID      string `config:"client_id"`
+	AuthURL       string `config:"auth_url"`
+	TokenURL      string `config:"token_url"`
+	JWKSURL       string `config:"jwks_url"`
+
+	TelemetryEnabled bool `config:"telemetry_enabled"`
+	EncryptSecrets   bool `config:"encrypt_secrets"`
+	Offline          bool `config:"offline"`
+	FIPSMode         bool `config:"fips_mode"`
+	PreviewFeatures  bool `config:"preview_features"`
+	RecordActivity   bool `config:"record_activity"`
+	DryRun           bool `config:"dry_run"`
+
+	TelemetrySampleRate    float64       `config:"telemetry_sample_rate"`
+	TelemetryBatchSize     int           `config:"telemetry_batch_size"`
+	TelemetryFlushInterval time.Duration `config:"telemetry_flush_interval"`
+	CrashReports           bool          `config:"crash_reports"`
+	TelemetryConsent       string        `config:"telemetry_consent"`
+	TelemetrySpoolMaxSize  int64         `config:"telemetry_spool_max_size"`
+	TelemetrySpoolMaxAge   time.Duration `config:"telemetry_spool_max_age"`
+
+	Color      string `config:"color"`
+	Pager      string `config:"pager"`
+	Locale     string `config:"locale"`
+	TimeFormat string `config:"time_format"`
+	Verbosity  string `config:"verbosity"`
+
+	HTTPTimeout             time.Duration `config:"http_timeout"`
+	DialTimeout             time.Duration `config:"dial_timeout"`
+	ResponseHeaderTimeout   time.Duration `config:"response_header_timeout"`
+	IdleConnTimeout         time.Duration `config:"idle_conn_timeout"`
+	KeepAlive               time.Duration `config:"keep_alive"`
+	DisableKeepAlives       bool          `config:"disable_keep_alives"`
+	MaxIdleConnsPerHost     int           `config:"max_idle_conns_per_host"`
+	MaxConnsPerHost         int           `config:"max_conns_per_host"`
+	MaxRequestsPerHost      int           `config:"max_requests_per_host"`
+	MaxResponseSize         int64         `config:"max_response_size"`
+	CircuitBreakerThreshold int           `config:"circuit_breaker_threshold"`
+	CircuitBreakerCooldown  time.Duration `config:"circuit_breaker_cooldown"`
+	FallbackBaseURLs        []string      `config:"fallback_base_urls"`
+
+	ClientCertFile string   `config:"client_cert_file"`
+	ClientKeyFile  string   `config:"client_key_file"`
+	Proxy          string   `config:"proxy"`
+	ProxyAuth      string   `config:"proxy_auth"`
+	SSHJumpHost    string   `config:"ssh_jump_host"`
+	DemoFixtures   string   `config:"demo_fixtures"`
+	APIVersion     string   `config:"api_version"`
+	APIRegion      string   `config:"api_region"`
+	EndpointType   string   `config:"endpoint_type"`
+	IPFamily       string   `config:"ip_family"`
+	Resolve        []string `config:"resolve"`
+}
+
+// Unmarshal reads the settings of the profile into s, including the inherited ones and the ones set by
+// environment variables or flags. Values of the wrong type return a ConversionError.
+func Unmarshal(s *Settings) error { return Default().Unmarshal(s) }
+func (p *Profile) Unmarshal(s *Settings) error {
+	v := reflect.ValueOf(s).Elem()
+	for i := range v.NumField() {
+		key := v.Type().Field(i).Tag.Get("config")
+		value, err := convertSetting(key, p.configured(key), v.Field(i).Type())
+		if err != nil {
+			return err
+		}
+		v.Field(i).Set(value)
+	}
+	return nil
+}
+
+// Apply sets the non-empty fields of s in the profile, call Save to persist them.
+// Settings can't be unset or set to false with Apply, use Set for that.
+func Apply(s Settings) error { return Default().Apply(s) }
+func (p *Profile) Apply(s Settings) error {
+	if !p.IsEphemeral() {
+		if err := p.checkWritable(); err != nil {
+			return err
+		}
+	}
+	v := reflect.ValueOf(s)
+	for i := range v.NumField() {
+		field := v.Field(i)
+		if field.IsZero() {
+			continue
+		}
+		key := v.Type().Field(i).Tag.Get("config")
+		switch value := field.Interface().(type) {
+		case time.Duration:
+			p.Set(key, value.String())
+		default:
+			p.Set(key, value)
+		}
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// convertSetting converts a setting to the type of a Settings field, with the rules of the Get accessors.
+func convertSetting(key string, value any, t reflect.Type) (reflect.Value, error) {
+	if value == nil || value == "" {
+		return reflect.Zero(t), nil
+	}
+	var (
+		converted any
+		ok        bool
+	)
+	switch {
+	case t == durationType:
+		converted, ok = toDuration(value)
+	case t.Kind() == reflect.String:
+		s, err := formatSetting(key, value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		converted, ok = s, true
+	case t.Kind() == reflect.Bool:
+		converted, ok = toBool(value)
+	case t.Kind() == reflect.Float64:
+		converted, ok = toFloat(value)
+	case t.Kind() == reflect.Int, t.Kind() == reflect.Int64:
+		var i int64
+		if i, ok = toInt(value); ok {
+			return reflect.ValueOf(i).Convert(t), nil
+		}
+	case t.Kind() == reflect.Slice:
+		converted, ok = toStrings(value)
+	}
+	if !ok {
+		return reflect.Value{}, &ConversionError{Key: key, Value: value, Type: t.String()}
+	}
+	return reflect.ValueOf(converted), nil
+}
+
+func toBool(value any) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		if IsTrue(v) {
+			return true, true
+		}
+		b, err := strconv.ParseBool(v)
+		return b, err == nil
+	}
+	return false, false
+}
+
+func toInt(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func toDuration(value any) (time.Duration, bool) {
+	switch v := value.(type) {
+	case time.Duration:
+		return v, true
+	case int:
+		return time.Duration(v) * time.Second, true
+	case int64:
+		return time.Duration(v) * time.Second, true
+	case string:
+		d, err := time.ParseDuration(v)
+		return d, err == nil
+	}
+	return 0, false
+}
+
+func toStrings(value any) ([]string, bool) {
+	switch v := value.(type) {
+	case []string:
+		return v, true
+	case string:
+		values := strings.Split(v, ",")
+		for i := range values {
+			values[i] = strings.TrimSpace(values[i])
+		}
+		return values, true
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, s)
+		}
+		return values, true
+	}
+	return nil, false
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_Unmarshal(t *testing.T) {
+	p := newTestProfile(t, `[default]
+  service = "cloudgov"
+  project_id = "5e2211c17a3e5a48f5497de3"
+  http_timeout = "1m"
+  dial_timeout = 5
+  max_conns_per_host = "8"
+  max_response_size = 1024
+  preview_features = "true"
+  fallback_base_urls = "https://a.example.com/, https://b.example.com/"
+  resolve = ["om.internal:443:10.0.0.1"]
+`)
+
+	var s Settings
+	require.NoError(t, p.Unmarshal(&s))
+	assert.Equal(t, "cloudgov", s.Service)
+	assert.Equal(t, "5e2211c17a3e5a48f5497de3", s.ProjectID)
+	assert.Equal(t, time.Minute, s.HTTPTimeout)
+	assert.Equal(t, 5*time.Second, s.DialTimeout)
+	assert.Equal(t, 8, s.MaxConnsPerHost)
+	assert.Equal(t, int64(1024), s.MaxResponseSize)
+	assert.True(t, s.PreviewFeatures)
+	assert.Equal(t, []string{"https://a.example.com/", "https://b.example.com/"}, s.FallbackBaseURLs)
+	assert.Equal(t, []string{"om.internal:443:10.0.0.1"}, s.Resolve)
+	assert.Zero(t, s.IdleConnTimeout, "defaults are not applied")
+
+	p.Set(httpTimeout, "soon")
+	require.ErrorIs(t, p.Unmarshal(&s), ErrInvalidValueType)
+}
+
+func TestProfile_Apply(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  org_id = \"5e2211c17a3e5a48f5497de1\"\n")
+	require.NoError(t, p.Apply(Settings{
+		ProjectID:       "5e2211c17a3e5a48f5497de3",
+		HTTPTimeout:     time.Minute,
+		MaxConnsPerHost: 4,
+		Offline:         true,
+		Resolve:         []string{"om.internal:443:10.0.0.1"},
+	}))
+	assert.Equal(t, "5e2211c17a3e5a48f5497de1", p.OrgID(), "empty fields are not applied")
+	assert.Equal(t, "5e2211c17a3e5a48f5497de3", p.ProjectID())
+	assert.Equal(t, "1m0s", p.Get(httpTimeout))
+	assert.Equal(t, 4, p.MaxConnsPerHost())
+	assert.True(t, p.Offline())
+
+	var s Settings
+	require.NoError(t, p.Unmarshal(&s))
+	assert.Equal(t, time.Minute, s.HTTPTimeout)
+	assert.Equal(t, []string{"om.internal:443:10.0.0.1"}, s.Resolve)
+
+	p.Set(readOnly, true)
+	require.ErrorIs(t, p.Apply(Settings{ProjectID: "5e2211c17a3e5a48f5497de4"}), ErrProfileReadOnly)
+}
+
+func TestSettings_keys(t *testing.T) {
+	st := reflect.TypeOf(Settings{})
+	for i := range st.NumField() {
+		assert.Contains(t, Properties(), st.Field(i).Tag.Get("config"), st.Field(i).Name)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const DefaultMaxResponseSize = 0 // DefaultMaxResponseSize no limit
+
+var ErrResponseTooLarge = errors.New("response too large")
+
+// ResponseTooLargeError is returned when a response body is larger than MaxResponseSize,
+// either by the request when the response announces its size, or when reading the body.
+type ResponseTooLargeError struct {
+	URL   string
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("%v: %s returned more than %d bytes", ErrResponseTooLarge, e.URL, e.Limit)
+}
+
+func (*ResponseTooLargeError) Unwrap() error {
+	return ErrResponseTooLarge
+}
+
+// MaxResponseSize get the configured limit in bytes of the response bodies read by HttpClient, zero means no limit.
+func MaxResponseSize() int64 { return Default().MaxResponseSize() }
+func (p *Profile) MaxResponseSize() int64 {
+	return int64(p.GetIntWithDefault(maxResponseSize, DefaultMaxResponseSize))
+}
+
+// SetMaxResponseSize sets the limit in bytes of the response bodies.
+func SetMaxResponseSize(v int64) { Default().SetMaxResponseSize(v) }
+func (p *Profile) SetMaxResponseSize(v int64) {
+	p.Set(maxResponseSize, v)
+}
+
+type sizeLimitTransport struct {
+	base  http.RoundTripper
+	limit int64
+}
+
+// sizeLimitTransport wraps base to limit the size of the response bodies, if a limit is set.
+func (p *Profile) sizeLimitTransport(base http.RoundTripper) http.RoundTripper {
+	limit := p.MaxResponseSize()
+	if limit <= 0 {
+		return base
+	}
+	return &sizeLimitTransport{base: base, limit: limit}
+}
+
+func (t *sizeLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		return resp, err
+	}
+	tooLarge := &ResponseTooLargeError{URL: req.URL.Redacted(), Limit: t.limit}
+	if resp.ContentLength > t.limit {
+		resp.Body.Close()
+		return nil, tooLarge
+	}
+	resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: t.limit, err: tooLarge}
+	return resp, nil
+}
+
+func (t *sizeLimitTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
+
+// limitedBody fails reading once more than the limit was read.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	err       error
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, b.err
+	}
+	// read one byte over the limit to tell a body of exactly the limit from a larger one
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), b.err
+	}
+	return n, err
+}
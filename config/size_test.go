@@ -0,0 +1,63 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSizeLimitTransport(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := strings.Repeat("a", len(r.URL.Query().Get("size")))
+		if r.URL.Query().Has("chunked") {
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer s.Close()
+
+	p := newTestProfile(t, "")
+	assert.Equal(t, http.DefaultTransport, p.sizeLimitTransport(http.DefaultTransport), "no limit by default")
+	p.SetMaxResponseSize(4)
+	client := &http.Client{Transport: p.sizeLimitTransport(http.DefaultTransport)}
+
+	resp, err := client.Get(s.URL + "?size=xxxx")
+	require.NoError(t, err)
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "aaaa", string(b), "a body of the limit is read")
+
+	_, err = client.Get(s.URL + "?size=xxxxx")
+	require.ErrorIs(t, err, ErrResponseTooLarge, "the announced length is checked before reading")
+
+	resp, err = client.Get(s.URL + "?size=xxxxx&chunked")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err = io.ReadAll(resp.Body)
+	var tooLarge *ResponseTooLargeError
+	require.ErrorAs(t, err, &tooLarge)
+	assert.Equal(t, int64(4), tooLarge.Limit)
+	assert.Equal(t, "aaaa", string(b))
+}
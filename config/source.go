@@ -0,0 +1,131 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// SettingSource describes where the effective value of a setting comes from.
+type SettingSource string
+
+const (
+	SourceUnset       SettingSource = "unset"                 // SourceUnset the setting has no value
+	SourceFlag        SettingSource = "flag"                  // SourceFlag the setting comes from a command line flag, see BindFlags
+	SourceOrg         SettingSource = "org"                   // SourceOrg the setting is a default of the profile's organization, see SetOrgDefault
+	SourceState       SettingSource = "state"                 // SourceState the project was pushed with PushProject
+	SourceSet         SettingSource = "set"                   // SourceSet the setting was changed by this process and not saved yet
+	SourceEnv         SettingSource = "env"                   // SourceEnv the setting comes from an environment variable
+	SourceCredentials SettingSource = "credentials_directory" // SourceCredentials the setting comes from the systemd credentials directory
+	SourceGlobal      SettingSource = "global"                // SourceGlobal the setting is in the config file outside any profile
+	SourceProfile     SettingSource = "profile"               // SourceProfile the setting is in the profile
+	SourceInherited   SettingSource = "inherited"             // SourceInherited the setting is in a profile this profile inherits from, see InheritedFrom
+	SourceSecret      SettingSource = "secret"                // SourceSecret the setting comes from the secret store, see SetSecretStore
+	SourceDefault     SettingSource = "default"               // SourceDefault the setting has a default value, see RegisterDefault
+)
+
+// Source reports where the effective value of a setting, as returned by Get, comes from.
+// Settings are only read from the user's config file, there's no managed, system-wide config to report.
+func Source(key string) SettingSource { return Default().Source(key) }
+func (p *Profile) Source(key string) SettingSource {
+	key = strings.ToLower(key)
+	p.ensureLoaded()
+
+	if _, ok := p.flagValue(key); ok {
+		return SourceFlag
+	}
+	if _, ok := p.pushedProject(key); ok {
+		return SourceState
+	}
+	if _, ok := p.orgValue(p.currentOrg(key), key); ok {
+		return SourceOrg
+	}
+	if p.IsEphemeral() {
+		return p.memorySource(key)
+	}
+
+	if viper.IsSet(key) && viper.Get(key) != "" && !p.isProfileOverride(key) {
+		switch {
+		case p.isDirty(key):
+			return SourceSet
+		case p.isEnvSet(key):
+			return SourceEnv
+		case slices.Contains(p.credentialFiles, key):
+			return SourceCredentials
+		case p.snapshot != nil && p.snapshot.IsSet(key):
+			return SourceGlobal
+		default:
+			return SourceDefault
+		}
+	}
+
+	_, name := p.lookup(key)
+	switch {
+	case name == "":
+		if _, ok := p.storedSecret(key); ok {
+			return SourceSecret
+		}
+		if _, ok := p.flagDefault(key); ok {
+			return SourceDefault
+		}
+		if _, ok := DefaultValue(key); ok {
+			return SourceDefault
+		}
+		return SourceUnset
+	case name != p.Name():
+		return SourceInherited
+	case p.isDirty(p.Name() + "." + key):
+		return SourceSet
+	default:
+		return SourceProfile
+	}
+}
+
+func (p *Profile) isDirty(key string) bool {
+	_, ok := p.dirty[key]
+	return ok
+}
+
+// envVarName returns the environment variable viper reads for a key.
+func (p *Profile) envVarName(key string) string {
+	name := strings.ToUpper(p.envPrefix + "_" + key)
+	if p.envKeyReplacer != nil {
+		name = p.envKeyReplacer.Replace(name)
+	}
+	return name
+}
+
+func (p *Profile) isEnvSet(key string) bool {
+	if p.isPlainEnvSet(key) {
+		return true
+	}
+	if p.envPrefix == "" || !slices.Contains(fileEnvProperties(), key) {
+		return false
+	}
+	return os.Getenv(p.envVarName(key)+envFileSuffix) != ""
+}
+
+func (p *Profile) isPlainEnvSet(key string) bool {
+	for _, name := range p.EnvVarNames(key) {
+		if value, ok := os.LookupEnv(name); ok && value != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_Source(t *testing.T) {
+	t.Setenv("MONGODB_ATLAS_ORG_ID", "env-org")
+	p := newTestProfile(t, `
+skip_update_check = true
+
+[base]
+  service = "cloudgov"
+
+[default]
+  inherits = "base"
+  output = "json"
+  org_id = "file-org"
+`)
+	require.NoError(t, p.load(true, AtlasCLIEnvPrefix))
+	p.SetProjectID("5e2211c17a3e5a48f5497de3")
+
+	tests := map[string]SettingSource{
+		orgID:           SourceEnv,
+		skipUpdateCheck: SourceGlobal,
+		output:          SourceProfile,
+		service:         SourceInherited,
+		projectID:       SourceSet,
+		privateAPIKey:   SourceUnset,
+	}
+	for key, want := range tests {
+		assert.Equal(t, want, p.Source(key), key)
+	}
+
+	require.Equal(t, "env-org", p.OrgID())
+}
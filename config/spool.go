@@ -0,0 +1,183 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	telemetrySpoolPrefix = "telemetry-"
+	telemetrySpoolSuffix = ".jsonl"
+	telemetrySpoolMonth  = "2006-01"
+)
+
+var ErrTelemetrySpoolFull = errors.New("telemetry spool is full")
+
+// telemetrySpool serializes the writes to the telemetry spool of the process.
+var telemetrySpool sync.Mutex
+
+// TelemetrySpoolFilename returns the file keeping the telemetry events of a month, next to the config file,
+// so users can see what is collected, see AppendTelemetrySpool.
+func TelemetrySpoolFilename(month time.Time) string { return Default().TelemetrySpoolFilename(month) }
+func (p *Profile) TelemetrySpoolFilename(month time.Time) string {
+	return filepath.Join(p.configDir, telemetrySpoolPrefix+month.UTC().Format(telemetrySpoolMonth)+telemetrySpoolSuffix)
+}
+
+// AppendTelemetrySpool adds records, encoded as JSON, to the telemetry spool of the month of t.
+// The spools older than TelemetrySpoolMaxAge are deleted, then the oldest ones while the spools would exceed
+// TelemetrySpoolMaxSize. ErrTelemetrySpoolFull is returned, and nothing is written, when the spool of the month
+// of t alone would exceed it.
+func AppendTelemetrySpool(t time.Time, records ...any) error {
+	return Default().AppendTelemetrySpool(t, records...)
+}
+func (p *Profile) AppendTelemetrySpool(t time.Time, records ...any) error {
+	var lines []byte
+	for _, r := range records {
+		b, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		lines = append(append(lines, b...), '\n')
+	}
+
+	telemetrySpool.Lock()
+	defer telemetrySpool.Unlock()
+	if err := p.fs.MkdirAll(p.configDir, defaultPermissions); err != nil {
+		return err
+	}
+	if err := p.pruneTelemetrySpools(t, int64(len(lines))); err != nil {
+		return err
+	}
+	f, err := p.fs.OpenFile(p.TelemetrySpoolFilename(t), os.O_APPEND|os.O_CREATE|os.O_WRONLY, configPerm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(lines)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// pruneTelemetrySpools makes room for size more bytes in the spool of the month of t, see AppendTelemetrySpool.
+func (p *Profile) pruneTelemetrySpools(t time.Time, size int64) error {
+	matches, err := afero.Glob(p.fs, filepath.Join(p.configDir, telemetrySpoolPrefix+"*"+telemetrySpoolSuffix))
+	if err != nil {
+		return err
+	}
+	// the names sort by month, oldest first
+	slices.Sort(matches)
+
+	filename := p.TelemetrySpoolFilename(t)
+	maxAge := p.TelemetrySpoolMaxAge()
+	type spool struct {
+		name string
+		size int64
+	}
+	var spools []spool
+	total, current := size, size
+	for _, name := range matches {
+		m := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(name), telemetrySpoolPrefix), telemetrySpoolSuffix)
+		month, err := time.Parse(telemetrySpoolMonth, m)
+		if err != nil {
+			continue
+		}
+		// the events of a month are all too old once the month ended more than maxAge ago
+		if name != filename && maxAge > 0 && month.AddDate(0, 1, 0).Before(t.Add(-maxAge)) {
+			if err := p.removeTelemetrySpool(name); err != nil {
+				return err
+			}
+			continue
+		}
+		info, err := p.fs.Stat(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		if name == filename {
+			current += info.Size()
+			continue
+		}
+		spools = append(spools, spool{name: name, size: info.Size()})
+	}
+
+	maxSize := p.TelemetrySpoolMaxSize()
+	if maxSize <= 0 {
+		return nil
+	}
+	if current > maxSize {
+		return fmt.Errorf("%w: the limit is %d bytes", ErrTelemetrySpoolFull, maxSize)
+	}
+	for _, s := range spools {
+		if total <= maxSize {
+			break
+		}
+		if err := p.removeTelemetrySpool(s.name); err != nil {
+			return err
+		}
+		total -= s.size
+	}
+	return nil
+}
+
+func (p *Profile) removeTelemetrySpool(name string) error {
+	if err := p.fs.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// ReadTelemetrySpool returns the records of the telemetry spool of the month of t, oldest first.
+func ReadTelemetrySpool(t time.Time) ([]json.RawMessage, error) {
+	return Default().ReadTelemetrySpool(t)
+}
+func (p *Profile) ReadTelemetrySpool(t time.Time) ([]json.RawMessage, error) {
+	f, err := p.fs.Open(p.TelemetrySpoolFilename(t))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []json.RawMessage
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		if !json.Valid(scanner.Bytes()) {
+			// a line cut by a crash doesn't hide the rest of the spool
+			continue
+		}
+		records = append(records, json.RawMessage(append([]byte(nil), scanner.Bytes()...)))
+	}
+	return records, scanner.Err()
+}
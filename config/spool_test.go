@@ -0,0 +1,119 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_TelemetrySpool(t *testing.T) {
+	p := newTestProfile(t, "")
+	march := time.Date(2024, time.March, 15, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, "/atlascli/telemetry-2024-03.jsonl", p.TelemetrySpoolFilename(march))
+
+	records, err := p.ReadTelemetrySpool(march)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	january := march.AddDate(0, -2, 0)
+	require.NoError(t, p.AppendTelemetrySpool(january, map[string]string{"command": "old"}))
+	require.NoError(t, p.AppendTelemetrySpool(march.AddDate(0, -1, 0), map[string]string{"command": "previous"}))
+	require.NoError(t, p.AppendTelemetrySpool(march, map[string]string{"command": "a"}, map[string]string{"command": "b"}))
+	f, err := p.fs.OpenFile(p.TelemetrySpoolFilename(march), os.O_APPEND|os.O_WRONLY, configPerm)
+	require.NoError(t, err)
+	_, err = f.WriteString("{\"command\": \"cut\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.NoError(t, p.AppendTelemetrySpool(march, map[string]string{"command": "c"}))
+
+	records, err = p.ReadTelemetrySpool(march)
+	require.NoError(t, err)
+	var commands []string
+	for _, r := range records {
+		var record map[string]string
+		require.NoError(t, json.Unmarshal(r, &record))
+		commands = append(commands, record["command"])
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, commands, "truncated lines are skipped")
+
+	exists, err := afero.Exists(p.fs, p.TelemetrySpoolFilename(january))
+	require.NoError(t, err)
+	assert.False(t, exists, "spools older than telemetry_spool_max_age are deleted")
+	exists, err = afero.Exists(p.fs, p.TelemetrySpoolFilename(march.AddDate(0, -1, 0)))
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestProfile_TelemetrySpool_limits(t *testing.T) {
+	p := newTestProfile(t, "")
+	march := time.Date(2024, time.March, 15, 10, 0, 0, 0, time.UTC)
+	exists := func(month time.Time) bool {
+		t.Helper()
+		ok, err := afero.Exists(p.fs, p.TelemetrySpoolFilename(month))
+		require.NoError(t, err)
+		return ok
+	}
+	record := map[string]string{"command": "0123456789"} // 25 bytes with the newline
+
+	t.Run("old spools are deleted", func(t *testing.T) {
+		p.SetTelemetrySpoolMaxAge(10 * 24 * time.Hour)
+		require.NoError(t, p.AppendTelemetrySpool(march.AddDate(0, -1, 0), record))
+		require.NoError(t, p.AppendTelemetrySpool(march.AddDate(0, 0, -10), record))
+		assert.True(t, exists(march.AddDate(0, -1, 0)), "february ended less than 10 days before march 5")
+
+		require.NoError(t, p.AppendTelemetrySpool(march, record))
+		assert.False(t, exists(march.AddDate(0, -1, 0)), "february ended more than 10 days before march 15")
+		assert.True(t, exists(march))
+	})
+
+	t.Run("oversized spools are deleted, oldest first", func(t *testing.T) {
+		p.SetTelemetrySpoolMaxAge(0)
+		p.SetTelemetrySpoolMaxSize(100)
+		april, may := march.AddDate(0, 1, 0), march.AddDate(0, 2, 0)
+		require.NoError(t, p.AppendTelemetrySpool(april, record))
+		require.NoError(t, p.AppendTelemetrySpool(may, record))
+		assert.True(t, exists(march), "the spools have 100 bytes")
+
+		require.NoError(t, p.AppendTelemetrySpool(may, record))
+		assert.False(t, exists(march))
+		assert.True(t, exists(april))
+
+		require.NoError(t, p.AppendTelemetrySpool(may, record, record))
+		assert.False(t, exists(april))
+		records, err := p.ReadTelemetrySpool(may)
+		require.NoError(t, err)
+		assert.Len(t, records, 4)
+	})
+
+	t.Run("appends beyond the limit are refused", func(t *testing.T) {
+		june := march.AddDate(0, 3, 0)
+		require.ErrorIs(t, p.AppendTelemetrySpool(june, record, record, record, record, record), ErrTelemetrySpoolFull)
+		assert.False(t, exists(june))
+
+		require.ErrorIs(t, p.AppendTelemetrySpool(march.AddDate(0, 2, 0), record), ErrTelemetrySpoolFull)
+		records, err := p.ReadTelemetrySpool(march.AddDate(0, 2, 0))
+		require.NoError(t, err)
+		assert.Len(t, records, 4, "the spool is unchanged")
+	})
+}
@@ -0,0 +1,85 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"slices"
+)
+
+var ErrProjectStackEmpty = errors.New("no project to pop")
+
+// PushProject makes the project the current one until PopProject is called, without changing the project_id of the profile,
+// so scripts can switch to a project and restore the previous one. The stack is kept in the state file, see StateFilename.
+// Flags and environment variables still take precedence over it.
+func PushProject(id string) error { return Default().PushProject(id) }
+func (p *Profile) PushProject(id string) error {
+	if err := p.validateID(id, IsValidProjectID, ErrInvalidProjectID); err != nil {
+		return err
+	}
+	if p.CurrentService() == CloudGovService {
+		if err := p.checkCloudGovID(projectID, id); err != nil {
+			return err
+		}
+	}
+	old := p.ProjectID()
+	err := p.updateState(func(s *profileState) error {
+		s.ProjectStack = append(s.ProjectStack, id)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	p.notifyChange(projectID, old, p.ProjectID())
+	return nil
+}
+
+// PopProject restores the project that was current before the last PushProject and returns the project it removes.
+func PopProject() (string, error) { return Default().PopProject() }
+func (p *Profile) PopProject() (string, error) {
+	var id string
+	old := p.ProjectID()
+	err := p.updateState(func(s *profileState) error {
+		if len(s.ProjectStack) == 0 {
+			return ErrProjectStackEmpty
+		}
+		id = s.ProjectStack[len(s.ProjectStack)-1]
+		s.ProjectStack = s.ProjectStack[:len(s.ProjectStack)-1]
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	p.notifyChange(projectID, old, p.ProjectID())
+	return id, nil
+}
+
+// ProjectStack returns the projects pushed with PushProject, the current one last.
+func ProjectStack() []string { return Default().ProjectStack() }
+func (p *Profile) ProjectStack() []string {
+	return slices.Clone(p.profileState().ProjectStack)
+}
+
+// pushedProject returns the project on top of the stack unless the project is set in the environment.
+func (p *Profile) pushedProject(name string) (string, bool) {
+	if name != projectID || p.isEnvSet(name) {
+		return "", false
+	}
+	stack := p.profileState().ProjectStack
+	if len(stack) == 0 {
+		return "", false
+	}
+	return stack[len(stack)-1], true
+}
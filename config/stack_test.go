@@ -0,0 +1,71 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testProject1 = "5e2211c17a3e5a48f5497de1"
+	testProject2 = "5e2211c17a3e5a48f5497de2"
+)
+
+func TestProfile_PushProject(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  project_id = \""+testProject1+"\"\n")
+
+	require.ErrorIs(t, p.PushProject("invalid"), ErrInvalidProjectID)
+	require.NoError(t, p.PushProject(testProject2))
+	assert.Equal(t, testProject2, p.ProjectID())
+	assert.Equal(t, SourceState, p.Source(projectID))
+	assert.Equal(t, []string{testProject2}, p.ProjectStack())
+
+	b, err := afero.ReadFile(p.fs, p.Filename())
+	require.NoError(t, err)
+	assert.Contains(t, string(b), testProject1, "the config file isn't changed")
+
+	assert.Equal(t, testProject2, p.sibling(DefaultProfile).ProjectID(), "the stack is kept between runs")
+
+	id, err := p.PopProject()
+	require.NoError(t, err)
+	assert.Equal(t, testProject2, id)
+	assert.Equal(t, testProject1, p.ProjectID())
+	assert.Equal(t, SourceProfile, p.Source(projectID))
+
+	_, err = p.PopProject()
+	require.ErrorIs(t, err, ErrProjectStackEmpty)
+	exists, err := afero.Exists(p.fs, p.StateFilename())
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestProfile_ProjectStackFollowsProfile(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  project_id = \""+testProject1+"\"\n")
+	require.NoError(t, p.PushProject(testProject2))
+	require.NoError(t, p.Rename("renamed"))
+
+	renamed := p.sibling("renamed")
+	assert.Equal(t, []string{testProject2}, renamed.ProjectStack())
+	assert.Empty(t, p.sibling(DefaultProfile).ProjectStack())
+
+	require.NoError(t, renamed.Delete())
+	assert.Empty(t, p.sibling("renamed").ProjectStack())
+}
@@ -0,0 +1,134 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+const stateFilename = "state.json"
+
+// profileState is what is remembered about a profile between runs without changing the config file.
+type profileState struct {
+	ProjectStack []string `json:"project_stack,omitempty"`
+}
+
+func (s *profileState) isEmpty() bool {
+	return len(s.ProjectStack) == 0
+}
+
+// StateFilename returns the file keeping the state of the profiles, like the project stack, next to the config file.
+func StateFilename() string { return Default().StateFilename() }
+func (p *Profile) StateFilename() string {
+	return filepath.Join(p.configDir, stateFilename)
+}
+
+func (p *Profile) readStates() (map[string]*profileState, error) {
+	states := map[string]*profileState{}
+	b, err := afero.ReadFile(p.fs, p.StateFilename())
+	if errors.Is(err, fs.ErrNotExist) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// profileState returns the state of the profile, the state file is read once.
+func (p *Profile) profileState() *profileState {
+	if p.state != nil && p.state.name == p.Name() {
+		return &p.state.profileState
+	}
+	p.state = &cachedState{name: p.Name()}
+	if states, err := p.readStates(); err == nil && states[p.Name()] != nil {
+		p.state.profileState = *states[p.Name()]
+	}
+	return &p.state.profileState
+}
+
+// updateState reads the state file again, so changes made by other processes are kept,
+// edits the state of the profile and writes the file.
+func (p *Profile) updateState(edit func(*profileState) error) error {
+	return p.updateStates(func(states map[string]*profileState) error {
+		s := states[p.Name()]
+		if s == nil {
+			s = &profileState{}
+		}
+		if err := edit(s); err != nil {
+			return err
+		}
+		if s.isEmpty() {
+			delete(states, p.Name())
+		} else {
+			states[p.Name()] = s
+		}
+		return nil
+	})
+}
+
+func (p *Profile) updateStates(edit func(map[string]*profileState) error) error {
+	states, err := p.readStates()
+	if err != nil {
+		return err
+	}
+	if err := edit(states); err != nil {
+		return err
+	}
+	p.state = nil
+
+	if len(states) == 0 {
+		if err := p.fs.Remove(p.StateFilename()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	b, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := p.fs.MkdirAll(p.configDir, defaultPermissions); err != nil {
+		return err
+	}
+	return afero.WriteFile(p.fs, p.StateFilename(), b, configPerm)
+}
+
+// renameState moves the state of the profile when it's renamed, or drops it when newName is empty.
+func (p *Profile) renameState(newName string) error {
+	return p.updateStates(func(states map[string]*profileState) error {
+		s, ok := states[p.Name()]
+		if !ok {
+			return nil
+		}
+		delete(states, p.Name())
+		if newName != "" {
+			states[newName] = s
+		}
+		return nil
+	})
+}
+
+type cachedState struct {
+	name string
+	profileState
+}
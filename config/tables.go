@@ -0,0 +1,155 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// tableSpan is a table of a TOML document, from its header line to the line before the next table.
+// Comments right before the next table are left out, they usually describe that table.
+type tableSpan struct {
+	start, end int
+	root       string // root is the first key of the table name, the profile name
+	rootStart  int    // rootStart is the offset of the root key in the header line
+	rootEnd    int
+}
+
+// parseTableHeader returns the root key of a table header line like [name] or [name.sub] # comment,
+// with its position in the line.
+func parseTableHeader(line string) (string, int, int, bool) {
+	trimmed := strings.TrimLeft(line, " \t")
+	if !strings.HasPrefix(trimmed, "[") {
+		return "", 0, 0, false
+	}
+	i := len(line) - len(trimmed)
+	for i < len(line) && (line[i] == '[' || line[i] == ' ' || line[i] == '\t') {
+		i++
+	}
+	if i == len(line) {
+		return "", 0, 0, false
+	}
+
+	start := i
+	if q := line[i]; q == '"' || q == '\'' {
+		end := strings.IndexByte(line[i+1:], q)
+		if end < 0 {
+			return "", 0, 0, false
+		}
+		return line[i+1 : i+1+end], start, i + end + 2, true
+	}
+	end := strings.IndexAny(line[i:], ".] \t")
+	if end < 0 {
+		return "", 0, 0, false
+	}
+	return line[i : i+end], start, i + end, true
+}
+
+// isMultilineDelimiter returns true when a line opens or closes a multi-line string.
+func isMultilineDelimiter(line string) bool {
+	return (strings.Count(line, `"""`)+strings.Count(line, `'''`))%2 == 1
+}
+
+func findTables(lines []string) []tableSpan {
+	var spans []tableSpan
+	inString := false
+	for i, line := range lines {
+		if isMultilineDelimiter(line) {
+			inString = !inString
+			continue
+		}
+		if inString {
+			continue
+		}
+		root, rootStart, rootEnd, ok := parseTableHeader(line)
+		if !ok {
+			continue
+		}
+		if len(spans) > 0 {
+			spans[len(spans)-1].end = tableEnd(lines, i)
+		}
+		spans = append(spans, tableSpan{
+			start:     i,
+			end:       len(lines),
+			root:      strings.ToLower(root),
+			rootStart: rootStart,
+			rootEnd:   rootEnd,
+		})
+	}
+	return spans
+}
+
+// tableEnd returns where the table before the header at index next ends, leaving out the comments right before the header.
+func tableEnd(lines []string, next int) int {
+	end := next
+	for end > 0 && strings.HasPrefix(strings.TrimSpace(lines[end-1]), "#") {
+		end--
+	}
+	return end
+}
+
+// removeTables drops the tables of a profile, including its sub tables and the comments right before them,
+// and leaves the rest of the document as is.
+func removeTables(contents, name string) string {
+	lines := strings.Split(contents, "\n")
+	spans := findTables(lines)
+
+	kept := make([]string, 0, len(lines))
+	last := 0
+	for _, s := range spans {
+		if s.root != name {
+			continue
+		}
+		kept = append(kept, lines[last:max(last, tableEnd(lines, s.start))]...)
+		last = s.end
+	}
+	kept = append(kept, lines[last:]...)
+	return strings.Join(kept, "\n")
+}
+
+// renameTables renames the tables of a profile, replacing any tables of a profile that already has the new name.
+func renameTables(contents, name, newName string) string {
+	if name != newName {
+		contents = removeTables(contents, newName)
+	}
+
+	lines := strings.Split(contents, "\n")
+	for _, s := range findTables(lines) {
+		if s.root == name {
+			line := lines[s.start]
+			lines[s.start] = line[:s.rootStart] + tomlKey(newName) + line[s.rootEnd:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tomlKey quotes a key unless it's a valid bare key.
+func tomlKey(key string) string {
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return strconv.Quote(key)
+		}
+	}
+	return key
+}
+
+// hasProfile returns true when a TOML document has settings for the profile.
+func hasProfile(contents, name string) bool {
+	t, err := toml.Load(contents)
+	return err == nil && t.Has(name)
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const multiProfileConfig = `# global settings
+skip_update_check = true
+
+[default]
+  org_id = "a" # main org
+  description = """
+[not a table]
+"""
+
+[default.labels]
+  env = "prod"
+
+# work profile, managed by IT
+[ "work" ]
+  org_id = "b"
+
+[other]
+  org_id = "c"
+`
+
+func Test_removeTables(t *testing.T) {
+	assert.Equal(t, `# global settings
+skip_update_check = true
+
+# work profile, managed by IT
+[ "work" ]
+  org_id = "b"
+
+[other]
+  org_id = "c"
+`, removeTables(multiProfileConfig, "default"))
+
+	assert.Equal(t, `# global settings
+skip_update_check = true
+
+[default]
+  org_id = "a" # main org
+  description = """
+[not a table]
+"""
+
+[default.labels]
+  env = "prod"
+
+[other]
+  org_id = "c"
+`, removeTables(multiProfileConfig, "work"), "comments right before the table are removed with it")
+
+	assert.Equal(t, multiProfileConfig, removeTables(multiProfileConfig, "missing"))
+}
+
+func Test_renameTables(t *testing.T) {
+	got := renameTables(multiProfileConfig, "default", "work")
+	assert.Equal(t, `# global settings
+skip_update_check = true
+
+[work]
+  org_id = "a" # main org
+  description = """
+[not a table]
+"""
+
+[work.labels]
+  env = "prod"
+
+[other]
+  org_id = "c"
+`, got)
+}
+
+func TestProfile_Delete_preservesContent(t *testing.T) {
+	p := newTestProfile(t, multiProfileConfig)
+	p.name = "work"
+
+	require.NoError(t, p.Delete())
+	b, err := afero.ReadFile(p.fs, p.Filename())
+	require.NoError(t, err)
+	assert.Equal(t, removeTables(multiProfileConfig, "work"), string(b))
+	assert.Contains(t, string(b), `  org_id = "a" # main org`)
+}
+
+func TestProfile_Rename_fallback(t *testing.T) {
+	p := newTestProfile(t, "default.org_id = \"a\"\n\n[other]\n  org_id = \"c\"\n")
+
+	require.NoError(t, p.Rename("renamed"))
+	b, err := afero.ReadFile(p.fs, p.Filename())
+	require.NoError(t, err)
+	assert.True(t, hasProfile(string(b), "renamed"))
+	assert.False(t, hasProfile(string(b), "default"))
+	assert.True(t, hasProfile(string(b), "other"))
+}
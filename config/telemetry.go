@@ -0,0 +1,161 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const DoNotTrackEnv = "DO_NOT_TRACK" // DoNotTrackEnv disables telemetry regardless of telemetry_enabled, see https://consoledonottrack.com
+
+const (
+	DefaultTelemetrySampleRate    = 1.0                 // DefaultTelemetrySampleRate every command is reported
+	DefaultTelemetryBatchSize     = 20                  // DefaultTelemetryBatchSize events sent together
+	DefaultTelemetryFlushInterval = 10 * time.Second    // DefaultTelemetryFlushInterval longest time events wait to be sent
+	DefaultTelemetrySpoolMaxSize  = 1 << 20             // DefaultTelemetrySpoolMaxSize bytes of spooled events kept on disk
+	DefaultTelemetrySpoolMaxAge   = 31 * 24 * time.Hour // DefaultTelemetrySpoolMaxAge how long spooled events are kept on disk
+)
+
+var ErrInvalidSampleRate = errors.New("invalid telemetry sample rate, expected a number between 0 and 1")
+
+// SourceDoNotTrack telemetry is disabled by DoNotTrackEnv.
+const SourceDoNotTrack SettingSource = "do_not_track"
+
+var ErrTelemetryOverridden = errors.New("telemetry_enabled is overridden")
+
+// TelemetryOverriddenError is returned when enabling telemetry has no effect because something else disables it.
+type TelemetryOverriddenError struct {
+	Source SettingSource
+}
+
+func (e *TelemetryOverriddenError) Error() string {
+	if e.Source == SourceDoNotTrack {
+		return fmt.Sprintf("%v: telemetry is disabled by the %s environment variable", ErrTelemetryOverridden, DoNotTrackEnv)
+	}
+	return fmt.Sprintf("%v: telemetry is disabled by %s", ErrTelemetryOverridden, e.Source)
+}
+
+func (*TelemetryOverriddenError) Unwrap() error {
+	return ErrTelemetryOverridden
+}
+
+// TelemetryStatus describes whether telemetry is enabled and what decided it.
+type TelemetryStatus struct {
+	Enabled bool
+	Source  SettingSource // Source is SourceDoNotTrack, SourceDefault or where telemetry_enabled is set, see Source
+}
+
+// TelemetryPolicy reports whether telemetry is enabled and the source that decided it.
+// DO_NOT_TRACK takes precedence over the telemetry_enabled setting.
+func TelemetryPolicy() TelemetryStatus { return Default().TelemetryPolicy() }
+func (p *Profile) TelemetryPolicy() TelemetryStatus {
+	if !isTelemetryFeatureAllowed() {
+		return TelemetryStatus{Enabled: false, Source: SourceDoNotTrack}
+	}
+	if p.Offline() {
+		return TelemetryStatus{Enabled: false, Source: p.Source(offline)}
+	}
+
+	source := p.Source(TelemetryEnabledProperty)
+	if source == SourceUnset {
+		source = SourceDefault
+	}
+	return TelemetryStatus{Enabled: p.TelemetryEnabled(), Source: source}
+}
+
+// checkTelemetryOverride warns when telemetry_enabled is true but DoNotTrackEnv disables telemetry.
+func (p *Profile) checkTelemetryOverride() {
+	if isTelemetryFeatureAllowed() {
+		return
+	}
+	enabled, _ := p.configured(TelemetryEnabledProperty).(bool)
+	if s, ok := p.configured(TelemetryEnabledProperty).(string); ok {
+		enabled = IsTrue(s)
+	}
+	if enabled {
+		p.warn(Warning{Kind: WarningPolicy, Key: TelemetryEnabledProperty, Message: (&TelemetryOverriddenError{Source: SourceDoNotTrack}).Error()})
+	}
+}
+
+// TelemetrySampleRate get the share of the telemetry events that are sent, between 0 and 1.
+// Invalid values are DefaultTelemetrySampleRate.
+func TelemetrySampleRate() float64 { return Default().TelemetrySampleRate() }
+func (p *Profile) TelemetrySampleRate() float64 {
+	v, ok := toFloat(p.Get(telemetrySampleRate))
+	if !ok || v < 0 || v > 1 {
+		return DefaultTelemetrySampleRate
+	}
+	return v
+}
+
+// SetTelemetrySampleRate sets the share of the telemetry events that are sent, like 0.1 for one in ten.
+func SetTelemetrySampleRate(v float64) error { return Default().SetTelemetrySampleRate(v) }
+func (p *Profile) SetTelemetrySampleRate(v float64) error {
+	if v < 0 || v > 1 {
+		return fmt.Errorf("%w: %v", ErrInvalidSampleRate, v)
+	}
+	p.SetGlobal(telemetrySampleRate, v)
+	return nil
+}
+
+// TelemetryBatchSize get the number of telemetry events sent together, at least 1.
+func TelemetryBatchSize() int { return Default().TelemetryBatchSize() }
+func (p *Profile) TelemetryBatchSize() int {
+	return max(p.GetIntWithDefault(telemetryBatchSize, DefaultTelemetryBatchSize), 1)
+}
+
+// SetTelemetryBatchSize sets the number of telemetry events sent together.
+func SetTelemetryBatchSize(v int) { Default().SetTelemetryBatchSize(v) }
+func (p *Profile) SetTelemetryBatchSize(v int) {
+	p.SetGlobal(telemetryBatchSize, v)
+}
+
+// TelemetryFlushInterval get the longest time telemetry events wait for a full batch before being sent.
+func TelemetryFlushInterval() time.Duration { return Default().TelemetryFlushInterval() }
+func (p *Profile) TelemetryFlushInterval() time.Duration {
+	return p.GetDurationWithDefault(telemetryFlushInterval, DefaultTelemetryFlushInterval)
+}
+
+// SetTelemetryFlushInterval sets the longest time telemetry events wait for a full batch before being sent.
+func SetTelemetryFlushInterval(v time.Duration) { Default().SetTelemetryFlushInterval(v) }
+func (p *Profile) SetTelemetryFlushInterval(v time.Duration) {
+	p.SetGlobal(telemetryFlushInterval, v.String())
+}
+
+// TelemetrySpoolMaxSize get the limit in bytes of the telemetry events kept on disk, zero means no limit.
+func TelemetrySpoolMaxSize() int64 { return Default().TelemetrySpoolMaxSize() }
+func (p *Profile) TelemetrySpoolMaxSize() int64 {
+	return int64(p.GetIntWithDefault(telemetrySpoolMaxSize, DefaultTelemetrySpoolMaxSize))
+}
+
+// SetTelemetrySpoolMaxSize sets the limit in bytes of the telemetry events kept on disk.
+func SetTelemetrySpoolMaxSize(v int64) { Default().SetTelemetrySpoolMaxSize(v) }
+func (p *Profile) SetTelemetrySpoolMaxSize(v int64) {
+	p.SetGlobal(telemetrySpoolMaxSize, v)
+}
+
+// TelemetrySpoolMaxAge get how long the telemetry events are kept on disk, zero means no limit.
+func TelemetrySpoolMaxAge() time.Duration { return Default().TelemetrySpoolMaxAge() }
+func (p *Profile) TelemetrySpoolMaxAge() time.Duration {
+	return p.GetDurationWithDefault(telemetrySpoolMaxAge, DefaultTelemetrySpoolMaxAge)
+}
+
+// SetTelemetrySpoolMaxAge sets how long the telemetry events are kept on disk.
+func SetTelemetrySpoolMaxAge(v time.Duration) { Default().SetTelemetrySpoolMaxAge(v) }
+func (p *Profile) SetTelemetrySpoolMaxAge(v time.Duration) {
+	p.SetGlobal(telemetrySpoolMaxAge, v.String())
+}
@@ -0,0 +1,105 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_TelemetryPolicy(t *testing.T) {
+	t.Setenv(DoNotTrackEnv, "")
+	p := newTestProfile(t, "")
+	assert.Equal(t, TelemetryStatus{Enabled: true, Source: SourceDefault}, p.TelemetryPolicy())
+
+	require.NoError(t, p.SetTelemetryEnabled(false))
+	assert.Equal(t, TelemetryStatus{Enabled: false, Source: SourceSet}, p.TelemetryPolicy())
+	require.NoError(t, p.Save())
+	assert.Equal(t, TelemetryStatus{Enabled: false, Source: SourceGlobal}, p.TelemetryPolicy())
+
+	t.Setenv(DoNotTrackEnv, "1")
+	err := p.SetTelemetryEnabled(true)
+	require.ErrorIs(t, err, ErrTelemetryOverridden)
+	var overridden *TelemetryOverriddenError
+	require.ErrorAs(t, err, &overridden)
+	assert.Equal(t, SourceDoNotTrack, overridden.Source)
+	assert.Contains(t, err.Error(), DoNotTrackEnv)
+	assert.Equal(t, TelemetryStatus{Enabled: false, Source: SourceDoNotTrack}, p.TelemetryPolicy())
+
+	require.NoError(t, p.SetTelemetryEnabled(false), "disabling doesn't conflict with DO_NOT_TRACK")
+}
+
+func TestProfile_ProfileOverridableProperties(t *testing.T) {
+	t.Setenv(DoNotTrackEnv, "")
+	p := newTestProfile(t, "telemetry_enabled = true\nskip_update_check = false\n\n[default]\n  telemetry_enabled = false\n")
+
+	assert.False(t, p.TelemetryEnabled(), "the profile takes precedence over the global setting")
+	assert.Equal(t, TelemetryStatus{Enabled: false, Source: SourceProfile}, p.TelemetryPolicy())
+	assert.False(t, p.SkipUpdateCheck())
+
+	p.SetProfileSkipUpdateCheck(true)
+	assert.True(t, p.SkipUpdateCheck())
+	p.SetSkipUpdateCheck(false)
+	assert.True(t, p.SkipUpdateCheck(), "changing the global setting doesn't affect the profile")
+
+	t.Setenv("MONGODB_ATLAS_TELEMETRY_ENABLED", "true")
+	require.NoError(t, p.load(true, AtlasCLIEnvPrefix))
+	assert.True(t, p.TelemetryEnabled(), "the environment takes precedence over the profile")
+	assert.Equal(t, SourceEnv, p.Source(TelemetryEnabledProperty))
+}
+
+func TestProfile_TelemetryBatching(t *testing.T) {
+	p := newTestProfile(t, "telemetry_sample_rate = 0.5\ntelemetry_batch_size = 0\n")
+	assert.InDelta(t, 0.5, p.TelemetrySampleRate(), 0)
+	assert.Equal(t, 1, p.TelemetryBatchSize(), "batches have one event at least")
+	assert.Equal(t, DefaultTelemetryFlushInterval, p.TelemetryFlushInterval())
+
+	require.ErrorIs(t, p.SetTelemetrySampleRate(1.5), ErrInvalidSampleRate)
+	require.NoError(t, p.SetTelemetrySampleRate(0))
+	assert.Zero(t, p.TelemetrySampleRate())
+	p.SetGlobal(telemetrySampleRate, "2")
+	assert.InDelta(t, DefaultTelemetrySampleRate, p.TelemetrySampleRate(), 0, "invalid values are the default")
+
+	p.SetTelemetryBatchSize(50)
+	p.SetTelemetryFlushInterval(time.Minute)
+	assert.Equal(t, 50, p.TelemetryBatchSize())
+	assert.Equal(t, time.Minute, p.TelemetryFlushInterval())
+
+	var s Settings
+	p.SetGlobal(telemetrySampleRate, 0.1)
+	require.NoError(t, p.Unmarshal(&s))
+	assert.InDelta(t, 0.1, s.TelemetrySampleRate, 0)
+}
+
+func TestProfile_TelemetrySpoolLimits(t *testing.T) {
+	p := newTestProfile(t, "telemetry_spool_max_age = \"48h\"\n")
+	assert.Equal(t, int64(DefaultTelemetrySpoolMaxSize), p.TelemetrySpoolMaxSize())
+	assert.Equal(t, 48*time.Hour, p.TelemetrySpoolMaxAge())
+
+	p.SetTelemetrySpoolMaxSize(4096)
+	p.SetTelemetrySpoolMaxAge(0)
+	assert.Equal(t, int64(4096), p.TelemetrySpoolMaxSize())
+	assert.Zero(t, p.TelemetrySpoolMaxAge(), "zero means no limit")
+
+	var s Settings
+	require.NoError(t, p.Unmarshal(&s))
+	assert.Equal(t, int64(4096), s.TelemetrySpoolMaxSize)
+	assert.Zero(t, s.TelemetrySpoolMaxAge)
+}
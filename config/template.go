@@ -0,0 +1,165 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"github.com/spf13/afero"
+)
+
+const (
+	templatesDir      = "templates"
+	templateExtension = ".toml"
+)
+
+var (
+	ErrInvalidTemplateName = errors.New("template name should not be empty or contain path separators")
+	ErrTemplateNotFound    = errors.New("template not found")
+	ErrProfileExists       = errors.New("profile already exists")
+)
+
+// Template is a profile skeleton used to create similar profiles, it never holds credentials.
+type Template struct {
+	Service       string `toml:"service,omitempty"`
+	OpsManagerURL string `toml:"ops_manager_url,omitempty"`
+	Output        string `toml:"output,omitempty"`
+	OrgID         string `toml:"org_id,omitempty"`
+}
+
+func validateTemplateName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("%w: %q", ErrInvalidTemplateName, name)
+	}
+	return nil
+}
+
+func (p *Profile) templateFilename(name string) string {
+	return filepath.Join(p.configDir, templatesDir, name+templateExtension)
+}
+
+// Templates returns the names of the templates stored in the config directory.
+func Templates() ([]string, error) { return Default().Templates() }
+func (p *Profile) Templates() ([]string, error) {
+	entries, err := afero.ReadDir(p.fs, filepath.Join(p.configDir, templatesDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), templateExtension) {
+			names = append(names, strings.TrimSuffix(e.Name(), templateExtension))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// LoadTemplate reads a template from the config directory.
+func LoadTemplate(name string) (*Template, error) { return Default().LoadTemplate(name) }
+func (p *Profile) LoadTemplate(name string) (*Template, error) {
+	if err := validateTemplateName(name); err != nil {
+		return nil, err
+	}
+
+	b, err := afero.ReadFile(p.fs, p.templateFilename(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %q", ErrTemplateNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Template{}
+	if err := toml.Unmarshal(b, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SaveTemplate stores a template in the config directory, replacing any template with the same name.
+func SaveTemplate(name string, t *Template) error { return Default().SaveTemplate(name, t) }
+func (p *Profile) SaveTemplate(name string, t *Template) error {
+	if err := validateTemplateName(name); err != nil {
+		return err
+	}
+
+	b, err := toml.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if err := p.fs.MkdirAll(filepath.Join(p.configDir, templatesDir), defaultPermissions); err != nil {
+		return err
+	}
+	return afero.WriteFile(p.fs, p.templateFilename(name), b, configPerm)
+}
+
+// DeleteTemplate removes a template from the config directory.
+func DeleteTemplate(name string) error { return Default().DeleteTemplate(name) }
+func (p *Profile) DeleteTemplate(name string) error {
+	if err := validateTemplateName(name); err != nil {
+		return err
+	}
+
+	err := p.fs.Remove(p.templateFilename(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %q", ErrTemplateNotFound, name)
+	}
+	return err
+}
+
+// NewProfileFromTemplate creates a new profile with the settings of the given template.
+// The profile is only written to disk once Save is called on it.
+func NewProfileFromTemplate(name, template string) (*Profile, error) {
+	return Default().NewProfileFromTemplate(name, template)
+}
+func (p *Profile) NewProfileFromTemplate(name, template string) (*Profile, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	name = strings.ToLower(name)
+	if p.Exists(name) {
+		return nil, fmt.Errorf("%w: %q", ErrProfileExists, name)
+	}
+
+	t, err := p.LoadTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+
+	np := p.sibling(name)
+	for k, v := range map[string]string{
+		service:            t.Service,
+		OpsManagerURLField: t.OpsManagerURL,
+		output:             t.Output,
+		orgID:              t.OrgID,
+	} {
+		if v != "" {
+			np.Set(k, v)
+		}
+	}
+
+	return np, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_NewProfileFromTemplate(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  org_id = \"a\"\n")
+
+	template := &Template{
+		Service:       "ops-manager",
+		OpsManagerURL: "https://om.example.com/",
+		Output:        "json",
+	}
+	require.NoError(t, p.SaveTemplate("onprem", template))
+	require.ErrorIs(t, p.SaveTemplate("../onprem", template), ErrInvalidTemplateName)
+
+	names, err := p.Templates()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"onprem"}, names)
+
+	_, err = p.NewProfileFromTemplate("default", "onprem")
+	require.ErrorIs(t, err, ErrProfileExists)
+	_, err = p.NewProfileFromTemplate("dc1", "missing")
+	require.ErrorIs(t, err, ErrTemplateNotFound)
+
+	np, err := p.NewProfileFromTemplate("DC1", "onprem")
+	require.NoError(t, err)
+	assert.Equal(t, "dc1", np.Name())
+	assert.Equal(t, "ops-manager", np.Service())
+	assert.Equal(t, "https://om.example.com/", np.OpsManagerURL())
+	assert.Equal(t, "json", np.Output())
+	assert.Empty(t, np.OrgID())
+
+	require.NoError(t, np.Save())
+	b, err := afero.ReadFile(p.fs, p.Filename())
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "[dc1]")
+	assert.Contains(t, string(b), "[default]")
+
+	require.NoError(t, p.DeleteTemplate("onprem"))
+	require.ErrorIs(t, p.DeleteTemplate("onprem"), ErrTemplateNotFound)
+}
+
+func TestProfile_NewProfileFromTemplate_existsInReceiver(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	p := &Profile{name: DefaultProfile, configDir: "/other", fs: afero.NewMemMapFs()}
+	require.NoError(t, afero.WriteFile(p.fs, p.Filename(), []byte("[dc1]\n  org_id = \"a\"\n"), configPerm))
+	require.NoError(t, p.SaveTemplate("onprem", &Template{Output: "json"}))
+	p.SetLazyLoad(true)
+	require.NoError(t, p.load(false, AtlasCLIEnvPrefix))
+
+	_, err := p.NewProfileFromTemplate("dc1", "onprem")
+	require.ErrorIs(t, err, ErrProfileExists, "the profiles of the receiver's config file are checked")
+}
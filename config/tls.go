@@ -0,0 +1,151 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+var ErrClientCertificate = errors.New("can't load client certificate")
+
+// ClientCertFile get the configured PEM file with the client certificate for mutual TLS,
+// it can also hold the private key.
+func ClientCertFile() string { return Default().ClientCertFile() }
+func (p *Profile) ClientCertFile() string {
+	return p.GetString(clientCertFile)
+}
+
+// SetClientCertFile sets the PEM file with the client certificate.
+func SetClientCertFile(v string) { Default().SetClientCertFile(v) }
+func (p *Profile) SetClientCertFile(v string) {
+	p.Set(clientCertFile, v)
+}
+
+// ClientKeyFile get the configured PEM file with the private key of the client certificate,
+// empty when it's in the certificate file.
+func ClientKeyFile() string { return Default().ClientKeyFile() }
+func (p *Profile) ClientKeyFile() string {
+	return p.GetString(clientKeyFile)
+}
+
+// SetClientKeyFile sets the PEM file with the private key of the client certificate.
+func SetClientKeyFile(v string) { Default().SetClientKeyFile(v) }
+func (p *Profile) SetClientKeyFile(v string) {
+	p.Set(clientKeyFile, v)
+}
+
+// clientCertificate loads a client certificate when a server asks for one and keeps it
+// until the files change or the certificate expires, so renewed certificates are used without restarting.
+type clientCertificate struct {
+	fs       afero.Fs
+	certFile string
+	keyFile  string
+	now      func() time.Time
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func newClientCertificate(fs afero.Fs, certFile, keyFile string) *clientCertificate {
+	if keyFile == "" {
+		keyFile = certFile
+	}
+	return &clientCertificate{fs: fs, certFile: certFile, keyFile: keyFile, now: time.Now}
+}
+
+// get is a tls.Config GetClientCertificate callback.
+func (c *clientCertificate) get(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	modTime, err := c.lastModified()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrClientCertificate, err)
+	}
+	if c.cert != nil && modTime.Equal(c.modTime) && c.now().Before(c.cert.Leaf.NotAfter) {
+		return c.cert, nil
+	}
+
+	cert, err := c.load()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrClientCertificate, err)
+	}
+	c.cert, c.modTime = cert, modTime
+	return cert, nil
+}
+
+func (c *clientCertificate) lastModified() (time.Time, error) {
+	var last time.Time
+	for _, f := range []string{c.certFile, c.keyFile} {
+		info, err := c.fs.Stat(f)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+	return last, nil
+}
+
+func (c *clientCertificate) load() (*tls.Certificate, error) {
+	certPEM, err := afero.ReadFile(c.fs, c.certFile)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := afero.ReadFile(c.fs, c.keyFile)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return nil, err
+		}
+	}
+	if !c.now().Before(cert.Leaf.NotAfter) {
+		return nil, fmt.Errorf("%s expired on %s", c.certFile, cert.Leaf.NotAfter.Format(time.RFC3339))
+	}
+	return &cert, nil
+}
+
+// tlsConfig returns the TLS configuration of HttpClient, nil when the defaults are fine.
+func (p *Profile) tlsConfig() *tls.Config {
+	fips := p.FIPSMode()
+	certFile := p.ClientCertFile()
+	if certFile == "" && !fips {
+		return nil
+	}
+
+	c := &tls.Config{MinVersion: tls.VersionTLS12}
+	if certFile != "" {
+		c.GetClientCertificate = newClientCertificate(p.fs, certFile, p.ClientKeyFile()).get
+	}
+	if fips {
+		restrictToFIPS(c)
+	}
+	return c
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCertificate writes a self-signed client certificate and its key in PEM to the files.
+func writeTestCertificate(t *testing.T, fs afero.Fs, certFile, keyFile string, serial int64, notAfter time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "atlascli"},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, afero.WriteFile(fs, certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), configPerm))
+	require.NoError(t, afero.WriteFile(fs, keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), configPerm))
+	modTime := time.Unix(serial, 0)
+	require.NoError(t, fs.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, fs.Chtimes(keyFile, modTime, modTime))
+}
+
+func TestClientCertificate_Reload(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	now := time.Now()
+	writeTestCertificate(t, fs, "/cert.pem", "/key.pem", 1, now.Add(time.Hour))
+
+	c := newClientCertificate(fs, "/cert.pem", "/key.pem")
+	cert, err := c.get(nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, cert.Leaf.SerialNumber.Int64())
+
+	cached, err := c.get(nil)
+	require.NoError(t, err)
+	require.Same(t, cert, cached)
+
+	writeTestCertificate(t, fs, "/cert.pem", "/key.pem", 2, now.Add(time.Hour))
+	cert, err = c.get(nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, cert.Leaf.SerialNumber.Int64(), "changed files are reloaded")
+
+	c.now = func() time.Time { return now.Add(2 * time.Hour) }
+	_, err = c.get(nil)
+	require.ErrorIs(t, err, ErrClientCertificate, "expired certificates are reloaded, and still expired")
+
+	_, err = newClientCertificate(fs, "/missing.pem", "").get(nil)
+	require.ErrorIs(t, err, ErrClientCertificate)
+}
+
+func TestProfile_HttpClientCertificate(t *testing.T) {
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Len(t, r.TLS.PeerCertificates, 1)
+		require.Equal(t, "atlascli", r.TLS.PeerCertificates[0].Subject.CommonName)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	s.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	s.StartTLS()
+	defer s.Close()
+
+	p := newTestProfile(t, "")
+	writeTestCertificate(t, p.fs, "/atlascli/client.pem", "/atlascli/client.key", 1, time.Now().Add(time.Hour))
+	p.SetClientCertFile("/atlascli/client.pem")
+	p.SetClientKeyFile("/atlascli/client.key")
+
+	transport := p.baseTransport()
+	require.NotNil(t, transport.TLSClientConfig)
+	transport.TLSClientConfig.RootCAs = x509.NewCertPool()
+	transport.TLSClientConfig.RootCAs.AddCert(s.Certificate())
+
+	resp, err := (&http.Client{Transport: transport}).Get(s.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
@@ -0,0 +1,194 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultHTTPTimeout           = 0 // DefaultHTTPTimeout no limit, downloads can take long
+	DefaultDialTimeout           = 30 * time.Second
+	DefaultResponseHeaderTimeout = 2 * time.Minute
+	DefaultMaxIdleConnsPerHost   = http.DefaultMaxIdleConnsPerHost
+	DefaultMaxConnsPerHost       = 0 // DefaultMaxConnsPerHost no limit
+	DefaultIdleConnTimeout       = 90 * time.Second
+	DefaultKeepAlive             = 30 * time.Second
+)
+
+// HTTPTimeout get the configured limit for a whole request, including reading the response body.
+// Zero means no limit.
+func HTTPTimeout() time.Duration { return Default().HTTPTimeout() }
+func (p *Profile) HTTPTimeout() time.Duration {
+	return p.GetDurationWithDefault(httpTimeout, DefaultHTTPTimeout)
+}
+
+// SetHTTPTimeout sets the limit for a whole request.
+func SetHTTPTimeout(v time.Duration) { Default().SetHTTPTimeout(v) }
+func (p *Profile) SetHTTPTimeout(v time.Duration) {
+	p.Set(httpTimeout, v.String())
+}
+
+// DialTimeout get the configured limit to establish a connection.
+func DialTimeout() time.Duration { return Default().DialTimeout() }
+func (p *Profile) DialTimeout() time.Duration {
+	return p.GetDurationWithDefault(dialTimeout, DefaultDialTimeout)
+}
+
+// SetDialTimeout sets the limit to establish a connection.
+func SetDialTimeout(v time.Duration) { Default().SetDialTimeout(v) }
+func (p *Profile) SetDialTimeout(v time.Duration) {
+	p.Set(dialTimeout, v.String())
+}
+
+// ResponseHeaderTimeout get the configured limit to wait for the response headers once the request is sent.
+func ResponseHeaderTimeout() time.Duration { return Default().ResponseHeaderTimeout() }
+func (p *Profile) ResponseHeaderTimeout() time.Duration {
+	return p.GetDurationWithDefault(responseHeaderTimeout, DefaultResponseHeaderTimeout)
+}
+
+// SetResponseHeaderTimeout sets the limit to wait for the response headers.
+func SetResponseHeaderTimeout(v time.Duration) { Default().SetResponseHeaderTimeout(v) }
+func (p *Profile) SetResponseHeaderTimeout(v time.Duration) {
+	p.Set(responseHeaderTimeout, v.String())
+}
+
+// MaxIdleConnsPerHost get the configured number of idle connections kept open per host.
+func MaxIdleConnsPerHost() int { return Default().MaxIdleConnsPerHost() }
+func (p *Profile) MaxIdleConnsPerHost() int {
+	return p.GetIntWithDefault(maxIdleConnsPerHost, DefaultMaxIdleConnsPerHost)
+}
+
+// SetMaxIdleConnsPerHost sets the number of idle connections kept open per host.
+func SetMaxIdleConnsPerHost(v int) { Default().SetMaxIdleConnsPerHost(v) }
+func (p *Profile) SetMaxIdleConnsPerHost(v int) {
+	p.Set(maxIdleConnsPerHost, v)
+}
+
+// MaxConnsPerHost get the configured limit of connections per host, zero means no limit.
+func MaxConnsPerHost() int { return Default().MaxConnsPerHost() }
+func (p *Profile) MaxConnsPerHost() int {
+	return p.GetIntWithDefault(maxConnsPerHost, DefaultMaxConnsPerHost)
+}
+
+// SetMaxConnsPerHost sets the limit of connections per host.
+func SetMaxConnsPerHost(v int) { Default().SetMaxConnsPerHost(v) }
+func (p *Profile) SetMaxConnsPerHost(v int) {
+	p.Set(maxConnsPerHost, v)
+}
+
+// IdleConnTimeout get the configured time an idle connection is kept open.
+func IdleConnTimeout() time.Duration { return Default().IdleConnTimeout() }
+func (p *Profile) IdleConnTimeout() time.Duration {
+	return p.GetDurationWithDefault(idleConnTimeout, DefaultIdleConnTimeout)
+}
+
+// SetIdleConnTimeout sets the time an idle connection is kept open.
+func SetIdleConnTimeout(v time.Duration) { Default().SetIdleConnTimeout(v) }
+func (p *Profile) SetIdleConnTimeout(v time.Duration) {
+	p.Set(idleConnTimeout, v.String())
+}
+
+// KeepAlive get the configured interval of the TCP keep-alive probes of connections, a negative value disables them.
+func KeepAlive() time.Duration { return Default().KeepAlive() }
+func (p *Profile) KeepAlive() time.Duration {
+	return p.GetDurationWithDefault(tcpKeepAlive, DefaultKeepAlive)
+}
+
+// SetKeepAlive sets the interval of the TCP keep-alive probes of connections.
+func SetKeepAlive(v time.Duration) { Default().SetKeepAlive(v) }
+func (p *Profile) SetKeepAlive(v time.Duration) {
+	p.Set(tcpKeepAlive, v.String())
+}
+
+// DisableKeepAlives get whether connections are closed after each request instead of being reused,
+// for networks where NATs or firewalls silently drop idle connections.
+func DisableKeepAlives() bool { return Default().DisableKeepAlives() }
+func (p *Profile) DisableKeepAlives() bool {
+	return p.GetBool(disableKeepAlives)
+}
+
+// SetDisableKeepAlives sets whether connections are closed after each request.
+func SetDisableKeepAlives(v bool) { Default().SetDisableKeepAlives(v) }
+func (p *Profile) SetDisableKeepAlives(v bool) {
+	p.Set(disableKeepAlives, v)
+}
+
+// closeIdleConnections closes the idle connections of a transport if it keeps any,
+// the transports of HttpClient forward it so that http.Client.CloseIdleConnections reaches the network transport.
+func closeIdleConnections(t http.RoundTripper) {
+	if c, ok := t.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
+
+// unauthenticatedClient returns a client like HttpClient without the authentication transports,
+// for requests that must not send the profile's credentials.
+func (p *Profile) unauthenticatedClient() *http.Client {
+	if p.Offline() {
+		return &http.Client{Transport: offlineTransport{}}
+	}
+	return &http.Client{Transport: p.metricsTransport(p.cloudGovTransport(p.networkTransport())), Timeout: p.HTTPTimeout()}
+}
+
+// TransportWrapper wraps the transport sending requests over the network, see SetTransportWrapper.
+type TransportWrapper func(base http.RoundTripper) http.RoundTripper
+
+// SetTransportWrapper sets a function wrapping the transport HttpClient sends requests over the network with,
+// below authentication, failover and the other transports of the client, like to record and replay requests in tests.
+// nil removes it.
+func SetTransportWrapper(w TransportWrapper) { Default().SetTransportWrapper(w) }
+func (p *Profile) SetTransportWrapper(w TransportWrapper) {
+	p.transportWrapper = w
+}
+
+// networkTransport returns the transport sending requests over the network, wrapped by SetTransportWrapper.
+func (p *Profile) networkTransport() http.RoundTripper {
+	if p.transportWrapper == nil {
+		return p.baseTransport()
+	}
+	return p.transportWrapper(p.baseTransport())
+}
+
+// baseTransport returns the transport used by HttpClient before adding authentication.
+func (p *Profile) baseTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{
+		Timeout:   p.DialTimeout(),
+		KeepAlive: p.KeepAlive(),
+	}
+	t.DialContext = resolveDialer(p.ResolveOverrides(), ipFamilyDialer(p.IPFamily(), dialer.DialContext))
+	t.ResponseHeaderTimeout = p.ResponseHeaderTimeout()
+	t.MaxIdleConnsPerHost = p.MaxIdleConnsPerHost()
+	t.MaxConnsPerHost = p.MaxConnsPerHost()
+	t.IdleConnTimeout = p.IdleConnTimeout()
+	t.DisableKeepAlives = p.DisableKeepAlives()
+	if c := p.tlsConfig(); c != nil {
+		t.TLSClientConfig = c
+	}
+	if proxy := p.proxyFunc(); proxy != nil {
+		t.Proxy = proxy
+	}
+	if d := p.sshJumpDialer(); d != nil {
+		t.Proxy = nil
+		t.DialContext = d.DialContext
+	} else if d := p.proxyDialer(t); d != nil {
+		t.Proxy = nil
+		t.DialContext = d.DialContext
+	}
+	return t
+}
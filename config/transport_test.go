@@ -0,0 +1,124 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestTransport_doesNotModifyRequest(t *testing.T) {
+	var sent *http.Request
+	tr := &Transport{
+		token: "token",
+		base: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			sent = req
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}),
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "https://cloud.mongodb.com/api/atlas/v2", nil)
+	require.NoError(t, err)
+	resp, err := tr.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "Bearer token", sent.Header.Get("Authorization"))
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
+
+func TestProfile_Timeouts(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  http_timeout = \"1m\"\n  dial_timeout = 5\n  response_header_timeout = \"invalid\"\n")
+
+	assert.Equal(t, time.Minute, p.HTTPTimeout())
+	assert.Equal(t, 5*time.Second, p.DialTimeout())
+	assert.Equal(t, DefaultResponseHeaderTimeout, p.ResponseHeaderTimeout())
+
+	p.SetResponseHeaderTimeout(10 * time.Second)
+	client := p.HttpClient()
+	assert.Equal(t, time.Minute, client.Timeout)
+	preview, ok := client.Transport.(previewTransport)
+	require.True(t, ok)
+	transport, ok := preview.base.(*http.Transport)
+	if assert.True(t, ok) {
+		assert.Equal(t, 10*time.Second, transport.ResponseHeaderTimeout)
+	}
+}
+
+func TestProfile_ConnectionPool(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  max_idle_conns_per_host = 16\n  max_conns_per_host = \"32\"\n")
+	p.SetIdleConnTimeout(time.Minute)
+
+	transport := p.baseTransport()
+	assert.Equal(t, 16, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 32, transport.MaxConnsPerHost)
+	assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+
+	p = newTestProfile(t, "")
+	transport = p.baseTransport()
+	assert.Equal(t, http.DefaultMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 0, transport.MaxConnsPerHost)
+}
+
+func TestProfile_KeepAlives(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  keep_alive = \"-1s\"\n  disable_keep_alives = true\n")
+	assert.Equal(t, -time.Second, p.KeepAlive())
+	assert.True(t, p.DisableKeepAlives())
+	assert.True(t, p.baseTransport().DisableKeepAlives)
+
+	p = newTestProfile(t, "")
+	assert.Equal(t, DefaultKeepAlive, p.KeepAlive())
+	assert.False(t, p.baseTransport().DisableKeepAlives)
+}
+
+func TestProfile_CloseIdleConnections(t *testing.T) {
+	closed := make(chan struct{}, 1)
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	s.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closed <- struct{}{}
+		}
+	}
+	s.Start()
+	defer s.Close()
+
+	p := newTestProfile(t, "")
+	p.SetAccessToken("token")
+	client := p.HttpClient()
+	resp, err := client.Get(s.URL)
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	client.CloseIdleConnections()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the idle connection wasn't closed")
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidProjectID = errors.New("invalid project ID, expected a 24 character hexadecimal ID")
+	ErrInvalidOrgID     = errors.New("invalid organization ID, expected a 24 character hexadecimal ID")
+	ErrInvalidBaseURL   = errors.New("invalid base URL")
+)
+
+// apiPathPrefix is the start of the path of API endpoints, users often paste it as part of the base URL.
+const apiPathPrefix = "/api/"
+
+var objectIDRegex = regexp.MustCompile(`^[0-9a-fA-F]{24}$`)
+
+// IsValidProjectID returns true if the ID has the format of an Atlas project ID.
+func IsValidProjectID(id string) bool {
+	return objectIDRegex.MatchString(id)
+}
+
+// IsValidOrgID returns true if the ID has the format of an Atlas organization ID.
+func IsValidOrgID(id string) bool {
+	return objectIDRegex.MatchString(id)
+}
+
+// validateID checks the format of an ID, Ops Manager deployments can use local IDs
+// so only empty values or values with spaces are rejected for them.
+func (p *Profile) validateID(id string, isValid func(string) bool, errInvalid error) error {
+	if p.IsCloud() && !isValid(id) {
+		return fmt.Errorf("%w: %q", errInvalid, id)
+	}
+	if id == "" || strings.ContainsAny(id, " \t\n") {
+		return fmt.Errorf("%w: %q", errInvalid, id)
+	}
+	return nil
+}
+
+// SetValidProjectID sets the project ID after checking its format.
+func SetValidProjectID(v string) error { return Default().SetValidProjectID(v) }
+func (p *Profile) SetValidProjectID(v string) error {
+	if err := p.validateID(v, IsValidProjectID, ErrInvalidProjectID); err != nil {
+		return err
+	}
+	if p.CurrentService() == CloudGovService {
+		if err := p.checkCloudGovID(projectID, v); err != nil {
+			return err
+		}
+	}
+	p.SetProjectID(v)
+	return nil
+}
+
+// SetValidOrgID sets the organization ID after checking its format.
+func SetValidOrgID(v string) error { return Default().SetValidOrgID(v) }
+func (p *Profile) SetValidOrgID(v string) error {
+	if err := p.validateID(v, IsValidOrgID, ErrInvalidOrgID); err != nil {
+		return err
+	}
+	if p.CurrentService() == CloudGovService {
+		if err := p.checkCloudGovID(orgID, v); err != nil {
+			return err
+		}
+	}
+	p.SetOrgID(v)
+	return nil
+}
+
+// NormalizeBaseURL validates a base URL and returns it in the form expected by the API clients,
+// with a trailing slash and without any API path, for example https://om.example.com:8080/.
+// URLs without an http or https scheme, without a host, with credentials, queries or fragments are rejected.
+func NormalizeBaseURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidBaseURL, err)
+	}
+	switch {
+	case u.Scheme != "http" && u.Scheme != "https":
+		return "", fmt.Errorf("%w: %q, the scheme must be http or https", ErrInvalidBaseURL, raw)
+	case u.Host == "":
+		return "", fmt.Errorf("%w: %q, missing host", ErrInvalidBaseURL, raw)
+	case u.User != nil:
+		return "", fmt.Errorf("%w: %q, credentials are not allowed in the URL", ErrInvalidBaseURL, raw)
+	case u.RawQuery != "" || u.Fragment != "":
+		return "", fmt.Errorf("%w: %q, queries and fragments are not allowed", ErrInvalidBaseURL, raw)
+	}
+
+	if i := strings.Index(u.Path, apiPathPrefix); i >= 0 {
+		u.Path = u.Path[:i]
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+// SetValidOpsManagerURL normalizes and sets the ops manager base url.
+func SetValidOpsManagerURL(v string) error { return Default().SetValidOpsManagerURL(v) }
+func (p *Profile) SetValidOpsManagerURL(v string) error {
+	normalized, err := NormalizeBaseURL(v)
+	if err != nil {
+		return err
+	}
+	if p.CurrentService() == CloudGovService {
+		if err := checkCloudGovURL(OpsManagerURLField, normalized); err != nil {
+			return err
+		}
+	}
+	p.SetOpsManagerURL(normalized)
+	return nil
+}
+
+// ValidateOpsManagerURL returns an error if the configured ops manager base url is set but not valid.
+// Loading the config also checks it and reports a WarningSettings warning, see Warnings.
+func ValidateOpsManagerURL() error { return Default().ValidateOpsManagerURL() }
+func (p *Profile) ValidateOpsManagerURL() error {
+	v := p.GetString(OpsManagerURLField)
+	if v == "" {
+		return nil
+	}
+	_, err := NormalizeBaseURL(v)
+	return err
+}
+
+// checkOpsManagerURL warns when the ops manager base url of the config file or the environment isn't valid.
+func (p *Profile) checkOpsManagerURL() {
+	if err := p.ValidateOpsManagerURL(); err != nil {
+		p.warn(Warning{Kind: WarningSettings, Key: OpsManagerURLField, Message: err.Error()})
+	}
+}
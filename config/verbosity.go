@@ -0,0 +1,142 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	quietFlag   = "quiet"
+	verboseFlag = "verbose"
+)
+
+var ErrInvalidVerbosity = errors.New("invalid verbosity, expected one of quiet, normal, verbose or debug")
+
+// VerbosityLevel is how much CLIs print besides the command results, levels compare so VerbosityVerbose > VerbosityNormal.
+type VerbosityLevel int
+
+const (
+	VerbosityQuiet   VerbosityLevel = iota - 1 // VerbosityQuiet only results and errors are printed
+	VerbosityNormal                            // VerbosityNormal results, messages and warnings are printed, the default
+	VerbosityVerbose                           // VerbosityVerbose details about the progress of commands are printed too
+	VerbosityDebug                             // VerbosityDebug everything is printed, like debug logs
+)
+
+func (v VerbosityLevel) String() string {
+	switch {
+	case v <= VerbosityQuiet:
+		return "quiet"
+	case v == VerbosityNormal:
+		return "normal"
+	case v == VerbosityVerbose:
+		return "verbose"
+	default:
+		return "debug"
+	}
+}
+
+// LogLevel returns the minimum level of the records logged at this verbosity.
+func (v VerbosityLevel) LogLevel() slog.Level {
+	switch {
+	case v <= VerbosityQuiet:
+		return slog.LevelError
+	case v == VerbosityNormal:
+		return slog.LevelInfo
+	case v == VerbosityVerbose:
+		return slog.LevelDebug
+	default:
+		return slog.LevelDebug - 4
+	}
+}
+
+// ParseVerbosity returns the verbosity named s, VerbosityNormal when empty.
+func ParseVerbosity(s string) (VerbosityLevel, error) {
+	for v := VerbosityQuiet; v <= VerbosityDebug; v++ {
+		if strings.EqualFold(s, v.String()) {
+			return v, nil
+		}
+	}
+	if s == "" {
+		return VerbosityNormal, nil
+	}
+	return VerbosityNormal, fmt.Errorf("%w: %q", ErrInvalidVerbosity, s)
+}
+
+// Verbosity get how much CLIs should print, from the -q and -v flags added by AddVerbosityFlags,
+// then the verbosity setting, where a value set in the profile takes precedence over the global one.
+// Invalid values are VerbosityNormal.
+func Verbosity() VerbosityLevel { return Default().Verbosity() }
+func (p *Profile) Verbosity() VerbosityLevel {
+	if v, ok := p.flagValue(verboseFlag); ok {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return min(VerbosityNormal+VerbosityLevel(n), VerbosityDebug)
+		}
+	}
+	if v, ok := p.flagValue(quietFlag); ok && IsTrue(v) {
+		return VerbosityQuiet
+	}
+	v, _ := ParseVerbosity(p.GetString(verbosity))
+	return v
+}
+
+// SetVerbosity sets the global verbosity.
+func SetVerbosity(v VerbosityLevel) { Default().SetVerbosity(v) }
+func (p *Profile) SetVerbosity(v VerbosityLevel) {
+	p.SetGlobal(verbosity, v.String())
+}
+
+// SetProfileVerbosity sets the verbosity for this profile only.
+func SetProfileVerbosity(v VerbosityLevel) { Default().SetProfileVerbosity(v) }
+func (p *Profile) SetProfileVerbosity(v VerbosityLevel) {
+	p.Set(verbosity, v.String())
+}
+
+// AddVerbosityFlags adds the persistent -q/--quiet and -v/--verbose flags to a command and binds them,
+// so every CLI handles them the same way: -q is VerbosityQuiet, -v is VerbosityVerbose and -vv is VerbosityDebug.
+func AddVerbosityFlags(cmd *cobra.Command) { Default().AddVerbosityFlags(cmd) }
+func (p *Profile) AddVerbosityFlags(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+	flags.BoolP(quietFlag, "q", false, "Print only results and errors.")
+	flags.CountP(verboseFlag, "v", "Print more details, repeat for debug output like -vv.")
+	p.BindFlag(quietFlag, flags.Lookup(quietFlag))
+	p.BindFlag(verboseFlag, flags.Lookup(verboseFlag))
+}
+
+// verbosityHandler drops the records below the log level of the profile's verbosity when the logger was created,
+// so records logged from the goroutines of HttpClient requests don't read the profile.
+type verbosityHandler struct {
+	slog.Handler
+	level slog.Level
+}
+
+func (h verbosityHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level && h.Handler.Enabled(ctx, level)
+}
+
+func (h verbosityHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return verbosityHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level}
+}
+
+func (h verbosityHandler) WithGroup(name string) slog.Handler {
+	return verbosityHandler{Handler: h.Handler.WithGroup(name), level: h.level}
+}
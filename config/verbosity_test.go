@@ -0,0 +1,103 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVerbosity(t *testing.T) {
+	for _, v := range []VerbosityLevel{VerbosityQuiet, VerbosityNormal, VerbosityVerbose, VerbosityDebug} {
+		got, err := ParseVerbosity(v.String())
+		require.NoError(t, err)
+		assert.Equal(t, v, got)
+	}
+	v, err := ParseVerbosity("")
+	require.NoError(t, err)
+	assert.Equal(t, VerbosityNormal, v)
+	_, err = ParseVerbosity("loud")
+	require.ErrorIs(t, err, ErrInvalidVerbosity)
+	assert.Equal(t, slog.LevelError, VerbosityQuiet.LogLevel())
+	assert.Less(t, VerbosityDebug.LogLevel(), slog.LevelDebug)
+}
+
+func TestProfile_Verbosity(t *testing.T) {
+	p := newTestProfile(t, "verbosity = \"quiet\"\n\n[default]\n  org_id = \""+testOrgA+"\"\n")
+	assert.Equal(t, VerbosityQuiet, p.Verbosity(), "global setting")
+
+	p.SetProfileVerbosity(VerbosityVerbose)
+	assert.Equal(t, VerbosityVerbose, p.Verbosity(), "the profile takes precedence")
+
+	p.Set(verbosity, "loud")
+	assert.Equal(t, VerbosityNormal, p.Verbosity(), "invalid values are normal")
+
+	t.Setenv("MONGODB_ATLAS_VERBOSITY", "debug")
+	viper.Reset()
+	p = &Profile{name: DefaultProfile, configDir: p.configDir, fs: p.fs}
+	require.NoError(t, p.load(true, AtlasCLIEnvPrefix))
+	assert.Equal(t, VerbosityDebug, p.Verbosity(), "the environment takes precedence")
+}
+
+func TestProfile_AddVerbosityFlags(t *testing.T) {
+	p := newTestProfile(t, "[default]\n  verbosity = \"quiet\"\n")
+	cmd := &cobra.Command{Use: "test", Run: func(*cobra.Command, []string) {}}
+	p.AddVerbosityFlags(cmd)
+
+	assert.Equal(t, VerbosityQuiet, p.Verbosity(), "flags that aren't set are ignored")
+
+	cmd.SetArgs([]string{"-vv"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, VerbosityDebug, p.Verbosity())
+
+	cmd = &cobra.Command{Use: "test", Run: func(*cobra.Command, []string) {}}
+	p.AddVerbosityFlags(cmd)
+	p.SetProfileVerbosity(VerbosityDebug)
+	cmd.SetArgs([]string{"--quiet"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, VerbosityQuiet, p.Verbosity(), "flags take precedence")
+}
+
+func TestProfile_LoggerVerbosity(t *testing.T) {
+	p := newTestProfile(t, "")
+	l, buf := testLogger()
+	p.SetLogger(l)
+
+	p.Logger().Debug("debug record")
+	p.Logger().Info("info record")
+	assert.NotContains(t, buf.String(), "debug record")
+	assert.Contains(t, buf.String(), "info record")
+
+	p.SetVerbosity(VerbosityQuiet)
+	buf.Reset()
+	p.Logger().With("key", "value").Warn("warning record")
+	p.Logger().Error("error record")
+	assert.NotContains(t, buf.String(), "warning record")
+	assert.Contains(t, buf.String(), "error record")
+
+	quiet := p.Logger()
+	p.SetVerbosity(VerbosityVerbose)
+	quiet.Warn("quiet record")
+	assert.NotContains(t, buf.String(), "quiet record", "the verbosity is read when the logger is created")
+	p.Logger().WithGroup("group").Debug("debug record")
+	assert.Contains(t, buf.String(), "debug record")
+}
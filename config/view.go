@@ -0,0 +1,189 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"net/url"
+	"time"
+)
+
+// redacted replaces the value of secrets in Map and View.
+const redacted = "redacted"
+
+// ProfileView is a typed snapshot of the settings of a profile, with the values the accessors return,
+// defaults and inherited settings included. See View.
+type ProfileView struct {
+	Name           string
+	Service        ServiceType
+	OrgID          string
+	ProjectID      string
+	Output         string
+	OpsManagerURL  string
+	BaseURL        string // BaseURL is the base URL requests are sent to, see HttpBaseURL
+	MongoShellPath string
+	Inherits       string
+	ReadOnly       bool
+	Environment    string
+
+	PublicAPIKey  string
+	PrivateAPIKey string // PrivateAPIKey is redacted unless secrets are included, like the tokens
+	AccessToken   string
+	RefreshToken  string
+	ClientID      string
+	AuthURL       string
+	TokenURL      string
+	JWKSURL       string
+
+	SkipUpdateCheck  bool
+	TelemetryEnabled bool
+	EncryptSecrets   bool
+	Offline          bool
+	FIPSMode         bool
+	PreviewFeatures  bool
+	RecordActivity   bool
+	DryRun           bool
+
+	TelemetrySampleRate    float64
+	TelemetryBatchSize     int
+	TelemetryFlushInterval time.Duration
+	CrashReports           bool
+	TelemetryConsent       ConsentState
+	TelemetrySpoolMaxSize  int64
+	TelemetrySpoolMaxAge   time.Duration
+
+	Color      ColorMode
+	Pager      string
+	Locale     string
+	TimeFormat TimeFormat
+	Verbosity  VerbosityLevel
+
+	HTTPTimeout             time.Duration
+	DialTimeout             time.Duration
+	ResponseHeaderTimeout   time.Duration
+	IdleConnTimeout         time.Duration
+	KeepAlive               time.Duration
+	DisableKeepAlives       bool
+	MaxIdleConnsPerHost     int
+	MaxConnsPerHost         int
+	MaxRequestsPerHost      int
+	MaxResponseSize         int64
+	CircuitBreakerThreshold int
+	CircuitBreakerCooldown  time.Duration
+	FallbackBaseURLs        []string
+
+	ClientCertFile   string
+	ClientKeyFile    string
+	Proxy            string // Proxy has its password redacted unless secrets are included
+	ProxyAuth        string
+	SSHJumpHost      string
+	DemoFixtures     string
+	APIVersion       string
+	APIRegion        string
+	EndpointType     string
+	IPFamily         string
+	ResolveOverrides []ResolveOverride
+}
+
+// View returns the settings of the profile as a ProfileView. The private API key, the tokens and the proxy password
+// are redacted unless includeSecrets is true, only pass true to export the credentials.
+func View(includeSecrets bool) ProfileView { return Default().View(includeSecrets) }
+func (p *Profile) View(includeSecrets bool) ProfileView {
+	v := ProfileView{
+		Name:           p.Name(),
+		Service:        p.CurrentService(),
+		OrgID:          p.OrgID(),
+		ProjectID:      p.ProjectID(),
+		Output:         p.Output(),
+		OpsManagerURL:  p.OpsManagerURL(),
+		BaseURL:        p.HttpBaseURL(),
+		MongoShellPath: p.GetString(mongoShellPath),
+		Inherits:       p.Inherits(),
+		ReadOnly:       p.IsReadOnly(),
+		Environment:    p.Environment(),
+
+		PublicAPIKey:  p.PublicAPIKey(),
+		PrivateAPIKey: p.PrivateAPIKey(),
+		AccessToken:   p.AccessToken(),
+		RefreshToken:  p.RefreshToken(),
+		ClientID:      p.ClientID(),
+		AuthURL:       p.AuthURL(),
+		TokenURL:      p.TokenURL(),
+		JWKSURL:       p.JWKSURL(),
+
+		SkipUpdateCheck:  p.SkipUpdateCheck(),
+		TelemetryEnabled: p.TelemetryEnabled(),
+		EncryptSecrets:   p.EncryptSecrets(),
+		Offline:          p.Offline(),
+		FIPSMode:         p.FIPSMode(),
+		PreviewFeatures:  p.PreviewFeatures(),
+		RecordActivity:   p.RecordActivity(),
+		DryRun:           p.DryRun(),
+
+		TelemetrySampleRate:    p.TelemetrySampleRate(),
+		TelemetryBatchSize:     p.TelemetryBatchSize(),
+		TelemetryFlushInterval: p.TelemetryFlushInterval(),
+		CrashReports:           p.CrashReports(),
+		TelemetryConsent:       p.TelemetryConsent(),
+		TelemetrySpoolMaxSize:  p.TelemetrySpoolMaxSize(),
+		TelemetrySpoolMaxAge:   p.TelemetrySpoolMaxAge(),
+
+		Color:      p.Color(),
+		Pager:      p.Pager(),
+		Locale:     p.Locale(),
+		TimeFormat: p.TimeFormat(),
+		Verbosity:  p.Verbosity(),
+
+		HTTPTimeout:             p.HTTPTimeout(),
+		DialTimeout:             p.DialTimeout(),
+		ResponseHeaderTimeout:   p.ResponseHeaderTimeout(),
+		IdleConnTimeout:         p.IdleConnTimeout(),
+		KeepAlive:               p.KeepAlive(),
+		DisableKeepAlives:       p.DisableKeepAlives(),
+		MaxIdleConnsPerHost:     p.MaxIdleConnsPerHost(),
+		MaxConnsPerHost:         p.MaxConnsPerHost(),
+		MaxRequestsPerHost:      p.MaxRequestsPerHost(),
+		MaxResponseSize:         p.MaxResponseSize(),
+		CircuitBreakerThreshold: p.CircuitBreakerThreshold(),
+		CircuitBreakerCooldown:  p.CircuitBreakerCooldown(),
+		FallbackBaseURLs:        p.FallbackBaseURLs(),
+
+		ClientCertFile:   p.ClientCertFile(),
+		ClientKeyFile:    p.ClientKeyFile(),
+		Proxy:            p.Proxy(),
+		ProxyAuth:        p.ProxyAuth(),
+		SSHJumpHost:      p.SSHJumpHost(),
+		DemoFixtures:     p.DemoFixtures(),
+		APIVersion:       p.APIVersion(),
+		APIRegion:        p.APIRegion(),
+		EndpointType:     p.EndpointType(),
+		IPFamily:         p.IPFamily(),
+		ResolveOverrides: p.ResolveOverrides(),
+	}
+	if !includeSecrets {
+		v.redact()
+	}
+	return v
+}
+
+func (v *ProfileView) redact() {
+	for _, s := range []*string{&v.PrivateAPIKey, &v.AccessToken, &v.RefreshToken} {
+		if *s != "" {
+			*s = redacted
+		}
+	}
+	if u, err := url.Parse(v.Proxy); err == nil && u.User != nil {
+		v.Proxy = u.Redacted()
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"slices"
+	"sync"
+)
+
+// WarningKind is the category of a Warning.
+type WarningKind string
+
+const (
+	WarningDeprecation WarningKind = "deprecation" // WarningDeprecation something the CLI still supports but will remove, like MCLI variables
+	WarningPermissions WarningKind = "permissions" // WarningPermissions the config is accessible by other users, see PermissionWarning
+	WarningPolicy      WarningKind = "policy"      // WarningPolicy a setting is overridden, like telemetry_enabled by DO_NOT_TRACK
+	WarningCredentials WarningKind = "credentials" // WarningCredentials a credential can't be decrypted or resolved
+	WarningSettings    WarningKind = "settings"    // WarningSettings a setting has an invalid value, like a malformed ops_manager_url
+)
+
+// Warning is a problem found by the profile that doesn't stop the command, see Warnings.
+type Warning struct {
+	Kind    WarningKind `json:"kind"`
+	Key     string      `json:"key,omitempty"` // Key is the setting the warning is about, if any
+	Message string      `json:"message"`
+}
+
+func (w Warning) String() string {
+	return w.Message
+}
+
+// warningList collects the warnings of a profile, the same warning is only kept once.
+type warningList struct {
+	mu   sync.Mutex
+	list []Warning
+}
+
+func (l *warningList) add(w Warning) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if slices.Contains(l.list, w) {
+		return false
+	}
+	l.list = append(l.list, w)
+	return true
+}
+
+func (l *warningList) get(drain bool) []Warning {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	list := slices.Clone(l.list)
+	if drain {
+		l.list = nil
+	}
+	return list
+}
+
+// Warnings returns the warnings found so far, like permissions that are too open, deprecated environment variables
+// or settings overridden by a policy, so CLIs can print them at the end of a command or add them to JSON output.
+// They are also logged, see SetLogger.
+func Warnings() []Warning { return Default().Warnings() }
+func (p *Profile) Warnings() []Warning {
+	return p.warnings.get(false)
+}
+
+// DrainWarnings returns the warnings found so far and forgets them, so they are reported once.
+func DrainWarnings() []Warning { return Default().DrainWarnings() }
+func (p *Profile) DrainWarnings() []Warning {
+	return p.warnings.get(true)
+}
+
+// AddWarning adds a warning of the CLI, like a deprecated flag, to be reported with the warnings of the profile.
+func AddWarning(kind WarningKind, key, message string) { Default().AddWarning(kind, key, message) }
+func (p *Profile) AddWarning(kind WarningKind, key, message string) {
+	p.warn(Warning{Kind: kind, Key: key, Message: message})
+}
+
+// warn collects a warning and logs it, unless the same warning was already collected.
+func (p *Profile) warn(w Warning) {
+	if !p.warnings.add(w) {
+		return
+	}
+	if w.Key == "" {
+		p.Logger().Warn(w.Message, "kind", w.Kind)
+		return
+	}
+	p.Logger().Warn(w.Message, "kind", w.Kind, "key", w.Key)
+}
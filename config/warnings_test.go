@@ -0,0 +1,75 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_Warnings(t *testing.T) {
+	fakeCommand(t, func(string, ...string) ([]byte, error) {
+		return nil, errors.New("item not found")
+	})
+	p := newTestProfile(t, "[default]\n  private_api_key = \"op://atlas/prod/missing\"\n")
+	l, buf := testLogger()
+	p.SetLogger(l)
+	assert.Empty(t, p.Warnings())
+
+	require.Empty(t, p.PrivateAPIKey())
+	require.Empty(t, p.PrivateAPIKey())
+	p.AddWarning(WarningDeprecation, "", "--projectId is deprecated, use --project-id")
+
+	want := []Warning{
+		{Kind: WarningCredentials, Key: privateAPIKey, Message: `can't resolve secret reference "op://atlas/prod/missing": item not found`},
+		{Kind: WarningDeprecation, Message: "--projectId is deprecated, use --project-id"},
+	}
+	require.Equal(t, want, p.Warnings(), "the same warning is kept once")
+	assert.Contains(t, buf.String(), "kind=credentials")
+	assert.Contains(t, buf.String(), "kind=deprecation")
+
+	assert.Equal(t, want, p.DrainWarnings())
+	assert.Empty(t, p.Warnings())
+}
+
+func TestProfile_WarningsOnLoad(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Cleanup(ResetEnvCache)
+	t.Setenv("MCLI_ORG_ID", testOrgA)
+	t.Setenv(DoNotTrackEnv, "1")
+	ResetEnvCache()
+
+	p := &Profile{name: DefaultProfile, configDir: "/atlascli", fs: afero.NewMemMapFs()}
+	require.NoError(t, p.fs.MkdirAll(p.configDir, defaultPermissions))
+	require.NoError(t, afero.WriteFile(p.fs, p.Filename(), []byte("telemetry_enabled = true\n"), 0644))
+	require.NoError(t, p.LoadAtlasCLIConfig(true))
+
+	kinds := map[WarningKind]Warning{}
+	for _, w := range p.Warnings() {
+		kinds[w.Kind] = w
+	}
+	assert.Len(t, kinds, 3)
+	assert.Contains(t, kinds[WarningDeprecation].Message, "MCLI_")
+	assert.Contains(t, kinds[WarningPermissions].Message, p.Filename())
+	assert.Equal(t, TelemetryEnabledProperty, kinds[WarningPolicy].Key)
+}
@@ -0,0 +1,173 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package environment detects where the CLI is running: CI providers, containers, WSL, SSH sessions and terminals.
+package environment
+
+import (
+	"os"
+	"strings"
+)
+
+const (
+	ContainerizedEnv  = "MONGODB_ATLAS_IS_CONTAINERIZED" // ContainerizedEnv overrides container detection when set
+	GitHubActionsEnv  = "GITHUB_ACTIONS"
+	AtlasActionEnv    = "ATLAS_GITHUB_ACTION"
+	GitLabCIEnv       = "GITLAB_CI"
+	JenkinsURLEnv     = "JENKINS_URL"
+	CircleCIEnv       = "CIRCLECI"
+	CIEnv             = "CI"                           // CIEnv is set by most CI providers
+	NonInteractiveEnv = "MONGODB_ATLAS_NONINTERACTIVE" // NonInteractiveEnv overrides terminal and CI detection for prompts when set
+)
+
+// CIProvider identifies the CI system running the CLI.
+type CIProvider string
+
+const (
+	NoCI          CIProvider = ""
+	GitHubActions CIProvider = "github_actions"
+	GitLab        CIProvider = "gitlab"
+	Jenkins       CIProvider = "jenkins"
+	CircleCI      CIProvider = "circleci"
+	UnknownCI     CIProvider = "unknown" // UnknownCI is a CI system that only sets the CI env var
+)
+
+// Environment describes where the CLI is running.
+type Environment struct {
+	CI          CIProvider `json:"ci,omitempty"`
+	AtlasAction bool       `json:"atlas_action"` // AtlasAction is true when running from the Atlas GitHub Action
+	Container   bool       `json:"container"`
+	WSL         bool       `json:"wsl"`
+	SSH         bool       `json:"ssh"`
+	Interactive bool       `json:"interactive"` // Interactive is true when the user can be prompted, see IsInteractive
+}
+
+// IsCI returns true when running in a CI system.
+func (e *Environment) IsCI() bool {
+	return e.CI != NoCI
+}
+
+// IsInteractive returns true when the user can be prompted: stdin and stdout are terminals and the CLI doesn't run in CI.
+// MONGODB_ATLAS_NONINTERACTIVE takes precedence over the detection when set.
+func IsInteractive() bool {
+	return Detect().Interactive
+}
+
+// IsCI returns true when running in a CI system.
+func IsCI() bool {
+	return Detect().IsCI()
+}
+
+// probe reads the state of the system, replaced in tests.
+type probe struct {
+	lookupEnv  func(string) (string, bool)
+	exists     func(string) bool
+	readFile   func(string) ([]byte, error)
+	isTerminal func(*os.File) bool
+}
+
+var system = probe{
+	lookupEnv: os.LookupEnv,
+	exists: func(name string) bool {
+		_, err := os.Stat(name)
+		return err == nil
+	},
+	readFile:   os.ReadFile,
+	isTerminal: IsTerminal,
+}
+
+// Detect inspects the env vars, files and terminals of the current process.
+func Detect() *Environment {
+	return detect(system)
+}
+
+func detect(p probe) *Environment {
+	e := &Environment{
+		CI:          ciProvider(p),
+		AtlasAction: envIsTrue(p, AtlasActionEnv),
+		Container:   isContainer(p),
+		WSL:         isWSL(p),
+		SSH:         anyEnvSet(p, "SSH_CONNECTION", "SSH_CLIENT", "SSH_TTY"),
+	}
+	e.Interactive = isInteractive(p, e)
+	return e
+}
+
+func isInteractive(p probe, e *Environment) bool {
+	if value, ok := p.lookupEnv(NonInteractiveEnv); ok {
+		return !isTrue(value)
+	}
+	return !e.IsCI() && p.isTerminal(os.Stdin) && p.isTerminal(os.Stdout)
+}
+
+func ciProvider(p probe) CIProvider {
+	switch {
+	case envIsTrue(p, GitHubActionsEnv):
+		return GitHubActions
+	case envIsTrue(p, GitLabCIEnv):
+		return GitLab
+	case anyEnvSet(p, JenkinsURLEnv):
+		return Jenkins
+	case envIsTrue(p, CircleCIEnv):
+		return CircleCI
+	case envIsTrue(p, CIEnv):
+		return UnknownCI
+	default:
+		return NoCI
+	}
+}
+
+func isContainer(p probe) bool {
+	if value, ok := p.lookupEnv(ContainerizedEnv); ok {
+		return isTrue(value)
+	}
+	return p.exists("/.dockerenv") || p.exists("/run/.containerenv")
+}
+
+func isWSL(p probe) bool {
+	if anyEnvSet(p, "WSL_DISTRO_NAME", "WSL_INTEROP") {
+		return true
+	}
+	version, err := p.readFile("/proc/version")
+	return err == nil && strings.Contains(strings.ToLower(string(version)), "microsoft")
+}
+
+// IsTerminal returns true when f is a terminal.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func anyEnvSet(p probe, keys ...string) bool {
+	for _, key := range keys {
+		if value, ok := p.lookupEnv(key); ok && value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func envIsTrue(p probe, key string) bool {
+	value, ok := p.lookupEnv(key)
+	return ok && isTrue(value)
+}
+
+func isTrue(s string) bool {
+	switch strings.ToLower(s) {
+	case "t", "true", "y", "yes", "1":
+		return true
+	default:
+		return false
+	}
+}
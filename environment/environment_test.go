@@ -0,0 +1,146 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package environment
+
+import (
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeProbe(env map[string]string, files map[string]string, terminal bool) probe {
+	return probe{
+		lookupEnv: func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		},
+		exists: func(name string) bool {
+			_, ok := files[name]
+			return ok
+		},
+		readFile: func(name string) ([]byte, error) {
+			if f, ok := files[name]; ok {
+				return []byte(f), nil
+			}
+			return nil, fs.ErrNotExist
+		},
+		isTerminal: func(*os.File) bool { return terminal },
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		files    map[string]string
+		terminal bool
+		want     *Environment
+	}{
+		{
+			name:     "native terminal",
+			terminal: true,
+			want:     &Environment{Interactive: true},
+		},
+		{
+			name:     "github action",
+			env:      map[string]string{GitHubActionsEnv: "true", AtlasActionEnv: "true", CIEnv: "true"},
+			terminal: true,
+			want:     &Environment{CI: GitHubActions, AtlasAction: true},
+		},
+		{
+			name: "gitlab",
+			env:  map[string]string{GitLabCIEnv: "true", CIEnv: "true"},
+			want: &Environment{CI: GitLab},
+		},
+		{
+			name: "jenkins",
+			env:  map[string]string{JenkinsURLEnv: "https://jenkins.example.com"},
+			want: &Environment{CI: Jenkins},
+		},
+		{
+			name: "circleci",
+			env:  map[string]string{CircleCIEnv: "true", CIEnv: "true"},
+			want: &Environment{CI: CircleCI},
+		},
+		{
+			name: "unknown ci",
+			env:  map[string]string{CIEnv: "1"},
+			want: &Environment{CI: UnknownCI},
+		},
+		{
+			name:  "docker",
+			files: map[string]string{"/.dockerenv": ""},
+			want:  &Environment{Container: true},
+		},
+		{
+			name:  "container detection overridden",
+			env:   map[string]string{ContainerizedEnv: "false"},
+			files: map[string]string{"/.dockerenv": ""},
+			want:  &Environment{},
+		},
+		{
+			name: "container set by env",
+			env:  map[string]string{ContainerizedEnv: "true"},
+			want: &Environment{Container: true},
+		},
+		{
+			name:  "wsl",
+			files: map[string]string{"/proc/version": "Linux version 5.15.153.1-microsoft-standard-WSL2"},
+			want:  &Environment{WSL: true},
+		},
+		{
+			name:     "ssh",
+			env:      map[string]string{"SSH_CONNECTION": "10.0.0.1 52000 10.0.0.2 22"},
+			terminal: true,
+			want:     &Environment{SSH: true, Interactive: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detect(fakeProbe(tt.env, tt.files, tt.terminal))
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want.CI != NoCI, got.IsCI())
+		})
+	}
+}
+
+func Test_isInteractive(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		terminal bool
+		want     bool
+	}{
+		{name: "terminal", terminal: true, want: true},
+		{name: "no terminal", want: false},
+		{name: "ci", env: map[string]string{CIEnv: "true"}, terminal: true, want: false},
+		{name: "forced non interactive", env: map[string]string{NonInteractiveEnv: "true"}, terminal: true, want: false},
+		{name: "forced interactive", env: map[string]string{NonInteractiveEnv: "false", CIEnv: "true"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, detect(fakeProbe(tt.env, nil, tt.terminal)).Interactive)
+		})
+	}
+}
+
+func TestIsInteractive(t *testing.T) {
+	t.Setenv(NonInteractiveEnv, "1")
+	assert.False(t, IsInteractive())
+}
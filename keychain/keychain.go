@@ -0,0 +1,85 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package keychain stores credentials in the macOS Keychain, see config.SetSecretStore.
+package keychain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mongodb/atlas-cli-core/config"
+)
+
+const DefaultService = "atlascli" // DefaultService is the service name of the keychain items unless WithService is used
+
+var (
+	ErrUnsupported        = errors.New("the keychain is only supported on macOS")
+	ErrKeychain           = errors.New("keychain error")
+	ErrMissingEntitlement = errors.New("access groups and Touch ID require a signed binary with the keychain-access-groups entitlement")
+)
+
+// Store is a config.SecretStore backed by generic password items of the macOS Keychain.
+// The item account is profile.key, for example default.private_api_key.
+type Store struct {
+	service     string
+	accessGroup string
+	biometry    bool
+}
+
+var _ config.SecretStore = (*Store)(nil)
+
+type Option func(*Store)
+
+// WithService sets the service name of the keychain items.
+func WithService(name string) Option {
+	return func(s *Store) {
+		s.service = name
+	}
+}
+
+// WithAccessGroup shares the keychain items with the other applications of the access group.
+func WithAccessGroup(group string) Option {
+	return func(s *Store) {
+		s.accessGroup = group
+	}
+}
+
+// WithBiometry requires Touch ID, or the device passcode, to read the secrets stored from now on.
+func WithBiometry() Option {
+	return func(s *Store) {
+		s.biometry = true
+	}
+}
+
+func New(opts ...Option) *Store {
+	s := &Store{service: DefaultService}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func account(profile, key string) string {
+	return profile + "." + key
+}
+
+// statusError describes an error status of the Security framework.
+func statusError(status int) error {
+	const errSecMissingEntitlement = -34018
+	if status == errSecMissingEntitlement {
+		return ErrMissingEntitlement
+	}
+	return fmt.Errorf("%w: OSStatus %d", ErrKeychain, status)
+}
@@ -0,0 +1,202 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build darwin && cgo
+
+package keychain
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+#include <stdlib.h>
+#include <string.h>
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+
+static void setString(CFMutableDictionaryRef query, CFStringRef key, const char *value) {
+	CFStringRef s = CFStringCreateWithCString(kCFAllocatorDefault, value, kCFStringEncodingUTF8);
+	CFDictionarySetValue(query, key, s);
+	CFRelease(s);
+}
+
+// itemQuery matches an item, in the data protection keychain when dataProtection is set, the file keychain otherwise.
+static CFMutableDictionaryRef itemQuery(const char *service, const char *account, const char *group, int dataProtection) {
+	CFMutableDictionaryRef query = CFDictionaryCreateMutable(kCFAllocatorDefault, 0, &kCFTypeDictionaryKeyCallBacks, &kCFTypeDictionaryValueCallBacks);
+	CFDictionarySetValue(query, kSecClass, kSecClassGenericPassword);
+	setString(query, kSecAttrService, service);
+	setString(query, kSecAttrAccount, account);
+	if (group[0] != '\0') {
+		setString(query, kSecAttrAccessGroup, group);
+	}
+	if (dataProtection) {
+		CFDictionarySetValue(query, kSecUseDataProtectionKeychain, kCFBooleanTrue);
+	}
+	return query;
+}
+
+static OSStatus itemGet(const char *service, const char *account, const char *group, int dataProtection, void **data, size_t *length) {
+	CFMutableDictionaryRef query = itemQuery(service, account, group, dataProtection);
+	CFDictionarySetValue(query, kSecReturnData, kCFBooleanTrue);
+	CFDictionarySetValue(query, kSecMatchLimit, kSecMatchLimitOne);
+
+	CFTypeRef result = NULL;
+	OSStatus status = SecItemCopyMatching(query, &result);
+	CFRelease(query);
+	if (status != errSecSuccess) {
+		return status;
+	}
+
+	*length = (size_t)CFDataGetLength((CFDataRef)result);
+	*data = malloc(*length);
+	memcpy(*data, CFDataGetBytePtr((CFDataRef)result), *length);
+	CFRelease(result);
+	return errSecSuccess;
+}
+
+static OSStatus itemSet(const char *service, const char *account, const char *group, int dataProtection, int biometry, const void *data, size_t length) {
+	CFMutableDictionaryRef query = itemQuery(service, account, group, dataProtection);
+	// the access control of an existing item can't be changed, replace it
+	SecItemDelete(query);
+
+	CFDataRef value = CFDataCreate(kCFAllocatorDefault, data, (CFIndex)length);
+	CFDictionarySetValue(query, kSecValueData, value);
+	CFRelease(value);
+
+	if (biometry) {
+		SecAccessControlRef access = SecAccessControlCreateWithFlags(kCFAllocatorDefault,
+			kSecAttrAccessibleWhenUnlockedThisDeviceOnly, kSecAccessControlUserPresence, NULL);
+		if (access == NULL) {
+			CFRelease(query);
+			return errSecParam;
+		}
+		CFDictionarySetValue(query, kSecAttrAccessControl, access);
+		CFRelease(access);
+	}
+
+	OSStatus status = SecItemAdd(query, NULL);
+	CFRelease(query);
+	return status;
+}
+
+static OSStatus itemDelete(const char *service, const char *account, const char *group, int dataProtection) {
+	CFMutableDictionaryRef query = itemQuery(service, account, group, dataProtection);
+	OSStatus status = SecItemDelete(query);
+	CFRelease(query);
+	return status;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/mongodb/atlas-cli-core/config"
+)
+
+// cStrings holds the C strings identifying a keychain item.
+type cStrings struct {
+	service, account, group *C.char
+}
+
+func (s *Store) item(profile, key string) cStrings {
+	return cStrings{
+		service: C.CString(s.service),
+		account: C.CString(account(profile, key)),
+		group:   C.CString(s.accessGroup),
+	}
+}
+
+func (c cStrings) free() {
+	C.free(unsafe.Pointer(c.service))
+	C.free(unsafe.Pointer(c.account))
+	C.free(unsafe.Pointer(c.group))
+}
+
+// dataProtection is set when the items are in the data protection keychain, required by access groups and Touch ID.
+func (s *Store) dataProtection() C.int {
+	if s.accessGroup != "" || s.biometry {
+		return 1
+	}
+	return 0
+}
+
+// hasLegacyItems is true when items stored before WithBiometry was used can still be in the file keychain.
+func (s *Store) hasLegacyItems() bool {
+	return s.biometry && s.accessGroup == ""
+}
+
+// Secret reads a secret, prompting for Touch ID when the item requires it.
+func (s *Store) Secret(profile, key string) (string, error) {
+	item := s.item(profile, key)
+	defer item.free()
+
+	var data unsafe.Pointer
+	var length C.size_t
+	status := C.itemGet(item.service, item.account, item.group, s.dataProtection(), &data, &length)
+	if status == C.errSecItemNotFound && s.hasLegacyItems() {
+		status = C.itemGet(item.service, item.account, item.group, 0, &data, &length)
+	}
+	switch status {
+	case C.errSecSuccess:
+	case C.errSecItemNotFound:
+		return "", fmt.Errorf("%w: %s", config.ErrSecretNotFound, account(profile, key))
+	default:
+		return "", statusError(int(status))
+	}
+	defer C.free(data)
+	return C.GoStringN((*C.char)(data), C.int(length)), nil
+}
+
+// SetSecret adds or replaces a secret.
+func (s *Store) SetSecret(profile, key, value string) error {
+	item := s.item(profile, key)
+	defer item.free()
+
+	data := C.CBytes([]byte(value))
+	defer C.free(data)
+
+	biometry := C.int(0)
+	if s.biometry {
+		biometry = 1
+	}
+	if status := C.itemSet(item.service, item.account, item.group, s.dataProtection(), biometry, data, C.size_t(len(value))); status != C.errSecSuccess {
+		return statusError(int(status))
+	}
+	if s.hasLegacyItems() {
+		// the item now requires Touch ID, don't leave a copy that doesn't
+		C.itemDelete(item.service, item.account, item.group, 0)
+	}
+	return nil
+}
+
+// DeleteSecret removes a secret.
+func (s *Store) DeleteSecret(profile, key string) error {
+	item := s.item(profile, key)
+	defer item.free()
+
+	status := C.itemDelete(item.service, item.account, item.group, s.dataProtection())
+	if s.hasLegacyItems() {
+		if legacy := C.itemDelete(item.service, item.account, item.group, 0); status == C.errSecItemNotFound {
+			status = legacy
+		}
+	}
+	switch status {
+	case C.errSecSuccess:
+		return nil
+	case C.errSecItemNotFound:
+		return fmt.Errorf("%w: %s", config.ErrSecretNotFound, account(profile, key))
+	default:
+		return statusError(int(status))
+	}
+}
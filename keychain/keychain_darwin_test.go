@@ -0,0 +1,49 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build darwin && cgo && unit
+
+package keychain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_biometry(t *testing.T) {
+	s := New(WithService("atlascli-test"), WithBiometry())
+	err := s.SetSecret("test", "private_api_key", "secret")
+	if errors.Is(err, ErrMissingEntitlement) {
+		t.Skip("the test binary is not signed with the keychain-access-groups entitlement")
+	}
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.DeleteSecret("test", "private_api_key") })
+
+	v, err := s.Secret("test", "private_api_key")
+	require.NoError(t, err, "the item is read from the data protection keychain it was added to")
+	assert.Equal(t, "secret", v)
+
+	require.NoError(t, s.SetSecret("test", "private_api_key", "rotated"), "the existing item is replaced")
+	v, err = s.Secret("test", "private_api_key")
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", v)
+
+	require.NoError(t, s.DeleteSecret("test", "private_api_key"))
+	_, err = s.Secret("test", "private_api_key")
+	require.ErrorIs(t, err, config.ErrSecretNotFound)
+}
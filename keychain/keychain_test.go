@@ -0,0 +1,41 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package keychain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	s := New()
+	assert.Equal(t, DefaultService, s.service)
+	assert.Empty(t, s.accessGroup)
+	assert.False(t, s.biometry)
+
+	s = New(WithService("atlas-work"), WithAccessGroup("ABCDE12345.com.mongodb.atlas"), WithBiometry())
+	assert.Equal(t, "atlas-work", s.service)
+	assert.Equal(t, "ABCDE12345.com.mongodb.atlas", s.accessGroup)
+	assert.True(t, s.biometry)
+}
+
+func TestStatusError(t *testing.T) {
+	assert.ErrorIs(t, statusError(-34018), ErrMissingEntitlement)
+	assert.ErrorIs(t, statusError(-25293), ErrKeychain)
+	assert.Equal(t, "default.private_api_key", account("default", "private_api_key"))
+}
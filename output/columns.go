@@ -0,0 +1,65 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mongodb/atlas-cli-core/config"
+)
+
+var ErrUnknownColumn = errors.New("unknown column")
+
+// PrintResource writes v to w like Print, the plaintext output uses the columns configured
+// for the resource in the profile, see config.Columns.
+func PrintResource(w io.Writer, resource string, v any, columns ...Column) error {
+	p := config.Default()
+	r, err := NewRenderer(p)
+	if err != nil {
+		return err
+	}
+	r.Columns = columns
+	r.ColumnNames = p.Columns(resource)
+	return r.Render(w, v)
+}
+
+// selectColumns picks the columns by name, in the given order, all the columns when no names are given.
+func selectColumns(columns []Column, names []string) ([]Column, error) {
+	if len(names) == 0 {
+		return columns, nil
+	}
+	available := make([]string, len(columns))
+	for i, c := range columns {
+		available[i] = config.ColumnName(c.Header)
+	}
+	selected := make([]Column, len(names))
+	for i, name := range names {
+		j := -1
+		for k, a := range available {
+			if a == config.ColumnName(name) {
+				j = k
+				break
+			}
+		}
+		if j < 0 {
+			return nil, fmt.Errorf("%w: %q, available are %s", ErrUnknownColumn, name, strings.Join(available, ","))
+		}
+		selected[i] = columns[j]
+	}
+	return selected, nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderer_ColumnNames(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := &Renderer{Format: config.PlaintextOutput, ColumnNames: []string{"PRIVATEKEY", "id"}}
+	require.NoError(t, r.Render(buf, keys))
+	assert.Equal(t, "PRIVATEKEY   ID\nredacted     1\n             2\n", buf.String())
+
+	buf.Reset()
+	r = &Renderer{Format: config.PlaintextOutput, ColumnNames: []string{"PUBLIC_KEY"}, Columns: []Column{
+		{Header: "ID", Template: "{{.ID}}"},
+		{Header: "PUBLIC KEY", Template: "{{.PublicKey}}"},
+	}}
+	require.NoError(t, r.Render(buf, keys))
+	assert.Equal(t, "PUBLIC KEY\nabc\ndefgh\n", buf.String())
+
+	r.ColumnNames = []string{"NAME"}
+	require.ErrorIs(t, r.Render(buf, keys), ErrUnknownColumn)
+
+	buf.Reset()
+	r = &Renderer{Format: config.JSONOutput, ColumnNames: []string{"NAME"}}
+	require.NoError(t, r.Render(buf, keys[1]), "only the plaintext output uses columns")
+}
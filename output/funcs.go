@@ -0,0 +1,130 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// funcs returns the functions of the go-template output and of the plaintext columns, modeled after sprig:
+//
+//	{{json .}} and {{prettyJSON .}} encode a value as JSON
+//	{{join ", " .Tags}} joins the elements of a list
+//	{{date "2006-01-02" .Created}} formats a time, an RFC 3339 string or Unix seconds with a Go layout
+//	{{formatTime .Created}} formats a time with the profile's time_format and locale
+//	{{trunc 8 .ID}} keeps the first 8 characters, or the last 8 with -8
+//	{{upper .Name}}, {{lower .Name}} and {{trim .Name}} change strings
+//	{{default "none" .Comment}} replaces empty values
+func (r *Renderer) funcs() template.FuncMap {
+	return template.FuncMap{
+		"json":       toJSON,
+		"prettyJSON": toPrettyJSON,
+		"join":       join,
+		"date":       date,
+		"formatTime": func(t time.Time) string {
+			return FormatTime(t, r.TimeFormat, r.Locale)
+		},
+		"trunc":   trunc,
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"trim":    strings.TrimSpace,
+		"default": defaultValue,
+	}
+}
+
+func toJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func toPrettyJSON(v any) (string, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	return string(b), err
+}
+
+func join(sep string, list any) (string, error) {
+	rv := reflect.ValueOf(list)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: %T is not a list", list)
+	}
+	items := make([]string, rv.Len())
+	for i := range items {
+		items[i] = fmt.Sprint(rv.Index(i).Interface())
+	}
+	return strings.Join(items, sep), nil
+}
+
+func date(layout string, v any) (string, error) {
+	var t time.Time
+	switch v := v.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v == nil {
+			return "", nil
+		}
+		t = *v
+	case string:
+		if v == "" {
+			return "", nil
+		}
+		var err error
+		if t, err = time.Parse(time.RFC3339, v); err != nil {
+			return "", fmt.Errorf("date: %w", err)
+		}
+	case int64:
+		t = time.Unix(v, 0)
+	case int:
+		t = time.Unix(int64(v), 0)
+	case float64:
+		t = time.Unix(int64(v), 0)
+	default:
+		return "", fmt.Errorf("date: %T is not a time", v)
+	}
+	if t.IsZero() {
+		return "", nil
+	}
+	return t.UTC().Format(layout), nil
+}
+
+// trunc keeps the first n runes of s, or the last -n runes when n is negative.
+func trunc(n int, s string) string {
+	runes := []rune(s)
+	switch {
+	case n >= 0 && n < len(runes):
+		return string(runes[:n])
+	case n < 0 && -n < len(runes):
+		return string(runes[len(runes)+n:])
+	default:
+		return s
+	}
+}
+
+// defaultValue returns v unless it's nil or the zero value of its type, like an empty string or list.
+func defaultValue(def, v any) any {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.IsZero() {
+		return def
+	}
+	if (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map) && rv.Len() == 0 {
+		return def
+	}
+	return v
+}
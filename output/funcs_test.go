@@ -0,0 +1,88 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderer_funcs(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	v := map[string]any{
+		"id":      "5e2211c17a3e5a48f5497de3",
+		"name":    " Cluster0 ",
+		"tags":    []string{"prod", "eu"},
+		"created": created,
+		"raw":     "2024-03-01T12:30:00Z",
+		"comment": "",
+	}
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{template: `{{json .tags}}`, want: `["prod","eu"]`},
+		{template: `{{prettyJSON .tags}}`, want: "[\n  \"prod\",\n  \"eu\"\n]"},
+		{template: `{{join ", " .tags}}`, want: "prod, eu"},
+		{template: `{{.tags | join "/"}}`, want: "prod/eu"},
+		{template: `{{date "2006-01-02" .created}}`, want: "2024-03-01"},
+		{template: `{{date "Jan 2" .raw}}`, want: "Mar 1"},
+		{template: `{{formatTime .created}}`, want: "2024-03-01T12:30:00Z"},
+		{template: `{{trunc 4 .id}}`, want: "5e22"},
+		{template: `{{trunc -4 .id}}`, want: "7de3"},
+		{template: `{{trunc 100 .id}}`, want: "5e2211c17a3e5a48f5497de3"},
+		{template: `{{.name | trim | upper}}`, want: "CLUSTER0"},
+		{template: `{{lower "ABC"}}`, want: "abc"},
+		{template: `{{default "none" .comment}}`, want: "none"},
+		{template: `{{default "none" .missing}}`, want: "none"},
+		{template: `{{default "none" .id}}`, want: "5e2211c17a3e5a48f5497de3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			r := &Renderer{Format: config.GoTemplateOutput, Argument: tt.template}
+			require.NoError(t, r.Render(buf, v))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+
+	r := &Renderer{Format: config.GoTemplateOutput, Argument: `{{join "," .id}}`}
+	require.ErrorContains(t, r.Render(&bytes.Buffer{}, v), "is not a list")
+	r = &Renderer{Format: config.GoTemplateOutput, Argument: `{{date "2006" .name}}`}
+	require.ErrorContains(t, r.Render(&bytes.Buffer{}, v), "date")
+}
+
+func TestRenderer_templateCache(t *testing.T) {
+	r := &Renderer{}
+	first, err := r.template("output", "{{.}}")
+	require.NoError(t, err)
+	second, err := r.template("output", "{{.}}")
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	other, err := r.template("output", "{{json .}}")
+	require.NoError(t, err)
+	assert.NotSame(t, first, other)
+
+	_, err = r.template("output", "{{")
+	require.Error(t, err)
+}
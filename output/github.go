@@ -0,0 +1,186 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/mongodb/atlas-cli-core/environment"
+)
+
+const GitHubOutputEnv = "GITHUB_OUTPUT" // GitHubOutputEnv is the file GitHub Actions reads the step outputs from
+
+// AnnotationLevel is the level of a GitHub Actions workflow annotation.
+type AnnotationLevel string
+
+const (
+	AnnotationError   AnnotationLevel = "error"
+	AnnotationWarning AnnotationLevel = "warning"
+	AnnotationNotice  AnnotationLevel = "notice"
+)
+
+var (
+	ErrNoStepOutput      = errors.New(GitHubOutputEnv + " is not set, step outputs are only available in GitHub Actions")
+	ErrInvalidOutputName = errors.New("invalid step output name, expected letters, digits, - and _")
+)
+
+var outputName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// Annotation is a GitHub Actions workflow annotation, shown in the summary of the run and on the files of pull requests.
+type Annotation struct {
+	Level   AnnotationLevel
+	Message string
+	Title   string // Title is optional, like the location fields
+	File    string
+	Line    int
+}
+
+// GitHubActions returns true when running in a GitHub Actions workflow, where annotations and step outputs are used.
+func GitHubActions() bool {
+	return environment.Detect().CI == environment.GitHubActions
+}
+
+// Annotate writes a as a workflow command, usually to stdout, so GitHub Actions shows it as an annotation.
+func Annotate(w io.Writer, a Annotation) error {
+	var properties []string
+	for _, p := range []struct{ name, value string }{
+		{"file", a.File},
+		{"line", lineNumber(a.Line)},
+		{"title", a.Title},
+	} {
+		if p.value != "" {
+			properties = append(properties, p.name+"="+escapeProperty(p.value))
+		}
+	}
+	command := "::" + string(a.Level)
+	if len(properties) > 0 {
+		command += " " + strings.Join(properties, ",")
+	}
+	_, err := fmt.Fprintf(w, "%s::%s\n", command, escapeData(a.Message))
+	return err
+}
+
+// AnnotateError writes err as an error annotation, like the error of a failed command.
+func AnnotateError(w io.Writer, err error) error {
+	return Annotate(w, Annotation{Level: AnnotationError, Message: err.Error()})
+}
+
+// AnnotateWarnings writes the warnings as warning annotations titled by their kind, the GitHub Actions
+// equivalent of PrintWarnings.
+func AnnotateWarnings(w io.Writer, warnings []config.Warning) error {
+	for _, warning := range warnings {
+		if err := Annotate(w, Annotation{Level: AnnotationWarning, Title: string(warning.Kind), Message: warning.Message}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func lineNumber(line int) string {
+	if line <= 0 {
+		return ""
+	}
+	return strconv.Itoa(line)
+}
+
+// escapeData escapes the message of a workflow command, as the GitHub Actions toolkit does.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes the value of a property of a workflow command, as the GitHub Actions toolkit does.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// SetStepOutputs sets the fields of v, a struct or a map, as the outputs of the current step,
+// so the next steps of the workflow can use them, like ${{ steps.cluster.outputs.stateName }}.
+// It returns ErrNoStepOutput when not running in GitHub Actions.
+func SetStepOutputs(v any) error {
+	name := os.Getenv(GitHubOutputEnv)
+	if name == "" {
+		return ErrNoStepOutput
+	}
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	err = WriteStepOutputs(f, v)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// WriteStepOutputs writes the fields of v to w in the format of the GITHUB_OUTPUT file, see SetStepOutputs.
+// Fields are named after their JSON names, strings are written as is and other values as JSON.
+func WriteStepOutputs(w io.Writer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil || fields == nil {
+		return fmt.Errorf("step outputs must be an object, got %s", b)
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		if !outputName.MatchString(name) {
+			return fmt.Errorf("%w: %q", ErrInvalidOutputName, name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := string(fields[name])
+		var s string
+		if json.Unmarshal(fields[name], &s) == nil {
+			value = s
+		}
+		delimiter, err := outputDelimiter(value)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s<<%s\n%s\n%s\n", name, delimiter, value, delimiter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputDelimiter returns a random delimiter for a multiline step output that value doesn't contain.
+func outputDelimiter(value string) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	delimiter := "ghadelimiter_" + hex.EncodeToString(b)
+	if strings.Contains(value, delimiter) {
+		return outputDelimiter(value)
+	}
+	return delimiter, nil
+}
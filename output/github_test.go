@@ -0,0 +1,84 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package output
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/mongodb/atlas-cli-core/environment"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubActions(t *testing.T) {
+	t.Setenv(environment.GitHubActionsEnv, "true")
+	assert.True(t, GitHubActions())
+}
+
+func TestAnnotate(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, Annotate(&b, Annotation{Level: AnnotationNotice, Message: "done"}))
+	require.NoError(t, Annotate(&b, Annotation{
+		Level:   AnnotationError,
+		Title:   "Invalid spec: cluster, tier",
+		File:    "atlas/cluster.json",
+		Line:    12,
+		Message: "100% invalid\nsee the docs",
+	}))
+	require.NoError(t, AnnotateError(&b, errors.New("cluster not found")))
+	require.NoError(t, AnnotateWarnings(&b, []config.Warning{{Kind: config.WarningDeprecation, Key: "k", Message: "k is deprecated"}}))
+
+	assert.Equal(t, "::notice::done\n"+
+		"::error file=atlas/cluster.json,line=12,title=Invalid spec%3A cluster%2C tier::100%25 invalid%0Asee the docs\n"+
+		"::error::cluster not found\n"+
+		"::warning title="+string(config.WarningDeprecation)+"::k is deprecated\n", b.String())
+}
+
+func TestSetStepOutputs(t *testing.T) {
+	t.Setenv(GitHubOutputEnv, "")
+	require.ErrorIs(t, SetStepOutputs(map[string]string{"id": "1"}), ErrNoStepOutput)
+
+	name := filepath.Join(t.TempDir(), "output")
+	t.Setenv(GitHubOutputEnv, name)
+	require.NoError(t, os.WriteFile(name, []byte("previous<<EOF\nstep\nEOF\n"), 0o600))
+
+	type cluster struct {
+		Name      string   `json:"name"`
+		StateName string   `json:"stateName"`
+		Nodes     int      `json:"nodes"`
+		Tags      []string `json:"tags"`
+		Notes     string   `json:"notes"`
+	}
+	require.NoError(t, SetStepOutputs(cluster{Name: "c1", StateName: "IDLE", Nodes: 3, Tags: []string{"a"}, Notes: "line 1\nline 2"}))
+
+	b, err := os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Regexp(t, `^previous<<EOF\nstep\nEOF\n`+
+		`name<<ghadelimiter_\w+\nc1\nghadelimiter_\w+\n`+
+		`nodes<<\S+\n3\n\S+\n`+
+		`notes<<\S+\nline 1\nline 2\n\S+\n`+
+		`stateName<<\S+\nIDLE\n\S+\n`+
+		`tags<<\S+\n\["a"\]\n\S+\n$`, string(b))
+
+	require.ErrorIs(t, WriteStepOutputs(&bytes.Buffer{}, map[string]int{"not valid": 1}), ErrInvalidOutputName)
+	require.Error(t, WriteStepOutputs(&bytes.Buffer{}, []string{"a"}), "outputs must be named")
+}
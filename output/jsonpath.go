@@ -0,0 +1,316 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidJSONPath = errors.New("invalid JSONPath expression")
+
+// selector returns the nodes selected from a JSON node.
+type selector func(node any) []any
+
+// jsonPathStep applies a selector to the children, or with recursive descent to every descendant, of the current nodes.
+type jsonPathStep struct {
+	recursive bool
+	selector  selector
+}
+
+// compileJSONPath parses a JSONPath expression, supported are:
+// $ the root, .name and ['name'] children, .* and [*] wildcards, [0] and [-1] indexes,
+// [1:3] slices, [0,2] and ['a','b'] unions, and ..name recursive descent.
+func compileJSONPath(path string) ([]jsonPathStep, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w: %q, %s", ErrInvalidJSONPath, path, reason)
+	}
+
+	rest, ok := strings.CutPrefix(strings.TrimSpace(path), "$")
+	if !ok {
+		return nil, invalid("should start with $")
+	}
+
+	var steps []jsonPathStep
+	for rest != "" {
+		step := jsonPathStep{}
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			step.recursive = true
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				break
+			}
+			fallthrough
+		case strings.HasPrefix(rest, "."):
+			rest = strings.TrimPrefix(rest, ".")
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			if name == "" {
+				return nil, invalid("missing field name")
+			}
+			step.selector = nameSelector(name)
+			steps = append(steps, step)
+			continue
+		case !strings.HasPrefix(rest, "["):
+			return nil, invalid(fmt.Sprintf("unexpected %q", rest))
+		}
+
+		end := closingBracket(rest)
+		if end < 0 {
+			return nil, invalid("missing ]")
+		}
+		s, err := bracketSelector(rest[1:end])
+		if err != nil {
+			return nil, invalid(err.Error())
+		}
+		step.selector = s
+		rest = rest[end+1:]
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// closingBracket returns the index of the ] closing the [ at the start of s, skipping quoted names.
+func closingBracket(s string) int {
+	var quote rune
+	for i, r := range s {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+		case r == '\'' || r == '"':
+			quote = r
+		case r == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+func nameSelector(name string) selector {
+	if name == "*" {
+		return wildcard
+	}
+	return func(node any) []any {
+		if m, ok := node.(map[string]any); ok {
+			if v, ok := m[name]; ok {
+				return []any{v}
+			}
+		}
+		return nil
+	}
+}
+
+func wildcard(node any) []any {
+	switch n := node.(type) {
+	case []any:
+		return n
+	case map[string]any:
+		keys := make([]string, 0, len(n))
+		for k := range n {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		values := make([]any, len(keys))
+		for i, k := range keys {
+			values[i] = n[k]
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+func bracketSelector(expr string) (selector, error) {
+	expr = strings.TrimSpace(expr)
+	switch {
+	case expr == "*":
+		return wildcard, nil
+	case expr == "":
+		return nil, errors.New("empty brackets")
+	case strings.Contains(expr, ":"):
+		return sliceSelector(expr)
+	}
+
+	var selectors []selector
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if name, ok := unquote(part); ok {
+			selectors = append(selectors, nameSelector(name))
+			continue
+		}
+		i, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index %q", part)
+		}
+		selectors = append(selectors, indexSelector(i))
+	}
+	return func(node any) []any {
+		var values []any
+		for _, s := range selectors {
+			values = append(values, s(node)...)
+		}
+		return values
+	}, nil
+}
+
+func unquote(s string) (string, bool) {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1], true
+	}
+	return "", false
+}
+
+// normalizeIndex resolves negative indexes from the end of a list of length n.
+func normalizeIndex(i, n int) int {
+	if i < 0 {
+		return i + n
+	}
+	return i
+}
+
+func indexSelector(i int) selector {
+	return func(node any) []any {
+		list, ok := node.([]any)
+		if !ok {
+			return nil
+		}
+		if j := normalizeIndex(i, len(list)); j >= 0 && j < len(list) {
+			return []any{list[j]}
+		}
+		return nil
+	}
+}
+
+func sliceSelector(expr string) (selector, error) {
+	startExpr, endExpr, _ := strings.Cut(expr, ":")
+	bound := func(s string) (*int, error) {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return nil, nil
+		}
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slice %q", expr)
+		}
+		return &i, nil
+	}
+	start, err := bound(startExpr)
+	if err != nil {
+		return nil, err
+	}
+	end, err := bound(endExpr)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(node any) []any {
+		list, ok := node.([]any)
+		if !ok {
+			return nil
+		}
+		from, to := 0, len(list)
+		if start != nil {
+			from = max(0, normalizeIndex(*start, len(list)))
+		}
+		if end != nil {
+			to = min(len(list), normalizeIndex(*end, len(list)))
+		}
+		if from >= to {
+			return nil
+		}
+		return list[from:to]
+	}, nil
+}
+
+// descendants returns the node and every node nested in it.
+func descendants(node any) []any {
+	nodes := []any{node}
+	for _, child := range wildcard(node) {
+		nodes = append(nodes, descendants(child)...)
+	}
+	return nodes
+}
+
+// evalJSONPath returns the values of v selected by the path.
+// v is converted to JSON first so that the json struct tags are used for field names.
+func evalJSONPath(path string, v any) ([]any, error) {
+	steps, err := compileJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var root any
+	if err := decoder.Decode(&root); err != nil {
+		return nil, err
+	}
+
+	nodes := []any{root}
+	for _, step := range steps {
+		var next []any
+		for _, node := range nodes {
+			if step.recursive {
+				for _, d := range descendants(node) {
+					next = append(next, step.selector(d)...)
+				}
+				continue
+			}
+			next = append(next, step.selector(node)...)
+		}
+		nodes = next
+	}
+	return nodes, nil
+}
+
+// renderJSONPath prints every selected value on its own line, strings as is and everything else as JSON.
+func renderJSONPath(w io.Writer, path string, v any) error {
+	values, err := evalJSONPath(path, v)
+	if err != nil {
+		return err
+	}
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			if _, err := fmt.Fprintln(w, s); err != nil {
+				return err
+			}
+			continue
+		}
+		b, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, string(b)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cluster struct {
+	Name        string         `json:"name"`
+	DiskSizeGB  int            `json:"diskSizeGB"`
+	Labels      map[string]any `json:"labels,omitempty"`
+	Connections []string       `json:"connections,omitempty"`
+}
+
+var page = struct {
+	Results    []cluster `json:"results"`
+	TotalCount int       `json:"totalCount"`
+}{
+	Results: []cluster{
+		{Name: "a", DiskSizeGB: 10, Labels: map[string]any{"env": "prod", "team": "x"}},
+		{Name: "b", DiskSizeGB: 20, Connections: []string{"mongodb+srv://b"}},
+		{Name: "c", DiskSizeGB: 30},
+	},
+	TotalCount: 3,
+}
+
+func Test_evalJSONPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want []any
+	}{
+		{path: "$.totalCount", want: []any{json.Number("3")}},
+		{path: "$.results[*].name", want: []any{"a", "b", "c"}},
+		{path: "$['results'][0]['name']", want: []any{"a"}},
+		{path: "$.results[-1].diskSizeGB", want: []any{json.Number("30")}},
+		{path: "$.results[1:].name", want: []any{"b", "c"}},
+		{path: "$.results[:-2].name", want: []any{"a"}},
+		{path: "$.results[0,2].name", want: []any{"a", "c"}},
+		{path: "$.results[0].labels.*", want: []any{"prod", "x"}},
+		{path: "$..connections[0]", want: []any{"mongodb+srv://b"}},
+		{path: "$..['env','team']", want: []any{"prod", "x"}},
+		{path: "$.missing", want: nil},
+		{path: "$.results[10]", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := evalJSONPath(tt.path, page)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_compileJSONPath_invalid(t *testing.T) {
+	for _, path := range []string{"results", "$.results[0", "$.results[a]", "$.", "$results", "$.results[1:x]", "$[]"} {
+		t.Run(path, func(t *testing.T) {
+			_, err := compileJSONPath(path)
+			require.ErrorIs(t, err, ErrInvalidJSONPath)
+		})
+	}
+}
+
+func TestRenderer_Render_jsonPath(t *testing.T) {
+	format, path, err := config.ParseOutputFormat("jsonpath=$.results[*]")
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	r := &Renderer{Format: format, Argument: path}
+	require.NoError(t, r.Render(buf, map[string]any{"results": []any{"a", 1, map[string]any{"b": true}}}))
+	assert.Equal(t, "a\n1\n{\"b\":true}\n", buf.String())
+}
@@ -0,0 +1,268 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package output prints command results in the format configured in the profile's output setting.
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported output format")
+
+// Column is a column of the plaintext output.
+type Column struct {
+	Header   string
+	Template string // Template is a Go template executed against each row, like {{.Name}}
+}
+
+// Redactor is implemented by values holding secrets, Redacted returns a copy that is safe to print.
+type Redactor interface {
+	Redacted() any
+}
+
+// Renderer prints values in one of the config.OutputFormats.
+type Renderer struct {
+	Format   config.OutputFormat
+	Argument string   // Argument is the template or path of formats that require one
+	Columns  []Column // Columns of the plaintext output, by default one per exported struct field
+	// ColumnNames select and order the Columns by header, like NAME and MDB_VER for the headers Name and MDB Ver.
+	ColumnNames []string
+	// Redact is called with every value before printing it, after Redactor.Redacted, to hide secrets.
+	Redact func(any) any
+	// TimeFormat and Locale are used by the formatTime template function, like {{formatTime .Created}}
+	TimeFormat config.TimeFormat
+	Locale     string
+
+	mu        sync.Mutex
+	templates map[templateKey]*template.Template
+}
+
+type templateKey struct {
+	name, text string
+}
+
+// NewRenderer returns a Renderer for the profile's output setting.
+func NewRenderer(p *config.Profile) (*Renderer, error) {
+	format, arg, err := p.OutputFormat()
+	if err != nil {
+		return nil, err
+	}
+	return &Renderer{
+		Format:     format,
+		Argument:   arg,
+		TimeFormat: p.TimeFormat(),
+		Locale:     p.Locale(),
+	}, nil
+}
+
+// Print writes v to w in the format configured in the default profile, columns are used for plaintext output.
+func Print(w io.Writer, v any, columns ...Column) error {
+	r, err := NewRenderer(config.Default())
+	if err != nil {
+		return err
+	}
+	r.Columns = columns
+	return r.Render(w, v)
+}
+
+// Render writes v to w.
+// Slices are printed as lists, plaintext output prints one row per element.
+func (r *Renderer) Render(w io.Writer, v any) error {
+	v = r.redact(v)
+
+	switch r.Format {
+	case config.JSONOutput:
+		return renderJSON(w, v)
+	case config.YAMLOutput:
+		return renderYAML(w, v)
+	case config.GoTemplateOutput:
+		return r.renderTemplate(w, v)
+	case config.JSONPathOutput:
+		return renderJSONPath(w, r.Argument, v)
+	case config.PlaintextOutput, "":
+		return r.renderTable(w, v)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, r.Format)
+	}
+}
+
+func (r *Renderer) redact(v any) any {
+	one := func(v any) any {
+		if redactor, ok := v.(Redactor); ok {
+			v = redactor.Redacted()
+		}
+		if r.Redact != nil {
+			v = r.Redact(v)
+		}
+		return v
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return one(v)
+	}
+	items := make([]any, rv.Len())
+	for i := range items {
+		items[i] = one(rv.Index(i).Interface())
+	}
+	return items
+}
+
+func renderJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// renderYAML goes through JSON so the json struct tags of API models are used for field names.
+func renderYAML(w io.Writer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var generic any
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return err
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(generic); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// template returns the parsed template, templates are cached so rendering again, like in watch mode, doesn't parse them.
+func (r *Renderer) template(name, text string) (*template.Template, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := templateKey{name: name, text: text}
+	if t, ok := r.templates[key]; ok {
+		return t, nil
+	}
+	t, err := template.New(name).Funcs(r.funcs()).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if r.templates == nil {
+		r.templates = map[templateKey]*template.Template{}
+	}
+	r.templates[key] = t
+	return t, nil
+}
+
+func (r *Renderer) renderTemplate(w io.Writer, v any) error {
+	t, err := r.template("output", r.Argument)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, v)
+}
+
+func (r *Renderer) renderTable(w io.Writer, v any) error {
+	rows, ok := v.([]any)
+	if !ok {
+		rows = []any{v}
+	}
+
+	columns := r.Columns
+	if len(columns) == 0 && len(rows) > 0 {
+		columns = defaultColumns(rows[0])
+	}
+	if len(columns) > 0 {
+		var err error
+		if columns, err = selectColumns(columns, r.ColumnNames); err != nil {
+			return err
+		}
+	}
+	if len(columns) == 0 {
+		for _, row := range rows {
+			if _, err := fmt.Fprintln(w, row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	headers := make([]string, len(columns))
+	templates := make([]*template.Template, len(columns))
+	for i, c := range columns {
+		headers[i] = c.Header
+		t, err := r.template(c.Header, c.Template)
+		if err != nil {
+			return err
+		}
+		templates[i] = t
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 3, ' ', 0)
+	if _, err := fmt.Fprintln(tw, strings.Join(headers, "\t")); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		cells := make([]string, len(templates))
+		for i, t := range templates {
+			var cell strings.Builder
+			if err := t.Execute(&cell, row); err != nil {
+				return err
+			}
+			cells[i] = cell.String()
+		}
+		if _, err := fmt.Fprintln(tw, strings.Join(cells, "\t")); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// defaultColumns returns one column per exported field when v is a struct, timestamps use formatTime.
+func defaultColumns(v any) []Column {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var columns []Column
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		tmpl := fmt.Sprintf("{{.%s}}", f.Name)
+		if f.Type == reflect.TypeOf(time.Time{}) {
+			tmpl = fmt.Sprintf("{{formatTime .%s}}", f.Name)
+		}
+		columns = append(columns, Column{
+			Header:   strings.ToUpper(f.Name),
+			Template: tmpl,
+		})
+	}
+	return columns
+}
@@ -0,0 +1,120 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type apiKey struct {
+	ID         string `json:"id"`
+	PublicKey  string `json:"publicKey"`
+	PrivateKey string `json:"privateKey,omitempty"`
+}
+
+func (k apiKey) Redacted() any {
+	if k.PrivateKey != "" {
+		k.PrivateKey = "redacted"
+	}
+	return k
+}
+
+var keys = []apiKey{
+	{ID: "1", PublicKey: "abc", PrivateKey: "secret"},
+	{ID: "2", PublicKey: "defgh"},
+}
+
+func TestRenderer_Render(t *testing.T) {
+	tests := []struct {
+		name     string
+		renderer *Renderer
+		want     string
+	}{
+		{
+			name:     "json",
+			renderer: &Renderer{Format: config.JSONOutput},
+			want: `[
+  {
+    "id": "1",
+    "publicKey": "abc",
+    "privateKey": "redacted"
+  },
+  {
+    "id": "2",
+    "publicKey": "defgh"
+  }
+]
+`,
+		},
+		{
+			name:     "yaml",
+			renderer: &Renderer{Format: config.YAMLOutput},
+			want: `- id: "1"
+  privateKey: redacted
+  publicKey: abc
+- id: "2"
+  publicKey: defgh
+`,
+		},
+		{
+			name:     "go-template",
+			renderer: &Renderer{Format: config.GoTemplateOutput, Argument: "{{range .}}{{.PublicKey}} {{end}}"},
+			want:     "abc defgh ",
+		},
+		{
+			name: "plaintext with columns",
+			renderer: &Renderer{Format: config.PlaintextOutput, Columns: []Column{
+				{Header: "ID", Template: "{{.ID}}"},
+				{Header: "PUBLIC KEY", Template: "{{.PublicKey}}"},
+			}},
+			want: "ID   PUBLIC KEY\n1    abc\n2    defgh\n",
+		},
+		{
+			name:     "plaintext with default columns",
+			renderer: &Renderer{Format: config.PlaintextOutput},
+			want:     "ID   PUBLICKEY   PRIVATEKEY\n1    abc         redacted\n2    defgh       \n",
+		},
+		{
+			name: "redact hook",
+			renderer: &Renderer{Format: config.GoTemplateOutput, Argument: "{{range .}}{{.}} {{end}}", Redact: func(v any) any {
+				return v.(apiKey).ID
+			}},
+			want: "1 2 ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			require.NoError(t, tt.renderer.Render(buf, keys))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestRenderer_Render_single(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := &Renderer{Format: config.PlaintextOutput, Columns: []Column{{Header: "ID", Template: "{{.ID}}"}}}
+	require.NoError(t, r.Render(buf, &keys[0]))
+	assert.Equal(t, "ID\n1\n", buf.String())
+
+	require.ErrorContains(t, r.Render(buf, "not a struct"), "can't evaluate field ID")
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mongodb/atlas-cli-core/config"
+)
+
+const (
+	localLayout   = "2006-01-02 15:04:05 MST"
+	localLayoutUS = "Jan 2, 2006 3:04:05 PM MST" // localLayoutUS is used for the en-US locale
+)
+
+// FormatTime prints t in the given format, the locale picks the layout of local times.
+func FormatTime(t time.Time, format config.TimeFormat, locale string) string {
+	return formatTime(t, time.Now(), format, locale)
+}
+
+func formatTime(t, now time.Time, format config.TimeFormat, locale string) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	switch format {
+	case config.LocalTime:
+		if strings.EqualFold(locale, "en-US") {
+			return t.Local().Format(localLayoutUS)
+		}
+		return t.Local().Format(localLayout)
+	case config.RelativeTime:
+		return relativeTime(t, now)
+	default:
+		return t.UTC().Format(time.RFC3339)
+	}
+}
+
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	var amount string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		amount = plural(int(d/time.Minute), "minute")
+	case d < 24*time.Hour:
+		amount = plural(int(d/time.Hour), "hour")
+	case d < 30*24*time.Hour:
+		amount = plural(int(d/(24*time.Hour)), "day")
+	case d < 365*24*time.Hour:
+		amount = plural(int(d/(30*24*time.Hour)), "month")
+	default:
+		amount = plural(int(d/(365*24*time.Hour)), "year")
+	}
+
+	if future {
+		return "in " + amount
+	}
+	return amount + " ago"
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
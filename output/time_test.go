@@ -0,0 +1,62 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_formatTime(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	loc := time.FixedZone("EST", -5*60*60)
+	ts := time.Date(2024, 6, 15, 9, 30, 0, 0, loc) // 14:30 UTC
+
+	assert.Equal(t, "2024-06-15T14:30:00Z", formatTime(ts, now, config.RFC3339Time, ""))
+	assert.Equal(t, "2024-06-15T14:30:00Z", formatTime(ts, now, "", ""))
+	assert.Equal(t, ts.Local().Format(localLayout), formatTime(ts, now, config.LocalTime, "fr-FR"))
+	assert.Equal(t, ts.Local().Format(localLayoutUS), formatTime(ts, now, config.LocalTime, "en-US"))
+	assert.Equal(t, "", formatTime(time.Time{}, now, config.RFC3339Time, ""))
+
+	tests := map[time.Duration]string{
+		-10 * time.Second:     "just now",
+		-time.Minute:          "1 minute ago",
+		-3 * time.Hour:        "3 hours ago",
+		2 * 24 * time.Hour:    "in 2 days",
+		-65 * 24 * time.Hour:  "2 months ago",
+		-800 * 24 * time.Hour: "2 years ago",
+	}
+	for d, want := range tests {
+		assert.Equal(t, want, formatTime(now.Add(d), now, config.RelativeTime, ""))
+	}
+}
+
+func TestRenderer_Render_time(t *testing.T) {
+	type event struct {
+		ID      string
+		Created time.Time
+	}
+	buf := &bytes.Buffer{}
+	r := &Renderer{Format: config.PlaintextOutput, TimeFormat: config.RFC3339Time}
+	require.NoError(t, r.Render(buf, event{ID: "1", Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}))
+	assert.Equal(t, "ID   CREATED\n1    2024-01-02T03:04:05Z\n", buf.String())
+}
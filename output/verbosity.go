@@ -0,0 +1,41 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mongodb/atlas-cli-core/config"
+)
+
+// Messagef prints a message for the user, like "Cluster created.", unless the verbosity is quiet.
+// Use it for anything that isn't the result of the command, scripts parsing the output use -q to skip messages.
+func Messagef(w io.Writer, format string, args ...any) error {
+	return printAt(w, config.VerbosityNormal, format, args...)
+}
+
+// Verbosef prints details about the progress of a command when the verbosity is verbose or debug.
+func Verbosef(w io.Writer, format string, args ...any) error {
+	return printAt(w, config.VerbosityVerbose, format, args...)
+}
+
+func printAt(w io.Writer, level config.VerbosityLevel, format string, args ...any) error {
+	if config.Verbosity() < level {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, format+"\n", args...)
+	return err
+}
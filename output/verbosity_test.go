@@ -0,0 +1,48 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessagef(t *testing.T) {
+	t.Cleanup(func() { config.SetVerbosity(config.VerbosityNormal) })
+	tests := []struct {
+		verbosity config.VerbosityLevel
+		want      string
+	}{
+		{verbosity: config.VerbosityQuiet, want: ""},
+		{verbosity: config.VerbosityNormal, want: "Cluster created.\n"},
+		{verbosity: config.VerbosityVerbose, want: "Cluster created.\nWaiting for Cluster0.\n"},
+		{verbosity: config.VerbosityDebug, want: "Cluster created.\nWaiting for Cluster0.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.verbosity.String(), func(t *testing.T) {
+			config.SetVerbosity(tt.verbosity)
+			buf := &bytes.Buffer{}
+			require.NoError(t, Messagef(buf, "Cluster created."))
+			require.NoError(t, Verbosef(buf, "Waiting for %s.", "Cluster0"))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
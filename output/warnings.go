@@ -0,0 +1,69 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/mongodb/atlas-cli-core/config"
+)
+
+// PrintWarnings writes the warnings as text, one per line, usually to stderr after the command output.
+func PrintWarnings(w io.Writer, warnings []config.Warning) error {
+	for _, warning := range warnings {
+		if _, err := fmt.Fprintf(w, "Warning: %s\n", warning.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenderWithWarnings writes v to w like Render and reports the warnings, like the ones of config.DrainWarnings.
+// JSON and YAML output get a warnings array: objects get a warnings field, other values are wrapped
+// in an object with result and warnings fields. Other formats print the warnings to stderr as text.
+func (r *Renderer) RenderWithWarnings(w, stderr io.Writer, v any, warnings []config.Warning) error {
+	if len(warnings) == 0 {
+		return r.Render(w, v)
+	}
+	if r.Format != config.JSONOutput && r.Format != config.YAMLOutput {
+		if err := r.Render(w, v); err != nil {
+			return err
+		}
+		return PrintWarnings(stderr, warnings)
+	}
+	v, err := withWarnings(r.redact(v), warnings)
+	if err != nil {
+		return err
+	}
+	if r.Format == config.JSONOutput {
+		return renderJSON(w, v)
+	}
+	return renderYAML(w, v)
+}
+
+func withWarnings(v any, warnings []config.Warning) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]any
+	if err := json.Unmarshal(b, &object); err != nil || object == nil {
+		return map[string]any{"result": v, "warnings": warnings}, nil
+	}
+	object["warnings"] = warnings
+	return object, nil
+}
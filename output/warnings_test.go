@@ -0,0 +1,63 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testWarnings = []config.Warning{{Kind: config.WarningDeprecation, Message: "the MCLI_ environment variables are deprecated"}}
+
+func TestRenderer_RenderWithWarnings(t *testing.T) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	r := &Renderer{Format: config.JSONOutput}
+	require.NoError(t, r.RenderWithWarnings(stdout, stderr, keys[0], testWarnings))
+	assert.JSONEq(t, `{
+  "id": "1",
+  "publicKey": "abc",
+  "privateKey": "redacted",
+  "warnings": [{"kind": "deprecation", "message": "the MCLI_ environment variables are deprecated"}]
+}`, stdout.String())
+	assert.Empty(t, stderr.String())
+
+	stdout.Reset()
+	require.NoError(t, r.RenderWithWarnings(stdout, stderr, keys, testWarnings))
+	assert.Contains(t, stdout.String(), `"result": [`)
+	assert.Contains(t, stdout.String(), `"warnings": [`)
+	assert.NotContains(t, stdout.String(), "secret")
+
+	stdout.Reset()
+	r = &Renderer{Format: config.YAMLOutput}
+	require.NoError(t, r.RenderWithWarnings(stdout, stderr, keys[1], testWarnings))
+	assert.Equal(t, "id: \"2\"\npublicKey: defgh\nwarnings:\n  - kind: deprecation\n    message: the MCLI_ environment variables are deprecated\n", stdout.String())
+
+	stdout.Reset()
+	r = &Renderer{Format: config.PlaintextOutput, Columns: []Column{{Header: "ID", Template: "{{.ID}}"}}}
+	require.NoError(t, r.RenderWithWarnings(stdout, stderr, keys[1], testWarnings))
+	assert.Equal(t, "ID\n2\n", stdout.String())
+	assert.Equal(t, "Warning: the MCLI_ environment variables are deprecated\n", stderr.String())
+
+	stdout.Reset()
+	r = &Renderer{Format: config.JSONOutput}
+	require.NoError(t, r.RenderWithWarnings(stdout, stderr, keys[1], nil))
+	assert.NotContains(t, stdout.String(), "warnings", "the output doesn't change without warnings")
+}
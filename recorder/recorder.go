@@ -0,0 +1,337 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package recorder records the HTTP requests of a client to a fixture file and replays them in tests,
+// see config.SetTransportWrapper. Credentials are scrubbed before they are written to the fixture.
+package recorder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/mongodb/atlas-cli-core/config"
+)
+
+const (
+	RecordEnv = "ATLAS_CLI_CORE_RECORD" // RecordEnv makes ForTest record the requests instead of replaying them when set
+	Redacted  = "REDACTED"              // Redacted replaces the scrubbed values
+)
+
+// Mode is whether a Recorder sends requests or answers them from its fixture.
+type Mode int
+
+const (
+	ModeReplay Mode = iota // ModeReplay answers requests from the fixture, requests not in it fail
+	ModeRecord             // ModeRecord sends requests and writes them to the fixture on Save
+)
+
+var ErrInteractionNotFound = errors.New("no recorded interaction matches the request")
+
+// MissingInteractionError is returned in ModeReplay for requests that are not in the fixture, or were already replayed.
+type MissingInteractionError struct {
+	Method string
+	URL    string
+}
+
+func (e *MissingInteractionError) Error() string {
+	return fmt.Sprintf("%v: %s %s", ErrInteractionNotFound, e.Method, e.URL)
+}
+
+func (*MissingInteractionError) Unwrap() error {
+	return ErrInteractionNotFound
+}
+
+// Interaction is a request and its response, as written in the fixture.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body,omitempty"`
+}
+
+// Body is written as text, or as base64 when it's not valid UTF-8.
+type Body []byte
+
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(b)})
+}
+
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = Body(s)
+		return nil
+	}
+	var encoded struct {
+		Base64 string `json:"base64"`
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded.Base64)
+	*b = decoded
+	return err
+}
+
+type fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper recording or replaying requests, see Wrap.
+type Recorder struct {
+	path     string
+	mode     Mode
+	base     http.RoundTripper
+	scrubber scrubber
+
+	mu           sync.Mutex
+	interactions []Interaction
+	replayed     []bool
+}
+
+var _ config.TransportWrapper = (*Recorder)(nil).Wrap
+
+type Option func(*Recorder)
+
+// WithSecrets replaces the values anywhere in the requests and responses, like the API keys of the profile used to record.
+func WithSecrets(values ...string) Option {
+	return func(r *Recorder) {
+		for _, v := range values {
+			if v != "" {
+				r.scrubber.secrets = append(r.scrubber.secrets, v)
+			}
+		}
+	}
+}
+
+// WithScrubbedFields scrubs more JSON, form and query fields than the credentials scrubbed by default.
+func WithScrubbedFields(names ...string) Option {
+	return func(r *Recorder) {
+		for _, name := range names {
+			r.scrubber.fields[normalizeField(name)] = true
+		}
+	}
+}
+
+// WithScrubbedHeaders scrubs more headers than Authorization, Proxy-Authorization, Cookie and Set-Cookie.
+func WithScrubbedHeaders(names ...string) Option {
+	return func(r *Recorder) {
+		for _, name := range names {
+			r.scrubber.headers[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+}
+
+// New returns a Recorder for the fixture at path. In ModeReplay the fixture is read and must exist.
+func New(path string, mode Mode, opts ...Option) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode, base: http.DefaultTransport, scrubber: newScrubber()}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if mode != ModeReplay {
+		return r, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f fixture
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	r.interactions = f.Interactions
+	r.replayed = make([]bool, len(f.Interactions))
+	return r, nil
+}
+
+// TestingT is the part of testing.TB used by ForTest, so the package doesn't import testing.
+type TestingT interface {
+	Helper()
+	Fatalf(format string, args ...any)
+	Errorf(format string, args ...any)
+	Cleanup(func())
+}
+
+// ForTest returns a Recorder replaying the fixture at path, or recording it when RecordEnv is set,
+// in which case the fixture is saved when the test ends.
+func ForTest(t TestingT, path string, opts ...Option) *Recorder {
+	t.Helper()
+	mode := ModeReplay
+	if os.Getenv(RecordEnv) != "" {
+		mode = ModeRecord
+	}
+	r, err := New(path, mode, opts...)
+	if err != nil {
+		t.Fatalf("recorder: %v, set %s to record the fixture", err, RecordEnv)
+	}
+	t.Cleanup(func() {
+		if err := r.Save(); err != nil {
+			t.Errorf("recorder: %v", err)
+		}
+	})
+	return r
+}
+
+// Wrap returns the recorder sending the requests it records with base, use it with config.SetTransportWrapper.
+// In ModeReplay base is never used.
+func (r *Recorder) Wrap(base http.RoundTripper) http.RoundTripper {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.base = base
+	return r
+}
+
+// Mode returns whether the recorder records or replays.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	recorded := r.scrubber.request(req, body)
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	r.mu.Lock()
+	base := r.base
+	r.mu.Unlock()
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, Interaction{Request: recorded, Response: r.scrubber.response(resp, respBody)})
+	return resp, nil
+}
+
+// CloseIdleConnections closes the idle connections of the base transport.
+func (r *Recorder) CloseIdleConnections() {
+	if c, ok := r.base.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
+
+// replay returns the response of the first interaction matching the request that wasn't replayed yet.
+func (r *Recorder) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.interactions {
+		if r.replayed[i] || !matches(interaction.Request, recorded) {
+			continue
+		}
+		r.replayed[i] = true
+		header := interaction.Response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		header.Del("Content-Length")
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, &MissingInteractionError{Method: recorded.Method, URL: recorded.URL}
+}
+
+func matches(recorded, req Request) bool {
+	return recorded.Method == req.Method && recorded.URL == req.URL && bytes.Equal(recorded.Body, req.Body)
+}
+
+// Save writes the recorded interactions to the fixture, it does nothing in ModeReplay.
+func (r *Recorder) Save() error {
+	if r.mode == ModeReplay {
+		return nil
+	}
+	r.mu.Lock()
+	b, err := json.MarshalIndent(fixture{Interactions: r.interactions}, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(b, '\n'), 0o600)
+}
+
+// readRequestBody reads the body of the request and returns a copy of the request to send with it,
+// the request isn't modified. A fresh body from GetBody is read when it's set.
+func readRequestBody(req *http.Request) (*http.Request, []byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil, nil
+	}
+	body := req.Body
+	if req.GetBody != nil {
+		req.Body.Close()
+		var err error
+		if body, err = req.GetBody(); err != nil {
+			return nil, nil, err
+		}
+	}
+	b, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clone := req.Clone(req.Context())
+	clone.Body = io.NopCloser(bytes.NewReader(b))
+	clone.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+	return clone, b, nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package recorder
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, c *http.Client, u string) (int, string, error) {
+	t.Helper()
+	resp, err := c.Get(u)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(b), nil
+}
+
+func TestRecorder(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret-token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"p1","refresh_token":"r","count":12345678901234567}`))
+	}))
+	path := filepath.Join(t.TempDir(), "fixtures", "projects.json")
+
+	p := config.NewEphemeralProfile(map[string]any{config.AccessTokenField: "secret-token", "service": config.CloudService})
+	r, err := New(path, ModeRecord, WithSecrets("p1"))
+	require.NoError(t, err)
+	p.SetTransportWrapper(r.Wrap)
+	status, body, err := get(t, p.HttpClient(), s.URL+"/groups?pageNum=1&access_token=secret")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"refresh_token":"r"`, "the client gets the real response")
+	require.NoError(t, r.Save())
+	s.Close()
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	fixture := string(b)
+	assert.NotContains(t, fixture, "secret")
+	assert.NotContains(t, fixture, `"r"`)
+	assert.NotContains(t, fixture, "p1")
+	assert.Contains(t, fixture, "12345678901234567", "numbers keep their precision")
+
+	r, err = New(path, ModeReplay)
+	require.NoError(t, err)
+	p.SetTransportWrapper(r.Wrap)
+	replayURL := s.URL + "/groups?pageNum=1&access_token=" + url.QueryEscape("other")
+	status, body, err = get(t, p.HttpClient(), replayURL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.True(t, strings.HasPrefix(body, `{"count":12345678901234567,"id":"REDACTED"`), body)
+
+	_, _, err = get(t, p.HttpClient(), replayURL)
+	require.ErrorIs(t, err, ErrInteractionNotFound, "each interaction is replayed once")
+}
+
+func TestRecorder_FormBody(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "refresh", r.PostForm.Get("refresh_token"))
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer s.Close()
+
+	r, err := New(filepath.Join(t.TempDir(), "token.json"), ModeRecord)
+	require.NoError(t, err)
+	c := &http.Client{Transport: r.Wrap(http.DefaultTransport)}
+	resp, err := c.PostForm(s.URL, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {"refresh"}})
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Len(t, r.interactions, 1)
+	assert.Equal(t, "grant_type=refresh_token&refresh_token=REDACTED", string(r.interactions[0].Request.Body))
+}
+
+func TestRecorder_requestNotModified(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "body", string(b))
+	}))
+	defer s.Close()
+
+	r, err := New(filepath.Join(t.TempDir(), "body.json"), ModeRecord)
+	require.NoError(t, err)
+	r.Wrap(http.DefaultTransport)
+	body := io.NopCloser(strings.NewReader("body"))
+	req, err := http.NewRequest(http.MethodPost, s.URL, body)
+	require.NoError(t, err)
+	resp, err := r.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, body, req.Body, "the body of the caller's request isn't replaced")
+	require.Len(t, r.interactions, 1)
+	assert.Equal(t, "body", string(r.interactions[0].Request.Body))
+}
+
+func TestBody(t *testing.T) {
+	b, err := Body{0xff, 0x00}.MarshalJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `{"base64":"/wA="}`, string(b))
+
+	var got Body
+	require.NoError(t, got.UnmarshalJSON(b))
+	assert.Equal(t, Body{0xff, 0x00}, got)
+}
+
+func TestForTest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.json")
+	t.Setenv(RecordEnv, "1")
+	r := ForTest(t, path)
+	assert.Equal(t, ModeRecord, r.Mode())
+}
@@ -0,0 +1,159 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// defaultScrubbedFields are the JSON, form and query fields holding credentials, see normalizeField.
+var defaultScrubbedFields = []string{
+	"access_token",
+	"refresh_token",
+	"id_token",
+	"subject_token",
+	"device_code",
+	"code_verifier",
+	"client_secret",
+	"password",
+	"private_api_key",
+	"private_key",
+}
+
+var defaultScrubbedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// normalizeField makes field names like accessToken, access_token and access-token the same.
+func normalizeField(name string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+}
+
+type scrubber struct {
+	fields  map[string]bool
+	headers map[string]bool
+	secrets []string
+}
+
+func newScrubber() scrubber {
+	s := scrubber{fields: map[string]bool{}, headers: map[string]bool{}}
+	for _, f := range defaultScrubbedFields {
+		s.fields[normalizeField(f)] = true
+	}
+	for _, h := range defaultScrubbedHeaders {
+		s.headers[h] = true
+	}
+	return s
+}
+
+func (s scrubber) request(req *http.Request, body []byte) Request {
+	u := *req.URL
+	u.User = nil
+	if q := u.Query(); len(q) > 0 {
+		s.scrubValues(q)
+		u.RawQuery = q.Encode()
+	}
+	return Request{
+		Method: req.Method,
+		URL:    s.text(u.String()),
+		Header: s.header(req.Header),
+		Body:   s.body(req.Header, body),
+	}
+}
+
+func (s scrubber) response(resp *http.Response, body []byte) Response {
+	return Response{
+		StatusCode: resp.StatusCode,
+		Header:     s.header(resp.Header),
+		Body:       s.body(resp.Header, body),
+	}
+}
+
+func (s scrubber) header(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	scrubbed := make(http.Header, len(h))
+	for k, values := range h {
+		for _, v := range values {
+			if s.headers[http.CanonicalHeaderKey(k)] {
+				v = Redacted
+			}
+			scrubbed[k] = append(scrubbed[k], s.text(v))
+		}
+	}
+	return scrubbed
+}
+
+// body scrubs the credential fields of JSON and form bodies, and the secrets of any text body.
+func (s scrubber) body(h http.Header, body []byte) Body {
+	if len(body) == 0 {
+		return nil
+	}
+	if mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type")); mediaType == "application/x-www-form-urlencoded" {
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			s.scrubValues(form)
+			return Body(s.text(form.Encode()))
+		}
+	}
+	var v any
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	if d.Decode(&v) == nil && !d.More() {
+		if b, err := json.Marshal(s.json(v)); err == nil {
+			return Body(s.text(string(b)))
+		}
+	}
+	return Body(s.text(string(body)))
+}
+
+func (s scrubber) json(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			if _, ok := e.(string); ok && s.fields[normalizeField(k)] {
+				v[k] = Redacted
+			} else {
+				v[k] = s.json(e)
+			}
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = s.json(e)
+		}
+	}
+	return v
+}
+
+func (s scrubber) scrubValues(values url.Values) {
+	for k, vs := range values {
+		if s.fields[normalizeField(k)] {
+			for i := range vs {
+				vs[i] = Redacted
+			}
+		}
+	}
+}
+
+// text replaces the secrets set with WithSecrets.
+func (s scrubber) text(v string) string {
+	for _, secret := range s.secrets {
+		v = strings.ReplaceAll(v, secret, Redacted)
+	}
+	return v
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/mongodb/atlas-cli-core/config"
+)
+
+// FlushDeadline is the longest Flush waits for the events to be sent, so telemetry never delays the end of a command
+// noticeably. The batches that aren't sent by then are dropped.
+const FlushDeadline = 500 * time.Millisecond
+
+// Sender sends a batch of events, like posting them to the telemetry endpoint. It should return when ctx is done.
+type Sender interface {
+	Send(ctx context.Context, events []CommandEvent) error
+}
+
+// SenderFunc is a function implementing Sender.
+type SenderFunc func(ctx context.Context, events []CommandEvent) error
+
+func (f SenderFunc) Send(ctx context.Context, events []CommandEvent) error { return f(ctx, events) }
+
+// Batcher samples events and sends them in batches, in the background.
+// A batch is sent when it's full or when its first event waited for the flush interval, call Flush before exiting.
+type Batcher struct {
+	profile    *config.Profile
+	sender     Sender
+	enabled    bool
+	sampleRate float64
+	batchSize  int
+	interval   time.Duration
+	random     func() float64
+
+	ctx     context.Context // ctx of the sends, canceled when Flush reaches its deadline
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+	pending []CommandEvent
+	timer   *time.Timer
+	sending sync.WaitGroup
+	errs    []error
+}
+
+// NewBatcher returns a Batcher using the telemetry settings of the profile, it drops every event
+// when telemetry is disabled, see config.TelemetryPolicy.
+func NewBatcher(p *config.Profile, s Sender) *Batcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Batcher{
+		profile:    p,
+		sender:     s,
+		enabled:    p.TelemetryPolicy().Enabled,
+		sampleRate: p.TelemetrySampleRate(),
+		batchSize:  p.TelemetryBatchSize(),
+		interval:   p.TelemetryFlushInterval(),
+		random:     rand.Float64,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Add queues an event if it's sampled, sending the batch when it's full.
+// The event is also kept in the telemetry spool of the profile, see UsageSummary.
+func (b *Batcher) Add(e CommandEvent) {
+	if !b.enabled || b.random() >= b.sampleRate {
+		return
+	}
+	e.SampleRate = b.sampleRate
+	err := b.profile.AppendTelemetrySpool(e.Timestamp, e)
+	if errors.Is(err, config.ErrTelemetrySpoolFull) {
+		// the event is still sent, only the local copy is dropped
+		b.profile.Logger().Debug("telemetry event not spooled", "error", err)
+	} else if err != nil {
+		b.profile.Logger().Warn("telemetry event can't be spooled", "error", err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = append(b.pending, e)
+	if len(b.pending) >= b.batchSize {
+		b.sendPending()
+		return
+	}
+	if b.timer == nil && b.interval > 0 {
+		b.timer = time.AfterFunc(b.interval, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.sendPending()
+		})
+	}
+}
+
+// sendPending sends the pending events in the background, b.mu must be held.
+func (b *Batcher) sendPending() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if len(b.pending) == 0 {
+		return
+	}
+	batch := b.pending
+	b.pending = nil
+	b.sending.Add(1)
+	go func() {
+		defer b.sending.Done()
+		if err := b.sender.Send(b.ctx, batch); err != nil {
+			b.mu.Lock()
+			b.errs = append(b.errs, err)
+			b.mu.Unlock()
+		}
+	}()
+}
+
+// Flush sends the pending events and waits for the batches being sent, for FlushDeadline at most,
+// then cancels the sends left. It returns the errors of the sends since the last Flush.
+func (b *Batcher) Flush(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, FlushDeadline)
+	defer cancel()
+
+	b.mu.Lock()
+	b.sendPending()
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.sending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := errors.Join(b.errs...)
+	b.errs = nil
+	return err
+}
@@ -0,0 +1,144 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSender struct {
+	mu      sync.Mutex
+	batches [][]CommandEvent
+	err     error
+}
+
+func (s *recordingSender) Send(_ context.Context, events []CommandEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, events)
+	return s.err
+}
+
+func (s *recordingSender) sent() [][]CommandEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.batches
+}
+
+func newTestBatcher(t *testing.T, settings map[string]any, s Sender) *Batcher {
+	t.Helper()
+	t.Setenv(config.DoNotTrackEnv, "")
+	return NewBatcher(config.NewEphemeralProfile(settings), s)
+}
+
+func TestBatcher_batches(t *testing.T) {
+	s := &recordingSender{}
+	b := newTestBatcher(t, map[string]any{"telemetry_batch_size": 2, "telemetry_flush_interval": "1h"}, s)
+
+	b.Add(CommandEvent{Command: "a"})
+	b.Add(CommandEvent{Command: "b"})
+	b.Add(CommandEvent{Command: "c"})
+	require.NoError(t, b.Flush(context.Background()))
+
+	var sizes []int
+	var commands []string
+	for _, batch := range s.sent() {
+		sizes = append(sizes, len(batch))
+		for _, e := range batch {
+			commands = append(commands, e.Command)
+			assert.InDelta(t, config.DefaultTelemetrySampleRate, e.SampleRate, 0)
+		}
+	}
+	assert.ElementsMatch(t, []int{2, 1}, sizes)
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, commands)
+
+	require.NoError(t, b.Flush(context.Background()), "nothing left to send")
+	assert.Len(t, s.sent(), 2)
+}
+
+func TestBatcher_interval(t *testing.T) {
+	s := &recordingSender{}
+	b := newTestBatcher(t, map[string]any{"telemetry_flush_interval": "10ms"}, s)
+
+	b.Add(CommandEvent{Command: "a"})
+	assert.Eventually(t, func() bool { return len(s.sent()) == 1 }, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestBatcher_sampling(t *testing.T) {
+	s := &recordingSender{}
+	b := newTestBatcher(t, map[string]any{"telemetry_sample_rate": 0.25}, s)
+	values := []float64{0.1, 0.5}
+	b.random = func() float64 {
+		v := values[0]
+		values = values[1:]
+		return v
+	}
+
+	b.Add(CommandEvent{Command: "sampled"})
+	b.Add(CommandEvent{Command: "dropped"})
+	require.NoError(t, b.Flush(context.Background()))
+
+	require.Len(t, s.sent(), 1)
+	require.Len(t, s.sent()[0], 1)
+	assert.Equal(t, "sampled", s.sent()[0][0].Command)
+	assert.InDelta(t, 0.25, s.sent()[0][0].SampleRate, 0)
+}
+
+func TestBatcher_disabled(t *testing.T) {
+	s := &recordingSender{}
+	b := newTestBatcher(t, map[string]any{"telemetry_enabled": false}, s)
+
+	b.Add(CommandEvent{Command: "a"})
+	require.NoError(t, b.Flush(context.Background()))
+	assert.Empty(t, s.sent())
+}
+
+func TestBatcher_Flush(t *testing.T) {
+	canceled := make(chan struct{})
+	b := newTestBatcher(t, nil, SenderFunc(func(ctx context.Context, _ []CommandEvent) error {
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	}))
+
+	b.Add(CommandEvent{Command: "a"})
+	start := time.Now()
+	require.ErrorIs(t, b.Flush(context.Background()), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), FlushDeadline+time.Second, "telemetry doesn't delay the command")
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the send wasn't canceled")
+	}
+}
+
+func TestBatcher_FlushErrors(t *testing.T) {
+	errSend := errors.New("telemetry endpoint unavailable")
+	b := newTestBatcher(t, nil, &recordingSender{err: errSend})
+
+	b.Add(CommandEvent{Command: "a"})
+	require.ErrorIs(t, b.Flush(context.Background()), errSend)
+	require.NoError(t, b.Flush(context.Background()))
+}
@@ -0,0 +1,75 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/mongodb/atlas-cli-core/config"
+)
+
+// RecoverCrash saves a crash report when the command panics and crash reports are enabled, then panics again
+// so the CLI still crashes. Defer it first in main:
+//
+//	defer telemetry.RecoverCrash(profile, version, strings.Join(os.Args[1:2], " "))
+func RecoverCrash(p *config.Profile, version, command string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if p.CrashReports() {
+		_ = p.SaveCrashReport(p.NewCrashReport(r, debug.Stack(), version, command))
+	}
+	panic(r)
+}
+
+// SubmitCrashReports sends the saved crash reports as telemetry events and deletes them, call it on the next run.
+// Nothing is sent when crash reports or telemetry are disabled.
+func SubmitCrashReports(ctx context.Context, p *config.Profile, s Sender) error {
+	if !p.CrashReports() || !p.TelemetryPolicy().Enabled {
+		return nil
+	}
+	reports, err := p.ListCrashReports()
+	if err != nil || len(reports) == 0 {
+		return err
+	}
+
+	events := make([]CommandEvent, len(reports))
+	ids := make([]string, len(reports))
+	for i, r := range reports {
+		ids[i] = r.ID
+		events[i] = CommandEvent{
+			Timestamp:  r.Time,
+			Command:    r.Command,
+			Result:     ResultCrash,
+			ErrorClass: ErrorInternal,
+			Service:    string(r.Config.Service),
+			OS:         r.OS,
+			Arch:       r.Arch,
+			Properties: map[string]any{
+				"crash_id":   r.ID,
+				"version":    r.Version,
+				"go_version": r.GoVersion,
+				"panic":      r.Panic,
+				"stack":      r.Stack,
+			},
+		}
+	}
+	if err := s.Send(ctx, events); err != nil {
+		return err
+	}
+	return p.DeleteCrashReports(ids...)
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func crash(p *config.Profile) {
+	defer RecoverCrash(p, "1.2.3", "clusters list")
+	panic("token secret-token expired")
+}
+
+func TestRecoverCrash(t *testing.T) {
+	p := config.NewEphemeralProfile(map[string]any{"crash_reports": true, "access_token": "secret-token"})
+	require.PanicsWithValue(t, "token secret-token expired", func() { crash(p) }, "the CLI still crashes")
+
+	reports, err := p.ListCrashReports()
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, "1.2.3", reports[0].Version)
+	assert.Equal(t, "clusters list", reports[0].Command)
+	assert.Equal(t, "token redacted expired", reports[0].Panic)
+	assert.Contains(t, reports[0].Stack, "telemetry.crash")
+
+	p = config.NewEphemeralProfile(map[string]any{})
+	require.Panics(t, func() { crash(p) })
+	reports, err = p.ListCrashReports()
+	require.NoError(t, err)
+	assert.Empty(t, reports, "crash reports are opt-in")
+}
+
+func TestSubmitCrashReports(t *testing.T) {
+	t.Setenv(config.DoNotTrackEnv, "")
+	p := config.NewEphemeralProfile(map[string]any{"crash_reports": true})
+	require.NoError(t, p.SaveCrashReport(p.NewCrashReport("boom", []byte("stack"), "1.2.3", "clusters list")))
+
+	errSend := errors.New("telemetry endpoint unavailable")
+	require.ErrorIs(t, SubmitCrashReports(context.Background(), p, &recordingSender{err: errSend}), errSend)
+	reports, err := p.ListCrashReports()
+	require.NoError(t, err)
+	require.Len(t, reports, 1, "reports are kept until they are sent")
+
+	s := &recordingSender{}
+	require.NoError(t, SubmitCrashReports(context.Background(), p, s))
+	require.Len(t, s.sent(), 1)
+	e := s.sent()[0][0]
+	assert.Equal(t, ResultCrash, e.Result)
+	assert.Equal(t, "clusters list", e.Command)
+	assert.Equal(t, "boom", e.Properties["panic"])
+	assert.Equal(t, reports[0].ID, e.Properties["crash_id"])
+
+	reports, err = p.ListCrashReports()
+	require.NoError(t, err)
+	assert.Empty(t, reports)
+	require.NoError(t, SubmitCrashReports(context.Background(), p, s))
+	assert.Len(t, s.sent(), 1, "nothing left to send")
+}
+
+func TestSubmitCrashReports_telemetryDisabled(t *testing.T) {
+	p := config.NewEphemeralProfile(map[string]any{"crash_reports": true, "telemetry_enabled": false})
+	require.NoError(t, p.SaveCrashReport(p.NewCrashReport("boom", nil, "", "")))
+
+	s := &recordingSender{}
+	require.NoError(t, SubmitCrashReports(context.Background(), p, s))
+	assert.Empty(t, s.sent())
+}
@@ -0,0 +1,153 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/mongodb/atlas-cli-core/auth"
+	"github.com/mongodb/atlas-cli-core/config"
+)
+
+// ErrorClass is the category of the error of a command, reported instead of the error message,
+// which can contain names and IDs.
+type ErrorClass string
+
+const (
+	NoError        ErrorClass = ""           // NoError the command succeeded
+	ErrorAuth      ErrorClass = "auth"       // ErrorAuth missing or invalid credentials, 401 and 403 responses
+	ErrorNetwork   ErrorClass = "network"    // ErrorNetwork the API couldn't be reached, like DNS errors, timeouts or offline mode
+	ErrorAPIClient ErrorClass = "api_4xx"    // ErrorAPIClient the API refused the request, like 404 Not Found
+	ErrorAPIServer ErrorClass = "api_5xx"    // ErrorAPIServer the API failed to handle the request
+	ErrorUserInput ErrorClass = "user_input" // ErrorUserInput invalid flags, arguments or settings, see UserInput
+	ErrorCanceled  ErrorClass = "canceled"   // ErrorCanceled the user interrupted the command
+	ErrorInternal  ErrorClass = "internal"   // ErrorInternal any other error
+)
+
+// StatusCoder is implemented by the errors of API responses, to classify them by HTTP status.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// userInputError marks an error caused by the user, see UserInput.
+type userInputError struct {
+	err error
+}
+
+func (e *userInputError) Error() string { return e.err.Error() }
+func (e *userInputError) Unwrap() error { return e.err }
+
+// UserInput marks an error as caused by invalid user input, like a missing flag, so it's classified as ErrorUserInput.
+func UserInput(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &userInputError{err: err}
+}
+
+// userInputErrors are the errors of the config package caused by invalid values.
+var userInputErrors = []error{
+	config.ErrInvalidValueType,
+	config.ErrInvalidOutputFormat,
+	config.ErrMissingOutputArgument,
+	config.ErrInvalidOrgID,
+	config.ErrInvalidProjectID,
+	config.ErrInvalidBaseURL,
+	config.ErrInvalidConfig,
+	config.ErrUnknownService,
+	config.ErrProfileNotFound,
+	config.ErrProfileExists,
+	config.ErrProfileNameHasDots,
+	config.ErrProfileReadOnly,
+	config.ErrInvalidSecret,
+	config.ErrInvalidSecretReference,
+	config.ErrFlagNotFound,
+}
+
+// authErrors are the errors caused by missing or invalid credentials.
+var authErrors = []error{
+	auth.ErrTokenRequest,
+	config.ErrNoAccessToken,
+	config.ErrInvalidToken,
+	config.ErrSecretReference,
+	config.ErrProxyAuthentication,
+}
+
+// networkErrors are the errors of requests that didn't get a response.
+var networkErrors = []error{
+	config.ErrOffline,
+	config.ErrCircuitOpen,
+	config.ErrSSHJump,
+	config.ErrClientCertificate,
+	context.DeadlineExceeded,
+}
+
+// ClassifyError returns the class of the error of a command, NoError for nil.
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return NoError
+	}
+	var userInput *userInputError
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorCanceled
+	case errors.As(err, &userInput), isAny(err, userInputErrors):
+		return ErrorUserInput
+	case isAny(err, authErrors):
+		return ErrorAuth
+	}
+
+	switch status := StatusCode(err); {
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return ErrorAuth
+	case status >= http.StatusInternalServerError:
+		return ErrorAPIServer
+	case status >= http.StatusBadRequest:
+		return ErrorAPIClient
+	}
+
+	var netErr net.Error
+	var urlErr *url.Error
+	if isAny(err, networkErrors) || errors.As(err, &netErr) || errors.As(err, &urlErr) {
+		return ErrorNetwork
+	}
+	return ErrorInternal
+}
+
+// StatusCode returns the HTTP status of the API response that caused the error, 0 when there's none.
+func StatusCode(err error) int {
+	var coder StatusCoder
+	if errors.As(err, &coder) {
+		return coder.StatusCode()
+	}
+	var tokenErr *auth.TokenError
+	if errors.As(err, &tokenErr) {
+		return tokenErr.StatusCode
+	}
+	return 0
+}
+
+func isAny(err error, targets []error) bool {
+	for _, target := range targets {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
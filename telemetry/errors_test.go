@@ -0,0 +1,73 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/mongodb/atlas-cli-core/auth"
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type apiError struct {
+	status int
+}
+
+func (e *apiError) Error() string   { return fmt.Sprintf("HTTP %d", e.status) }
+func (e *apiError) StatusCode() int { return e.status }
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		want   ErrorClass
+		status int
+	}{
+		{name: "nil", err: nil, want: NoError},
+		{name: "canceled", err: fmt.Errorf("waiting: %w", context.Canceled), want: ErrorCanceled},
+		{name: "user input", err: UserInput(errors.New("missing --name")), want: ErrorUserInput},
+		{name: "invalid setting", err: fmt.Errorf("%w: %q", config.ErrInvalidProjectID, "x"), want: ErrorUserInput},
+		{name: "no credentials", err: config.ErrNoAccessToken, want: ErrorAuth},
+		{name: "token error", err: &auth.TokenError{StatusCode: http.StatusBadRequest, Code: "invalid_grant"}, want: ErrorAuth, status: http.StatusBadRequest},
+		{name: "unauthorized", err: &apiError{status: http.StatusUnauthorized}, want: ErrorAuth, status: http.StatusUnauthorized},
+		{name: "not found", err: fmt.Errorf("get cluster: %w", &apiError{status: http.StatusNotFound}), want: ErrorAPIClient, status: http.StatusNotFound},
+		{name: "server error", err: &apiError{status: http.StatusServiceUnavailable}, want: ErrorAPIServer, status: http.StatusServiceUnavailable},
+		{name: "offline", err: &config.OfflineError{Operation: "GET /"}, want: ErrorNetwork},
+		{name: "dns", err: &net.DNSError{Err: "no such host", Name: "cloud.mongodb.com"}, want: ErrorNetwork},
+		{name: "timeout", err: context.DeadlineExceeded, want: ErrorNetwork},
+		{name: "other", err: errors.New("boom"), want: ErrorInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClassifyError(tt.err))
+			assert.Equal(t, tt.status, StatusCode(tt.err))
+		})
+	}
+}
+
+func TestUserInput(t *testing.T) {
+	assert.NoError(t, UserInput(nil))
+	err := errors.New("missing --name")
+	assert.ErrorIs(t, UserInput(err), err)
+	assert.EqualError(t, UserInput(err), "missing --name")
+}
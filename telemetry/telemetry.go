@@ -0,0 +1,84 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package telemetry builds the telemetry events of the CLIs using the config package, so they report
+// consistent and comparable metrics. Whether telemetry is enabled is decided by config.TelemetryPolicy.
+package telemetry
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/mongodb/atlas-cli-core/environment"
+)
+
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+	ResultCrash   = "crash" // ResultCrash is the result of the events of crash reports, see SubmitCrashReports
+)
+
+// now is replaced in tests.
+var now = time.Now
+
+// CommandEvent is the telemetry event of a command execution. It holds no error message, flag values or IDs,
+// CLIs add what they need to Properties.
+type CommandEvent struct {
+	Timestamp  time.Time              `json:"timestamp"`
+	Command    string                 `json:"command"` // Command is the path of the command, like "atlas clusters list"
+	DurationMS int64                  `json:"duration_ms"`
+	Result     string                 `json:"result"` // Result is ResultSuccess or ResultError
+	ErrorClass ErrorClass             `json:"error_class,omitempty"`
+	StatusCode int                    `json:"status_code,omitempty"` // StatusCode is the HTTP status of the API error, if any
+	Service    string                 `json:"service,omitempty"`
+	OS         string                 `json:"os"`
+	Arch       string                 `json:"arch"`
+	CI         environment.CIProvider `json:"ci,omitempty"`
+	SampleRate float64                `json:"sample_rate,omitempty"` // SampleRate is the share of events sent, set by Batcher
+	Properties map[string]any         `json:"properties,omitempty"`
+}
+
+// NewCommandEvent returns the event of a command started at start that ended with err, nil when it succeeded.
+func NewCommandEvent(p *config.Profile, command string, start time.Time, err error) CommandEvent {
+	end := now()
+	e := CommandEvent{
+		Timestamp:  start.UTC(),
+		Command:    command,
+		DurationMS: end.Sub(start).Milliseconds(),
+		Result:     ResultSuccess,
+		Service:    p.Service(),
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		CI:         environment.Detect().CI,
+	}
+	if err != nil {
+		e.Result = ResultError
+		e.ErrorClass = ClassifyError(err)
+		e.StatusCode = StatusCode(err)
+	}
+	return e
+}
+
+// StartCommand returns a function building the event of a command when it ends, like
+//
+//	done := telemetry.StartCommand(profile, cmd.CommandPath())
+//	err := cmd.Execute()
+//	event := done(err)
+func StartCommand(p *config.Profile, command string) func(err error) CommandEvent {
+	start := now()
+	return func(err error) CommandEvent {
+		return NewCommandEvent(p, command, start, err)
+	}
+}
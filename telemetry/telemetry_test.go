@@ -0,0 +1,76 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package telemetry
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mongodb/atlas-cli-core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeNow(t *testing.T, times ...time.Time) {
+	t.Helper()
+	original := now
+	t.Cleanup(func() { now = original })
+	now = func() time.Time {
+		next := times[0]
+		times = times[1:]
+		return next
+	}
+}
+
+func TestStartCommand(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fakeNow(t, start, start.Add(1500*time.Millisecond))
+	p := config.NewEphemeralProfile(map[string]any{"service": config.CloudService})
+
+	done := StartCommand(p, "atlas clusters list")
+	e := done(&apiError{status: http.StatusNotFound})
+
+	assert.Equal(t, start, e.Timestamp)
+	assert.Equal(t, "atlas clusters list", e.Command)
+	assert.Equal(t, int64(1500), e.DurationMS)
+	assert.Equal(t, ResultError, e.Result)
+	assert.Equal(t, ErrorAPIClient, e.ErrorClass)
+	assert.Equal(t, http.StatusNotFound, e.StatusCode)
+	assert.Equal(t, config.CloudService, e.Service)
+	assert.Equal(t, runtime.GOOS, e.OS)
+	assert.Equal(t, runtime.GOARCH, e.Arch)
+}
+
+func TestNewCommandEvent(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fakeNow(t, start.Add(time.Second), start.Add(time.Second))
+	p := config.NewEphemeralProfile(map[string]any{})
+
+	e := NewCommandEvent(p, "atlas auth whoami", start, nil)
+	assert.Equal(t, ResultSuccess, e.Result)
+	assert.Equal(t, NoError, e.ErrorClass)
+
+	e = NewCommandEvent(p, "atlas auth whoami", start, errors.New("user@example.com not found"))
+	b, err := json.Marshal(e)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "user@example.com", "error messages are not reported")
+	assert.Contains(t, string(b), `"error_class":"internal"`)
+}
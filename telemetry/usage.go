@@ -0,0 +1,153 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mongodb/atlas-cli-core/config"
+)
+
+// Summary is the usage of the CLI in a month, built from the events kept in the telemetry spool, see UsageSummary.
+type Summary struct {
+	Month     time.Time
+	Events    int
+	Errors    map[ErrorClass]int
+	Commands  []CommandUsage // Commands are sorted by runs, most used first
+	Collected []string       // Collected are the fields of the events, like "command" or "properties.flags", sorted
+}
+
+// CommandUsage is the usage of a command in a Summary.
+type CommandUsage struct {
+	Command         string
+	Runs            int
+	Errors          int
+	AverageDuration time.Duration
+}
+
+// UsageSummary returns the usage of the current month from the telemetry spool of the profile, so users can see
+// exactly what is collected. Only the events kept by the sampling are spooled, see Batcher.
+func UsageSummary(p *config.Profile) (*Summary, error) {
+	year, month, _ := now().UTC().Date()
+	s := &Summary{
+		Month:  time.Date(year, month, 1, 0, 0, 0, 0, time.UTC),
+		Errors: map[ErrorClass]int{},
+	}
+	records, err := p.ReadTelemetrySpool(s.Month)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := map[string]*CommandUsage{}
+	durations := map[string]int64{}
+	collected := map[string]bool{}
+	for _, r := range records {
+		var e CommandEvent
+		if err := json.Unmarshal(r, &e); err != nil {
+			continue
+		}
+		s.Events++
+		u, ok := usage[e.Command]
+		if !ok {
+			u = &CommandUsage{Command: e.Command}
+			usage[e.Command] = u
+		}
+		u.Runs++
+		durations[e.Command] += e.DurationMS
+		if e.Result != ResultSuccess {
+			u.Errors++
+		}
+		if e.ErrorClass != NoError {
+			s.Errors[e.ErrorClass]++
+		}
+		collectFields(r, collected)
+	}
+
+	for command, u := range usage {
+		u.AverageDuration = time.Duration(durations[command]/int64(u.Runs)) * time.Millisecond
+		s.Commands = append(s.Commands, *u)
+	}
+	sort.Slice(s.Commands, func(i, j int) bool {
+		if s.Commands[i].Runs != s.Commands[j].Runs {
+			return s.Commands[i].Runs > s.Commands[j].Runs
+		}
+		return s.Commands[i].Command < s.Commands[j].Command
+	})
+	for field := range collected {
+		s.Collected = append(s.Collected, field)
+	}
+	sort.Strings(s.Collected)
+	return s, nil
+}
+
+// collectFields adds the names of the fields of a spooled event to fields, the properties included,
+// as they're stored rather than as CommandEvent knows them.
+func collectFields(record json.RawMessage, fields map[string]bool) {
+	var event map[string]json.RawMessage
+	if err := json.Unmarshal(record, &event); err != nil {
+		return
+	}
+	for name, value := range event {
+		var properties map[string]json.RawMessage
+		if name == "properties" && json.Unmarshal(value, &properties) == nil {
+			for property := range properties {
+				fields["properties."+property] = true
+			}
+			continue
+		}
+		fields[name] = true
+	}
+}
+
+// String returns the summary as a report users can read, it shows the collected fields but not their values.
+func (s *Summary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Your CLI usage in %s\n\n", s.Month.Format("January 2006"))
+	if s.Events == 0 {
+		b.WriteString("No telemetry was collected.\n")
+		return b.String()
+	}
+	failed := 0
+	for _, c := range s.Commands {
+		failed += c.Errors
+	}
+	fmt.Fprintf(&b, "%d commands ran, %d failed.\n\n", s.Events, failed)
+
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "COMMAND\tRUNS\tERRORS\tAVG DURATION")
+	for _, c := range s.Commands {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", c.Command, c.Runs, c.Errors, c.AverageDuration)
+	}
+	_ = w.Flush()
+
+	if len(s.Errors) > 0 {
+		classes := make([]string, 0, len(s.Errors))
+		for class := range s.Errors {
+			classes = append(classes, string(class))
+		}
+		sort.Strings(classes)
+		b.WriteString("\nErrors by class:\n")
+		for _, class := range classes {
+			fmt.Fprintf(&b, "  %s: %d\n", class, s.Errors[ErrorClass(class)])
+		}
+	}
+	fmt.Fprintf(&b, "\nCollected fields: %s\n", strings.Join(s.Collected, ", "))
+	return b.String()
+}
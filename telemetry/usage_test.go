@@ -0,0 +1,65 @@
+// Copyright 2024 MongoDB Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+
+package telemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageSummary(t *testing.T) {
+	march := time.Date(2024, time.March, 10, 9, 0, 0, 0, time.UTC)
+	b := newTestBatcher(t, nil, &recordingSender{})
+	fakeNow(t, march.AddDate(0, 0, 5), march.AddDate(0, 0, 5))
+
+	s, err := UsageSummary(b.profile)
+	require.NoError(t, err)
+	assert.Zero(t, s.Events)
+	assert.Contains(t, s.String(), "No telemetry was collected.")
+
+	b.Add(CommandEvent{Timestamp: march.AddDate(0, -1, 0), Command: "atlas clusters list", Result: ResultSuccess})
+	b.Add(CommandEvent{Timestamp: march, Command: "atlas clusters list", DurationMS: 1000, Result: ResultSuccess})
+	b.Add(CommandEvent{Timestamp: march, Command: "atlas clusters list", DurationMS: 3000, Result: ResultError, ErrorClass: ErrorNetwork})
+	b.Add(CommandEvent{Timestamp: march, Command: "atlas auth login", DurationMS: 500, Result: ResultError, ErrorClass: ErrorAuth,
+		Properties: map[string]any{"flags": "secret-value"}})
+	require.NoError(t, b.Flush(context.Background()))
+
+	s, err = UsageSummary(b.profile)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), s.Month)
+	assert.Equal(t, 3, s.Events, "only the events of the month are summarized")
+	assert.Equal(t, map[ErrorClass]int{ErrorNetwork: 1, ErrorAuth: 1}, s.Errors)
+	assert.Equal(t, []CommandUsage{
+		{Command: "atlas clusters list", Runs: 2, Errors: 1, AverageDuration: 2 * time.Second},
+		{Command: "atlas auth login", Runs: 1, Errors: 1, AverageDuration: 500 * time.Millisecond},
+	}, s.Commands)
+	assert.Contains(t, s.Collected, "command")
+	assert.Contains(t, s.Collected, "properties.flags")
+	assert.Contains(t, s.Collected, "sample_rate")
+
+	report := s.String()
+	assert.Contains(t, report, "Your CLI usage in March 2024")
+	assert.Contains(t, report, "3 commands ran, 2 failed.")
+	assert.Regexp(t, `atlas clusters list\s+2\s+1\s+2s`, report)
+	assert.Contains(t, report, "network: 1")
+	assert.Contains(t, report, "properties.flags")
+	assert.NotContains(t, report, "secret-value", "values aren't shown")
+}